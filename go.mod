@@ -4,9 +4,13 @@ go 1.15
 
 require (
 	github.com/dustin/go-humanize v1.0.0
+	github.com/evanphx/json-patch v4.9.0+incompatible
 	github.com/gin-gonic/gin v1.6.3
+	github.com/google/cel-go v0.7.3
+	github.com/klauspost/compress v1.15.0
 	github.com/muesli/termenv v0.8.1
 	github.com/pkg/errors v0.9.1
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/slack-go/slack v0.9.1
 	github.com/spf13/cobra v1.1.3
 	github.com/spf13/pflag v1.0.5
@@ -14,15 +18,22 @@ require (
 	github.com/spongeprojects/client-go v0.0.2
 	github.com/spongeprojects/magicconch v0.0.6
 	github.com/stretchr/testify v1.7.0
+	go.etcd.io/bbolt v1.3.6
 	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 	gorm.io/driver/mysql v1.0.5
 	gorm.io/driver/postgres v1.0.8
 	gorm.io/driver/sqlite v1.1.4
 	gorm.io/gorm v1.21.3
+	k8s.io/api v0.21.1
 	k8s.io/apimachinery v0.21.1
 	k8s.io/cli-runtime v0.21.0
 	k8s.io/client-go v0.21.1
 	k8s.io/klog/v2 v2.8.0
+	sigs.k8s.io/yaml v1.2.0
 )
 
 replace k8s.io/klog/v2 => github.com/spongeprojects/klog/v2 v2.9.1-0.20210527182053-d23af0d5cbe7
+
+// API types of Channel, Watcher and ClusterWatcher evolve with kbb, they are staged in ./staging/client-go
+// until the next release of github.com/spongeprojects/client-go, see development.md.
+replace github.com/spongeprojects/client-go => ./staging/client-go
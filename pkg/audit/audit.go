@@ -0,0 +1,190 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spongeprojects/kubebigbrother/pkg/event"
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	"github.com/spongeprojects/kubebigbrother/pkg/stores/event_store"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/klog/v2"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// StageResponseComplete is the stage when the response has been sent
+	StageResponseComplete = "ResponseComplete"
+
+	cacheSize = 10000
+
+	// cacheTTL is how long a record is kept for events not seen yet by informers
+	cacheTTL = 5 * time.Minute
+
+	// maxPayloadBytes limits the size of a payload, batches are small by default
+	maxPayloadBytes = 32 << 20
+)
+
+// eventTypesOfVerb are types of events a request may cause, other verbs are ignored
+var eventTypesOfVerb = map[string][]string{
+	"create": {event.TypeAdded, event.TypeTriggered},
+	"update": {event.TypeUpdated, event.TypeTriggered, event.TypeResolved},
+	"patch":  {event.TypeUpdated, event.TypeTriggered, event.TypeResolved},
+	"delete": {event.TypeDeleted},
+}
+
+// Record is an audit event of a successful change
+type Record struct {
+	Match event_store.AuditMatch
+	Actor event.Actor
+}
+
+// Attribution returns who made the change in the form stored
+func (r *Record) Attribution() models.Attribution {
+	return models.Attribution{
+		Username:   r.Actor.Username,
+		UserGroups: strings.Join(r.Actor.Groups, ","),
+		UserAgent:  r.Actor.UserAgent,
+		SourceIPs:  strings.Join(r.Actor.SourceIPs, ","),
+	}
+}
+
+// NewRecord creates Record from audit event, returns false if it's not a successful change
+func NewRecord(cluster string, e *Event) (*Record, bool) {
+	if e.Stage != StageResponseComplete || e.ObjectRef == nil || e.ObjectRef.Name == "" {
+		return nil, false
+	}
+	eventTypes, ok := eventTypesOfVerb[e.Verb]
+	if !ok {
+		return nil, false
+	}
+	if e.ResponseStatus != nil && (e.ResponseStatus.Code < 200 || e.ResponseStatus.Code >= 300) {
+		return nil, false
+	}
+
+	return &Record{
+		Match: event_store.AuditMatch{
+			Cluster:         cluster,
+			Group:           e.ObjectRef.APIGroup,
+			Resource:        e.ObjectRef.Resource,
+			Namespace:       e.ObjectRef.Namespace,
+			Name:            e.ObjectRef.Name,
+			EventTypes:      eventTypes,
+			ResourceVersion: responseResourceVersion(e.ResponseObject),
+			Time:            e.StageTimestamp.Time,
+		},
+		Actor: event.Actor{
+			Username:  e.User.Username,
+			Groups:    e.User.Groups,
+			UserAgent: e.UserAgent,
+			SourceIPs: e.SourceIPs,
+		},
+	}, true
+}
+
+// responseResourceVersion returns resourceVersion of the response object, empty when unknown
+func responseResourceVersion(responseObject json.RawMessage) string {
+	if len(responseObject) == 0 {
+		return ""
+	}
+	var obj struct {
+		Kind     string `json:"kind"`
+		Metadata struct {
+			ResourceVersion string `json:"resourceVersion"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(responseObject, &obj); err != nil || obj.Kind == "Status" {
+		return ""
+	}
+	return obj.Metadata.ResourceVersion
+}
+
+// Cache keeps recent records with known resourceVersion,
+// so that events can be attributed before they are notified.
+type Cache struct {
+	lru *utilcache.LRUExpireCache
+}
+
+// NewCache creates Cache
+func NewCache() *Cache {
+	return &Cache{
+		lru: utilcache.NewLRUExpireCache(cacheSize),
+	}
+}
+
+// Add adds record to the cache, records without resourceVersion are skipped
+func (c *Cache) Add(r *Record) {
+	if r.Match.ResourceVersion == "" {
+		return
+	}
+	m := r.Match
+	c.lru.Add(cacheKey(m.Cluster, m.Group, m.Resource, m.Namespace, m.Name, m.ResourceVersion),
+		r.Actor, cacheTTL)
+}
+
+// Lookup finds who made the change resulting in the resourceVersion of the object
+func (c *Cache) Lookup(cluster string, gvr schema.GroupVersionResource,
+	namespace, name, resourceVersion string) (*event.Actor, bool) {
+	cached, ok := c.lru.Get(
+		cacheKey(cluster, gvr.Group, gvr.Resource, namespace, name, resourceVersion))
+	if !ok {
+		return nil, false
+	}
+	actor := cached.(event.Actor)
+	return &actor, true
+}
+
+func cacheKey(cluster, group, resource, namespace, name, resourceVersion string) string {
+	return fmt.Sprintf("%s/%s/%s/%s/%s@%s", cluster, group, resource, namespace, name, resourceVersion)
+}
+
+// Receiver receives audit events posted by the audit webhook backend of the API server,
+// the cluster is identified by the credential of the request.
+type Receiver struct {
+	Store event_store.Interface
+	Cache *Cache
+	Auth  *Authenticator
+}
+
+// ServeHTTP implements http.Handler
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	cluster, ok := r.Auth.Authenticate(req)
+	if !ok {
+		klog.Warningf("[audit] unauthorized request from %s", req.RemoteAddr)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if req.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var list EventList
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxPayloadBytes)).Decode(&list); err != nil {
+		klog.Warningf("[audit] decode payload error: %s", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	for i := range list.Items {
+		record, ok := NewRecord(cluster, &list.Items[i])
+		if !ok {
+			continue
+		}
+		r.Cache.Add(record)
+		attributed, err := r.Store.Attribute(record.Match, record.Attribution())
+		if err != nil {
+			klog.Warningf("[audit] attribute events error: %s", err)
+			// the API server retries, events attributed already are skipped
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		klog.V(5).Infof("[audit] %d events attributed to %s: %s %s/%s",
+			attributed, record.Actor.Username, list.Items[i].Verb,
+			record.Match.Namespace, record.Match.Name)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
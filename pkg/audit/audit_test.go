@@ -0,0 +1,140 @@
+package audit
+
+import (
+	"bytes"
+	"github.com/spongeprojects/kubebigbrother/pkg/gormdb"
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	"github.com/spongeprojects/kubebigbrother/pkg/stores/event_store"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const payload = `{
+  "kind": "EventList",
+  "apiVersion": "audit.k8s.io/v1",
+  "items": [{
+    "auditID": "1", "stage": "ResponseComplete", "verb": "patch",
+    "user": {"username": "alice", "groups": ["dev", "system:authenticated"]},
+    "sourceIPs": ["10.0.0.1"], "userAgent": "kubectl/v1.21.1",
+    "objectRef": {"resource": "deployments", "namespace": "demo", "name": "canary", "apiGroup": "apps", "apiVersion": "v1"},
+    "responseStatus": {"code": 200},
+    "responseObject": {"kind": "Deployment", "metadata": {"resourceVersion": "2"}},
+    "stageTimestamp": "2021-06-01T00:00:00.000000Z"
+  }, {
+    "auditID": "2", "stage": "ResponseComplete", "verb": "delete",
+    "user": {"username": "bob"},
+    "objectRef": {"resource": "configmaps", "namespace": "demo", "name": "canary", "apiVersion": "v1"},
+    "responseStatus": {"code": 200},
+    "stageTimestamp": "%s"
+  }, {
+    "auditID": "3", "stage": "ResponseComplete", "verb": "get",
+    "user": {"username": "carol"},
+    "objectRef": {"resource": "configmaps", "namespace": "demo", "name": "canary", "apiVersion": "v1"},
+    "responseStatus": {"code": 200},
+    "stageTimestamp": "2021-06-01T00:00:00.000000Z"
+  }]
+}`
+
+func TestNewRecord(t *testing.T) {
+	assertions := require.New(t)
+
+	_, ok := NewRecord("", &Event{Stage: "RequestReceived", Verb: "create",
+		ObjectRef: &ObjectReference{Resource: "pods", Name: "nginx"}})
+	assertions.False(ok)
+
+	_, ok = NewRecord("", &Event{Stage: StageResponseComplete, Verb: "list",
+		ObjectRef: &ObjectReference{Resource: "pods"}})
+	assertions.False(ok)
+
+	record, ok := NewRecord("dev", &Event{Stage: StageResponseComplete, Verb: "create",
+		User:           UserInfo{Username: "alice", Groups: []string{"dev", "ops"}},
+		ObjectRef:      &ObjectReference{Resource: "pods", Namespace: "demo", Name: "nginx"},
+		ResponseObject: []byte(`{"kind": "Pod", "metadata": {"resourceVersion": "5"}}`),
+	})
+	assertions.True(ok)
+	assertions.Equal("dev", record.Match.Cluster)
+	assertions.Equal("5", record.Match.ResourceVersion)
+	assertions.Equal([]string{"ADDED", "TRIGGERED"}, record.Match.EventTypes)
+	assertions.Equal("dev,ops", record.Attribution().UserGroups)
+
+	record, ok = NewRecord("", &Event{Stage: StageResponseComplete, Verb: "delete",
+		ObjectRef:      &ObjectReference{Resource: "pods", Namespace: "demo", Name: "nginx"},
+		ResponseObject: []byte(`{"kind": "Status", "status": "Success"}`),
+	})
+	assertions.True(ok)
+	assertions.Equal("", record.Match.ResourceVersion)
+}
+
+func TestReceiver(t *testing.T) {
+	assertions := require.New(t)
+
+	db, err := gormdb.New("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	assertions.Nil(err)
+	store := event_store.New(db)
+
+	updated := &models.Event{EventType: "UPDATED", Group: "apps", Version: "v1", Resource: "deployments",
+		Namespace: "demo", Name: "canary", ResourceVersion: "2",
+		Attribution: models.Attribution{Manager: "kubectl-patch"}}
+	deleted := &models.Event{EventType: "DELETED", Version: "v1", Resource: "configmaps",
+		Namespace: "demo", Name: "canary", ResourceVersion: "7"}
+	for _, e := range []*models.Event{updated, deleted} {
+		assertions.Nil(store.Save(e))
+	}
+
+	cache := NewCache()
+	receiver := &Receiver{Store: store, Cache: cache, Auth: &Authenticator{
+		Tokens:   map[string]string{"secret": "", "other-secret": "other"},
+		Clusters: map[string]bool{"": true, "other": true},
+	}}
+	post := func(token string, body []byte) int {
+		req := httptest.NewRequest(http.MethodPost, "/audit", bytes.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		receiver.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	body := bytes.Replace([]byte(payload), []byte("%s"),
+		[]byte(time.Now().UTC().Format(metav1.RFC3339Micro)), 1)
+	// unauthenticated requests never reach the store
+	assertions.Equal(http.StatusUnauthorized, post("", body))
+	assertions.Equal(http.StatusUnauthorized, post("invalid", body))
+	// events of another cluster are not attributed by its audit events
+	assertions.Equal(http.StatusOK, post("other-secret", body))
+	e, err := store.Find(updated.ID)
+	assertions.Nil(err)
+	assertions.Empty(e.Username)
+
+	assertions.Equal(http.StatusOK, post("secret", body))
+
+	e, err = store.Find(updated.ID)
+	assertions.Nil(err)
+	assertions.Equal("alice", e.Username)
+	assertions.Equal("dev,system:authenticated", e.UserGroups)
+	assertions.Equal("kubectl/v1.21.1", e.UserAgent)
+	assertions.Equal("10.0.0.1", e.SourceIPs)
+	assertions.Equal("kubectl-patch", e.Manager)
+
+	e, err = store.Find(deleted.ID)
+	assertions.Nil(err)
+	assertions.Equal("bob", e.Username)
+
+	actor, ok := cache.Lookup("", schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		"demo", "canary", "2")
+	assertions.True(ok)
+	assertions.Equal("alice", actor.Username)
+
+	req := httptest.NewRequest(http.MethodGet, "/audit", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	receiver.ServeHTTP(w, req)
+	assertions.Equal(http.StatusMethodNotAllowed, w.Code)
+}
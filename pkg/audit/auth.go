@@ -0,0 +1,98 @@
+package audit
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Authenticator identifies the cluster posting audit events by its credential,
+// a client certificate verified by the TLS server, whose CommonName is the cluster name,
+// or a bearer token, mapped to the cluster by the tokens file.
+type Authenticator struct {
+	// Tokens maps bearer tokens to clusters, tokens are disabled when empty
+	Tokens map[string]string
+
+	// Clusters are names of clusters watched, credentials of other clusters are rejected,
+	// when the only cluster watched is unnamed, CommonNames of client certificates are ignored.
+	Clusters map[string]bool
+}
+
+// Authenticate returns the cluster of the credential of the request
+func (a *Authenticator) Authenticate(req *http.Request) (cluster string, ok bool) {
+	if req.TLS != nil && len(req.TLS.VerifiedChains) > 0 && len(req.TLS.PeerCertificates) > 0 {
+		cluster = req.TLS.PeerCertificates[0].Subject.CommonName
+		if len(a.Clusters) == 1 && a.Clusters[""] {
+			cluster = ""
+		}
+		return cluster, a.Clusters[cluster]
+	}
+
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if token == "" || token == req.Header.Get("Authorization") {
+		return "", false
+	}
+	// every token is compared, so that the time taken reveals nothing
+	found := false
+	for t, c := range a.Tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			cluster, found = c, true
+		}
+	}
+	return cluster, found && a.Clusters[cluster]
+}
+
+// LoadTokens loads bearer tokens from a file of lines of "token,cluster",
+// the cluster is empty for the only unnamed cluster, empty lines and lines starting with # are skipped.
+func LoadTokens(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "open tokens file error")
+	}
+	defer f.Close()
+
+	tokens := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, ",", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, errors.Errorf("invalid line %d of tokens file, \"token,cluster\" expected", n)
+		}
+		tokens[parts[0]] = strings.TrimSpace(parts[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "read tokens file error")
+	}
+	return tokens, nil
+}
+
+// TLSConfig builds the TLS config of the audit server,
+// client certificates are verified by CAs in clientCAFile when set.
+func TLSConfig(clientCAFile string) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if clientCAFile == "" {
+		return config, nil
+	}
+	pem, err := ioutil.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "read client CA file error")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.Errorf("no certificate found in client CA file: %s", clientCAFile)
+	}
+	config.ClientCAs = pool
+	// bearer tokens are accepted without client certificates
+	config.ClientAuth = tls.VerifyClientCertIfGiven
+	return config, nil
+}
@@ -0,0 +1,85 @@
+package audit
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuthenticator(t *testing.T) {
+	assertions := require.New(t)
+
+	auth := &Authenticator{
+		Tokens:   map[string]string{"prod-token": "prod", "gone-token": "gone"},
+		Clusters: map[string]bool{"prod": true, "dev": true},
+	}
+	request := func(token, commonName string, verified bool) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/audit", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		if commonName != "" {
+			cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+			req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+			if verified {
+				req.TLS.VerifiedChains = [][]*x509.Certificate{{cert}}
+			}
+		}
+		return req
+	}
+	authenticate := func(req *http.Request) string {
+		cluster, ok := auth.Authenticate(req)
+		if !ok {
+			return "unauthorized"
+		}
+		return cluster
+	}
+
+	assertions.Equal("prod", authenticate(request("prod-token", "", false)))
+	assertions.Equal("dev", authenticate(request("", "dev", true)))
+	assertions.Equal("unauthorized", authenticate(request("", "", false)))
+	assertions.Equal("unauthorized", authenticate(request("invalid", "", false)))
+	// clusters not watched
+	assertions.Equal("unauthorized", authenticate(request("gone-token", "", false)))
+	assertions.Equal("unauthorized", authenticate(request("", "gone", true)))
+	// certificates not verified are ignored
+	assertions.Equal("unauthorized", authenticate(request("", "dev", false)))
+	assertions.Equal("prod", authenticate(request("prod-token", "dev", false)))
+
+	req := request("", "", false)
+	req.Header.Set("Authorization", "Basic prod-token")
+	assertions.Equal("unauthorized", authenticate(req))
+
+	// CommonNames are ignored when the only cluster watched is unnamed
+	auth.Clusters = map[string]bool{"": true}
+	assertions.Equal("", authenticate(request("", "anything", true)))
+}
+
+func TestLoadTokens(t *testing.T) {
+	assertions := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "tokens.csv")
+	assertions.Nil(ioutil.WriteFile(path, []byte("# token,cluster\n\nprod-token,prod\n only-token, \n"), 0600))
+	tokens, err := LoadTokens(path)
+	assertions.Nil(err)
+	assertions.Equal(map[string]string{"prod-token": "prod", "only-token": ""}, tokens)
+
+	assertions.Nil(ioutil.WriteFile(path, []byte("prod-token\n"), 0600))
+	_, err = LoadTokens(path)
+	assertions.NotNil(err)
+
+	_, err = LoadTokens(filepath.Join(t.TempDir(), "missing"))
+	assertions.NotNil(err)
+
+	_, err = TLSConfig(path)
+	assertions.NotNil(err)
+	config, err := TLSConfig("")
+	assertions.Nil(err)
+	assertions.Equal(tls.NoClientCert, config.ClientAuth)
+}
@@ -0,0 +1,54 @@
+package audit
+
+import (
+	"encoding/json"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// EventList is the payload posted by the audit webhook backend, only fields used are defined,
+// see k8s.io/apiserver/pkg/apis/audit/v1 for the full definition.
+type EventList struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Items []Event `json:"items"`
+}
+
+// Event is an audit event
+type Event struct {
+	AuditID types.UID `json:"auditID"`
+
+	// Stage is the stage of the request, only ResponseComplete events are used
+	Stage string `json:"stage"`
+
+	Verb      string   `json:"verb"`
+	User      UserInfo `json:"user"`
+	SourceIPs []string `json:"sourceIPs,omitempty"`
+	UserAgent string   `json:"userAgent,omitempty"`
+
+	ObjectRef      *ObjectReference `json:"objectRef,omitempty"`
+	ResponseStatus *metav1.Status   `json:"responseStatus,omitempty"`
+
+	// ResponseObject is only set at RequestResponse level
+	ResponseObject json.RawMessage `json:"responseObject,omitempty"`
+
+	StageTimestamp metav1.MicroTime `json:"stageTimestamp"`
+}
+
+// UserInfo is the authenticated user
+type UserInfo struct {
+	Username string   `json:"username,omitempty"`
+	Groups   []string `json:"groups,omitempty"`
+}
+
+// ObjectReference is the object the request is about
+type ObjectReference struct {
+	Resource        string    `json:"resource,omitempty"`
+	Namespace       string    `json:"namespace,omitempty"`
+	Name            string    `json:"name,omitempty"`
+	UID             types.UID `json:"uid,omitempty"`
+	APIGroup        string    `json:"apiGroup,omitempty"`
+	APIVersion      string    `json:"apiVersion,omitempty"`
+	ResourceVersion string    `json:"resourceVersion,omitempty"`
+	Subresource     string    `json:"subresource,omitempty"`
+}
@@ -9,7 +9,6 @@ import (
 	"k8s.io/klog/v2"
 	"net/http"
 	"net/url"
-	"text/template"
 )
 
 // ChannelCallback is the callback channel
@@ -18,9 +17,7 @@ type ChannelCallback struct {
 	Method      string
 	URL         string
 	UseTemplate bool
-	TmplAdded   *template.Template
-	TmplDeleted *template.Template
-	TmplUpdated *template.Template
+	Tmpls       eventTemplates
 }
 
 // NewEventProcessContext implements Channel
@@ -35,19 +32,8 @@ func (c *ChannelCallback) NewEventProcessContext(e *event.Event) *EventProcessCo
 func (c *ChannelCallback) Handle(ctx *EventProcessContext) error {
 	body := &bytes.Buffer{}
 	if c.UseTemplate {
-		var t *template.Template
-		switch ctx.Event.Type {
-		case event.TypeAdded:
-			t = c.TmplAdded
-		case event.TypeDeleted:
-			t = c.TmplDeleted
-		case event.TypeUpdated:
-			t = c.TmplUpdated
-		default:
-			return errors.Errorf("unknown event type: %s", ctx.Event.Type)
-		}
-		if err := t.Execute(body, ctx.Event); err != nil {
-			return errors.Wrap(err, "execute template error")
+		if err := c.Tmpls.execute(body, ctx.Event); err != nil {
+			return err
 		}
 	} else {
 		if err := json.NewEncoder(body).Encode(ctx.Event); err != nil {
@@ -100,8 +86,15 @@ func NewChannelCallback(config *spg.ChannelCallbackConfig) (*ChannelCallback, er
 		config.Method = "POST"
 	}
 
-	tmplAdded, tmplDeleted, tmplUpdated, err := parseTemplates(
-		config.AddedTemplate, config.DeletedTemplate, config.UpdatedTemplate)
+	tmpls, err := parseTemplates(map[event.Type]string{
+		event.TypeAdded:     config.AddedTemplate,
+		event.TypeDeleted:   config.DeletedTemplate,
+		event.TypeUpdated:   config.UpdatedTemplate,
+		event.TypeTriggered: config.TriggeredTemplate,
+		event.TypeResolved:  config.ResolvedTemplate,
+		event.TypeStuck:     config.StuckTemplate,
+		event.TypeOccurred:  config.OccurredTemplate,
+	})
 	if err != nil {
 		return nil, errors.Wrap(err, "parse template error")
 	}
@@ -111,8 +104,6 @@ func NewChannelCallback(config *spg.ChannelCallbackConfig) (*ChannelCallback, er
 		Method:      config.Method,
 		URL:         config.URL,
 		UseTemplate: config.UseTemplate,
-		TmplAdded:   tmplAdded,
-		TmplDeleted: tmplDeleted,
-		TmplUpdated: tmplUpdated,
+		Tmpls:       tmpls,
 	}, nil
 }
@@ -9,18 +9,15 @@ import (
 	"k8s.io/klog/v2"
 	"net/http"
 	"net/url"
-	"text/template"
 )
 
 // ChannelDingtalk is the callback channel
 type ChannelDingtalk struct {
-	Client      *http.Client
-	WebhookURL  string
-	AtMobiles   []string
-	AtAll       bool
-	TmplAdded   *template.Template
-	TmplDeleted *template.Template
-	TmplUpdated *template.Template
+	Client     *http.Client
+	WebhookURL string
+	AtMobiles  []string
+	AtAll      bool
+	Tmpls      eventTemplates
 }
 
 // DingtalkMessageAt represents a Dingtalk message at (@) info
@@ -54,20 +51,8 @@ func (c *ChannelDingtalk) NewEventProcessContext(e *event.Event) *EventProcessCo
 // Handle implements Channel
 func (c *ChannelDingtalk) Handle(ctx *EventProcessContext) error {
 	buf := &bytes.Buffer{}
-	var t *template.Template
-	switch ctx.Event.Type {
-	case event.TypeAdded:
-		t = c.TmplAdded
-	case event.TypeDeleted:
-		t = c.TmplDeleted
-	case event.TypeUpdated:
-		t = c.TmplUpdated
-	default:
-		return errors.Errorf("unknown event type: %s", ctx.Event.Type)
-	}
-
-	if err := t.Execute(buf, ctx.Event); err != nil {
-		return errors.Wrap(err, "execute template error")
+	if err := c.Tmpls.execute(buf, ctx.Event); err != nil {
+		return err
 	}
 
 	message := DingtalkMessage{
@@ -128,19 +113,24 @@ func NewChannelDingtalk(config *spg.ChannelDingtalkConfig) (*ChannelDingtalk, er
 		httpClient = http.DefaultClient
 	}
 
-	tmplAdded, tmplDeleted, tmplUpdated, err := parseTemplates(
-		config.AddedTemplate, config.DeletedTemplate, config.UpdatedTemplate)
+	tmpls, err := parseTemplates(map[event.Type]string{
+		event.TypeAdded:     config.AddedTemplate,
+		event.TypeDeleted:   config.DeletedTemplate,
+		event.TypeUpdated:   config.UpdatedTemplate,
+		event.TypeTriggered: config.TriggeredTemplate,
+		event.TypeResolved:  config.ResolvedTemplate,
+		event.TypeStuck:     config.StuckTemplate,
+		event.TypeOccurred:  config.OccurredTemplate,
+	})
 	if err != nil {
 		return nil, errors.Wrap(err, "parse template error")
 	}
 
 	return &ChannelDingtalk{
-		Client:      httpClient,
-		WebhookURL:  config.WebhookURL,
-		AtMobiles:   config.AtMobiles,
-		AtAll:       config.AtAll,
-		TmplAdded:   tmplAdded,
-		TmplDeleted: tmplDeleted,
-		TmplUpdated: tmplUpdated,
+		Client:     httpClient,
+		WebhookURL: config.WebhookURL,
+		AtMobiles:  config.AtMobiles,
+		AtAll:      config.AtAll,
+		Tmpls:      tmpls,
 	}, nil
 }
@@ -14,12 +14,10 @@ import (
 
 // ChannelFlock is the callback channel
 type ChannelFlock struct {
-	Client      *http.Client
-	URL         string
-	TmplTitle   *template.Template
-	TmplAdded   *template.Template
-	TmplDeleted *template.Template
-	TmplUpdated *template.Template
+	Client    *http.Client
+	URL       string
+	TmplTitle *template.Template
+	Tmpls     eventTemplates
 }
 
 // FlockMessage represents a flock message
@@ -54,20 +52,8 @@ func (c *ChannelFlock) Handle(ctx *EventProcessContext) error {
 	title := titleBuf.String()
 
 	buf := &bytes.Buffer{}
-	var t *template.Template
-	switch ctx.Event.Type {
-	case event.TypeAdded:
-		t = c.TmplAdded
-	case event.TypeDeleted:
-		t = c.TmplDeleted
-	case event.TypeUpdated:
-		t = c.TmplUpdated
-	default:
-		return errors.Errorf("unknown event type: %s", ctx.Event.Type)
-	}
-
-	if err := t.Execute(buf, ctx.Event); err != nil {
-		return errors.Wrap(err, "execute template error")
+	if err := c.Tmpls.execute(buf, ctx.Event); err != nil {
+		return err
 	}
 
 	message := FlockMessage{
@@ -137,18 +123,23 @@ func NewChannelFlock(config *spg.ChannelFlockConfig) (*ChannelFlock, error) {
 		return nil, errors.Wrap(err, "parse title template error")
 	}
 
-	tmplAdded, tmplDeleted, tmplUpdated, err := parseTemplates(
-		config.AddedTemplate, config.DeletedTemplate, config.UpdatedTemplate)
+	tmpls, err := parseTemplates(map[event.Type]string{
+		event.TypeAdded:     config.AddedTemplate,
+		event.TypeDeleted:   config.DeletedTemplate,
+		event.TypeUpdated:   config.UpdatedTemplate,
+		event.TypeTriggered: config.TriggeredTemplate,
+		event.TypeResolved:  config.ResolvedTemplate,
+		event.TypeStuck:     config.StuckTemplate,
+		event.TypeOccurred:  config.OccurredTemplate,
+	})
 	if err != nil {
 		return nil, errors.Wrap(err, "parse template error")
 	}
 
 	return &ChannelFlock{
-		Client:      httpClient,
-		URL:         config.URL,
-		TmplTitle:   tmplTitle,
-		TmplAdded:   tmplAdded,
-		TmplDeleted: tmplDeleted,
-		TmplUpdated: tmplUpdated,
+		Client:    httpClient,
+		URL:       config.URL,
+		TmplTitle: tmplTitle,
+		Tmpls:     tmpls,
 	}, nil
 }
@@ -7,20 +7,27 @@ import (
 	spg "github.com/spongeprojects/client-go/api/spongeprojects.com/v1alpha1"
 	"github.com/spongeprojects/kubebigbrother/pkg/event"
 	"github.com/spongeprojects/kubebigbrother/pkg/helpers/style"
+	"github.com/spongeprojects/kubebigbrother/pkg/utils/diff"
 	"io"
 	"k8s.io/klog/v2"
 	"os"
-	"text/template"
+	"sigs.k8s.io/yaml"
+)
+
+// Print formats
+const (
+	PrintFormatJSON     = "json"
+	PrintFormatYAML     = "yaml"
+	PrintFormatTemplate = "template"
 )
 
 // ChannelPrint is the channel to print event to writer
 type ChannelPrint struct {
-	Writer      io.Writer
-	IsStdout    bool
-	UseTemplate bool
-	TmplAdded   *template.Template
-	TmplDeleted *template.Template
-	TmplUpdated *template.Template
+	Writer   io.Writer
+	IsStdout bool
+	Format   string
+	Diff     bool
+	Tmpls    eventTemplates
 }
 
 // NewEventProcessContext implements Channel
@@ -34,27 +41,32 @@ func (c *ChannelPrint) NewEventProcessContext(e *event.Event) *EventProcessConte
 // Handle implements Channel
 func (c *ChannelPrint) Handle(ctx *EventProcessContext) error {
 	buf := &bytes.Buffer{}
-	if c.UseTemplate {
-		var t *template.Template
-		switch ctx.Event.Type {
-		case event.TypeAdded:
-			t = c.TmplAdded
-		case event.TypeDeleted:
-			t = c.TmplDeleted
-		case event.TypeUpdated:
-			t = c.TmplUpdated
-		default:
-			return errors.Errorf("unknown event type: %s", ctx.Event.Type)
+	switch c.Format {
+	case PrintFormatTemplate:
+		if err := c.Tmpls.execute(buf, ctx.Event); err != nil {
+			return err
 		}
-		if err := t.Execute(buf, ctx.Event); err != nil {
-			return errors.Wrap(err, "execute template error")
+	case PrintFormatYAML:
+		b, err := yaml.Marshal(ctx.Event)
+		if err != nil {
+			return errors.Wrap(err, "yaml encode error")
 		}
-	} else {
+		buf.WriteString("---\n")
+		buf.Write(b)
+	default:
 		if err := json.NewEncoder(buf).Encode(ctx.Event); err != nil {
 			return errors.Wrap(err, "json encode error")
 		}
 	}
 
+	if c.Diff && ctx.Event.Type == event.TypeUpdated {
+		d, err := diff.Objects(ctx.Event.OldObj, ctx.Event.Obj)
+		if err != nil {
+			return errors.Wrap(err, "diff error")
+		}
+		buf.WriteString(d)
+	}
+
 	if c.IsStdout {
 		printFunc := func() error {
 			styled := style.Fg(ctx.Event.Color(), buf.String()).String()
@@ -90,18 +102,36 @@ func NewChannelPrint(config *spg.ChannelPrintConfig) (*ChannelPrint, error) {
 		return nil, errors.Errorf("unsupported writer: %s", config.Writer)
 	}
 
-	tmplAdded, tmplDeleted, tmplUpdated, err := parseTemplates(
-		config.AddedTemplate, config.DeletedTemplate, config.UpdatedTemplate)
+	tmpls, err := parseTemplates(map[event.Type]string{
+		event.TypeAdded:     config.AddedTemplate,
+		event.TypeDeleted:   config.DeletedTemplate,
+		event.TypeUpdated:   config.UpdatedTemplate,
+		event.TypeTriggered: config.TriggeredTemplate,
+		event.TypeResolved:  config.ResolvedTemplate,
+		event.TypeStuck:     config.StuckTemplate,
+		event.TypeOccurred:  config.OccurredTemplate,
+	})
 	if err != nil {
 		return nil, errors.Wrap(err, "parse template error")
 	}
 
+	format := config.Format
+	switch format {
+	case "":
+		format = PrintFormatJSON
+		if config.UseTemplate {
+			format = PrintFormatTemplate
+		}
+	case PrintFormatJSON, PrintFormatYAML, PrintFormatTemplate:
+	default:
+		return nil, errors.Errorf("unsupported format: %s", format)
+	}
+
 	return &ChannelPrint{
-		Writer:      writer,
-		IsStdout:    config.Writer == PrintWriterStdout,
-		UseTemplate: config.UseTemplate,
-		TmplAdded:   tmplAdded,
-		TmplDeleted: tmplDeleted,
-		TmplUpdated: tmplUpdated,
+		Writer:   writer,
+		IsStdout: config.Writer == PrintWriterStdout,
+		Format:   format,
+		Diff:     config.Diff,
+		Tmpls:    tmpls,
 	}, nil
 }
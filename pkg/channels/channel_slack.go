@@ -14,12 +14,10 @@ import (
 
 // ChannelSlack is the callback channel
 type ChannelSlack struct {
-	Client      *slack.Client // TODO: add Slack app support (not only webhooks)
-	WebhookURL  string
-	TmplTitle   *template.Template
-	TmplAdded   *template.Template
-	TmplDeleted *template.Template
-	TmplUpdated *template.Template
+	Client     *slack.Client // TODO: add Slack app support (not only webhooks)
+	WebhookURL string
+	TmplTitle  *template.Template
+	Tmpls      eventTemplates
 }
 
 // NewEventProcessContext implements Channel
@@ -40,20 +38,8 @@ func (c *ChannelSlack) Handle(ctx *EventProcessContext) error {
 	title := titleBuf.String()
 
 	buf := &bytes.Buffer{}
-	var t *template.Template
-	switch ctx.Event.Type {
-	case event.TypeAdded:
-		t = c.TmplAdded
-	case event.TypeDeleted:
-		t = c.TmplDeleted
-	case event.TypeUpdated:
-		t = c.TmplUpdated
-	default:
-		return errors.Errorf("unknown event type: %s", ctx.Event.Type)
-	}
-
-	if err := t.Execute(buf, ctx.Event); err != nil {
-		return errors.Wrap(err, "execute template error")
+	if err := c.Tmpls.execute(buf, ctx.Event); err != nil {
+		return err
 	}
 
 	err := slack.PostWebhook(c.WebhookURL, &slack.WebhookMessage{
@@ -104,18 +90,23 @@ func NewChannelSlack(config *spg.ChannelSlackConfig) (*ChannelSlack, error) {
 		return nil, errors.Wrap(err, "parse title template error")
 	}
 
-	tmplAdded, tmplDeleted, tmplUpdated, err := parseTemplates(
-		config.AddedTemplate, config.DeletedTemplate, config.UpdatedTemplate)
+	tmpls, err := parseTemplates(map[event.Type]string{
+		event.TypeAdded:     config.AddedTemplate,
+		event.TypeDeleted:   config.DeletedTemplate,
+		event.TypeUpdated:   config.UpdatedTemplate,
+		event.TypeTriggered: config.TriggeredTemplate,
+		event.TypeResolved:  config.ResolvedTemplate,
+		event.TypeStuck:     config.StuckTemplate,
+		event.TypeOccurred:  config.OccurredTemplate,
+	})
 	if err != nil {
 		return nil, errors.Wrap(err, "parse template error")
 	}
 
 	return &ChannelSlack{
-		Client:      client,
-		WebhookURL:  config.WebhookURL,
-		TmplTitle:   tmplTitle,
-		TmplAdded:   tmplAdded,
-		TmplDeleted: tmplDeleted,
-		TmplUpdated: tmplUpdated,
+		Client:     client,
+		WebhookURL: config.WebhookURL,
+		TmplTitle:  tmplTitle,
+		Tmpls:      tmpls,
 	}, nil
 }
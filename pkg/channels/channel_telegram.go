@@ -11,17 +11,14 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
-	"text/template"
 )
 
 // ChannelTelegram is the Telegram channel
 type ChannelTelegram struct {
-	Client      *http.Client
-	Token       string
-	ChatIDs     []string
-	TmplAdded   *template.Template
-	TmplDeleted *template.Template
-	TmplUpdated *template.Template
+	Client  *http.Client
+	Token   string
+	ChatIDs []string
+	Tmpls   eventTemplates
 }
 
 // NewEventProcessContext implements Channel
@@ -70,21 +67,9 @@ func (c *ChannelTelegram) sendToRecipient(chatID string, text string) error {
 func (c *ChannelTelegram) Handle(ctx *EventProcessContext) error {
 	chatIDs := ctx.Data.([]string)
 
-	var t *template.Template
-	switch ctx.Event.Type {
-	case event.TypeAdded:
-		t = c.TmplAdded
-	case event.TypeDeleted:
-		t = c.TmplDeleted
-	case event.TypeUpdated:
-		t = c.TmplUpdated
-	default:
-		return errors.Errorf("unknown event type: %s", ctx.Event.Type)
-	}
-
 	buf := &bytes.Buffer{}
-	if err := t.Execute(buf, ctx.Event); err != nil {
-		return errors.Wrap(err, "execute template error")
+	if err := c.Tmpls.execute(buf, ctx.Event); err != nil {
+		return err
 	}
 	message := buf.String()
 
@@ -136,8 +121,15 @@ func NewChannelTelegram(config *spg.ChannelTelegramConfig) (*ChannelTelegram, er
 		httpClient = http.DefaultClient
 	}
 
-	tmplAdded, tmplDeleted, tmplUpdated, err := parseTemplates(
-		config.AddedTemplate, config.DeletedTemplate, config.UpdatedTemplate)
+	tmpls, err := parseTemplates(map[event.Type]string{
+		event.TypeAdded:     config.AddedTemplate,
+		event.TypeDeleted:   config.DeletedTemplate,
+		event.TypeUpdated:   config.UpdatedTemplate,
+		event.TypeTriggered: config.TriggeredTemplate,
+		event.TypeResolved:  config.ResolvedTemplate,
+		event.TypeStuck:     config.StuckTemplate,
+		event.TypeOccurred:  config.OccurredTemplate,
+	})
 	if err != nil {
 		return nil, errors.Wrap(err, "parse template error")
 	}
@@ -148,11 +140,9 @@ func NewChannelTelegram(config *spg.ChannelTelegramConfig) (*ChannelTelegram, er
 	}
 
 	return &ChannelTelegram{
-		Client:      httpClient,
-		Token:       config.Token,
-		ChatIDs:     chatIDs,
-		TmplAdded:   tmplAdded,
-		TmplDeleted: tmplDeleted,
-		TmplUpdated: tmplUpdated,
+		Client:  httpClient,
+		Token:   config.Token,
+		ChatIDs: chatIDs,
+		Tmpls:   tmpls,
 	}, nil
 }
@@ -3,14 +3,51 @@ package channels
 import (
 	"fmt"
 	"github.com/pkg/errors"
+	"github.com/spongeprojects/kubebigbrother/pkg/event"
+	"io"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"strings"
 	"text/template"
 )
 
-// parseTemplates parses added, deleted and updated templates
-func parseTemplates(addedTmpl, deletedTmpl, updatedTmpl string) (
-	tmplAdded, tmplDeleted, tmplUpdated *template.Template, err error) {
+// eventTemplates maps from event type to template
+type eventTemplates map[event.Type]*template.Template
+
+// execute executes the template for type of the event
+func (t eventTemplates) execute(w io.Writer, e *event.Event) error {
+	tmpl, ok := t[e.Type]
+	if !ok {
+		return errors.Errorf("unknown event type: %s", e.Type)
+	}
+	if err := tmpl.Execute(w, e); err != nil {
+		return errors.Wrap(err, "execute template error")
+	}
+	return nil
+}
+
+// defaultTemplates are used when template of an event type is not set
+var defaultTemplates = map[event.Type]string{
+	event.TypeAdded:   "Resource [{{.Obj.GroupVersionKind}}, {{.Obj.GetNamespace}}/{{.Obj.GetName}}] has been added\n",
+	event.TypeDeleted: "Resource [{{.Obj.GroupVersionKind}}, {{.Obj.GetNamespace}}/{{.Obj.GetName}}] has been deleted\n",
+	event.TypeUpdated: "Resource [{{.Obj.GroupVersionKind}}, {{.Obj.GetNamespace}}/{{.Obj.GetName}}] has been updated" +
+		"{{with .UpdatedFields}}: {{join . \", \"}}{{end}}\n",
+	event.TypeTriggered: "Resource [{{.Obj.GroupVersionKind}}, {{.Obj.GetNamespace}}/{{.Obj.GetName}}] " +
+		"has triggered: {{.TriggerExpression}}\n",
+	event.TypeResolved: "Resource [{{.Obj.GroupVersionKind}}, {{.Obj.GetNamespace}}/{{.Obj.GetName}}] " +
+		"has resolved: {{.TriggerExpression}}\n",
+	event.TypeStuck: "Resource [{{.Obj.GroupVersionKind}}, {{.Obj.GetNamespace}}/{{.Obj.GetName}}] " +
+		"has been stuck for {{.StuckFor}} ({{.TimerName}}): {{.TriggerExpression}}\n",
+	event.TypeOccurred: "{{with .KubeEvent}}[{{.Type}}] {{.InvolvedObject}} {{.Reason}}: {{.Message}}" +
+		"{{if gt .Count 1}} (x{{.Count}}){{end}}{{end}}\n",
+}
+
+// defaultTemplatePrefix is prepended to default templates,
+// it shows the cluster when watching multiple clusters
+const defaultTemplatePrefix = "{{with .Cluster}}[{{.}}] {{end}}"
+
+// parseTemplates parses templates of every event type,
+// default template is used for empty or missing ones.
+func parseTemplates(tmpls map[event.Type]string) (eventTemplates, error) {
 	funcMap := template.FuncMap{
 		"field": func(s *unstructured.Unstructured, path ...string) string {
 			// methods can be used in template:
@@ -25,35 +62,24 @@ func parseTemplates(addedTmpl, deletedTmpl, updatedTmpl string) (
 			}
 			return str
 		},
+		"join": strings.Join,
 	}
 
 	// example of using field:
 	// tmpl = "[{{.Obj.GroupVersionKind}}] is created: " +
 	//  "{{.Obj.GetNamespace}}/{{.Obj.GetName}} {{field .Obj \"kind\"}}\n"
-	if addedTmpl == "" {
-		addedTmpl = "Resource [{{.Obj.GroupVersionKind}}, {{.Obj.GetNamespace}}/{{.Obj.GetName}}] has been added\n"
-	}
-	if deletedTmpl == "" {
-		deletedTmpl = "Resource [{{.Obj.GroupVersionKind}}, {{.Obj.GetNamespace}}/{{.Obj.GetName}}] has been deleted\n"
-	}
-	if updatedTmpl == "" {
-		updatedTmpl = "Resource [{{.Obj.GroupVersionKind}}, {{.Obj.GetNamespace}}/{{.Obj.GetName}}] has been updated\n"
-	}
-
-	tmplAdded, err = template.New("").Funcs(funcMap).Parse(addedTmpl)
-	if err != nil {
-		return nil, nil, nil, errors.Wrap(err, "invalid added template")
-	}
-
-	tmplDeleted, err = template.New("").Funcs(funcMap).Parse(deletedTmpl)
-	if err != nil {
-		return nil, nil, nil, errors.Wrap(err, "invalid  deleted template")
-	}
-
-	tmplUpdated, err = template.New("").Funcs(funcMap).Parse(updatedTmpl)
-	if err != nil {
-		return nil, nil, nil, errors.Wrap(err, "invalid  updated template")
+	parsed := make(eventTemplates, len(defaultTemplates))
+	for t, defaultTmpl := range defaultTemplates {
+		tmpl := tmpls[t]
+		if tmpl == "" {
+			tmpl = defaultTemplatePrefix + defaultTmpl
+		}
+		var err error
+		parsed[t], err = template.New("").Funcs(funcMap).Parse(tmpl)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s template", strings.ToLower(string(t)))
+		}
 	}
 
-	return tmplAdded, tmplDeleted, tmplUpdated, nil
+	return parsed, nil
 }
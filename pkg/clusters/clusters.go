@@ -0,0 +1,109 @@
+package clusters
+
+import (
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Cluster is a Kubernetes cluster to watch
+type Cluster struct {
+	// Name is the name of the cluster, it's recorded in every event,
+	// empty for the only cluster when not watching multiple clusters.
+	Name string
+
+	RESTConfig *rest.Config
+}
+
+// Config is config to discover clusters,
+// Contexts and KubeconfigDir are exclusive,
+// when neither is set, the current context of Kubeconfig is used.
+type Config struct {
+	// Kubeconfig is path to kubeconfig file
+	Kubeconfig string
+
+	// Contexts are contexts in Kubeconfig, each of them is a cluster named after the context
+	Contexts []string
+
+	// KubeconfigDir is a directory of kubeconfig files,
+	// each of them is a cluster named after the file name without extension
+	KubeconfigDir string
+}
+
+// Discover discovers clusters to watch
+func Discover(config Config) ([]Cluster, error) {
+	switch {
+	case len(config.Contexts) > 0 && config.KubeconfigDir != "":
+		return nil, errors.New("contexts and kubeconfig dir cannot be set simultaneously")
+	case len(config.Contexts) > 0:
+		return FromContexts(config.Kubeconfig, config.Contexts)
+	case config.KubeconfigDir != "":
+		return FromDir(config.KubeconfigDir)
+	default:
+		restConfig, err := clientcmd.BuildConfigFromFlags("", config.Kubeconfig)
+		if err != nil {
+			return nil, errors.Wrap(err, "get kube config error")
+		}
+		return []Cluster{{Name: "", RESTConfig: restConfig}}, nil
+	}
+}
+
+// FromContexts builds clusters from contexts in kubeconfig
+func FromContexts(kubeconfig string, contexts []string) ([]Cluster, error) {
+	var clusters []Cluster
+	for _, context := range contexts {
+		restConfig, err := BuildConfig(kubeconfig, context)
+		if err != nil {
+			return nil, errors.Wrapf(err, "get kube config error, context: %s", context)
+		}
+		clusters = append(clusters, Cluster{
+			Name:       context,
+			RESTConfig: restConfig,
+		})
+	}
+	return clusters, nil
+}
+
+// FromDir builds clusters from kubeconfig files in dir, hidden files are skipped
+func FromDir(dir string) ([]Cluster, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "read kubeconfig dir error")
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name() < files[j].Name()
+	})
+
+	var clusters []Cluster
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+		kubeconfig := filepath.Join(dir, file.Name())
+		restConfig, err := BuildConfig(kubeconfig, "")
+		if err != nil {
+			return nil, errors.Wrapf(err, "get kube config error, file: %s", kubeconfig)
+		}
+		clusters = append(clusters, Cluster{
+			Name:       strings.TrimSuffix(file.Name(), filepath.Ext(file.Name())),
+			RESTConfig: restConfig,
+		})
+	}
+	if len(clusters) == 0 {
+		return nil, errors.Errorf("no kubeconfig found in dir: %s", dir)
+	}
+	return clusters, nil
+}
+
+// BuildConfig builds rest config from kubeconfig and context,
+// the current context is used when context is empty.
+func BuildConfig(kubeconfig, context string) (*rest.Config, error) {
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig},
+		&clientcmd.ConfigOverrides{CurrentContext: context},
+	).ClientConfig()
+}
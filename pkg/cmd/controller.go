@@ -6,6 +6,9 @@ import (
 	"github.com/spf13/viper"
 	"github.com/spongeprojects/kubebigbrother/pkg/cmd/controller"
 	"github.com/spongeprojects/kubebigbrother/pkg/cmd/genericoptions"
+	"github.com/spongeprojects/kubebigbrother/pkg/cmd/server"
+	"github.com/spongeprojects/kubebigbrother/pkg/informers"
+	"github.com/spongeprojects/kubebigbrother/pkg/stores/event_store"
 	"github.com/spongeprojects/kubebigbrother/pkg/utils/signals"
 	"github.com/spongeprojects/magicconch"
 	"k8s.io/klog/v2"
@@ -16,11 +19,32 @@ type controllerOptions struct {
 	GlobalOptions     *genericoptions.GlobalOptions
 	DatabaseOptions   *genericoptions.DatabaseOptions
 	KubeconfigOptions *genericoptions.KubeconfigOptions
+	StorageOptions    *genericoptions.StorageOptions
 
+	Contexts            []string
+	KubeconfigDir       string
+	ConfigSource        string
+	ConfigPath          string
 	DefaultWorkers      int
 	DefaultMaxRetries   int
 	DefaultChannelNames []string
+	DefaultOwnerDepth   int
 	MinResyncPeriod     time.Duration
+	AuditWebhookAddr    string
+	AuditWebhookTLS     auditWebhookTLSOptions
+	Retention           event_store.RetentionPolicy
+	PruneInterval       time.Duration
+	PruneOptimize       bool
+	ServeAddr           string
+	RedactKeyFile       string
+}
+
+// auditWebhookTLSOptions are credentials of the audit webhook
+type auditWebhookTLSOptions struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+	TokensFile   string
 }
 
 func getControllerOptions() *controllerOptions {
@@ -28,10 +52,32 @@ func getControllerOptions() *controllerOptions {
 		GlobalOptions:       genericoptions.GetGlobalOptions(),
 		DatabaseOptions:     genericoptions.GetDatabaseOptions(),
 		KubeconfigOptions:   genericoptions.GetKubeconfigOptions(),
+		StorageOptions:      genericoptions.GetStorageOptions(),
+		Contexts:            viper.GetStringSlice("contexts"),
+		KubeconfigDir:       viper.GetString("kubeconfig-dir"),
+		ConfigSource:        viper.GetString("config-source"),
+		ConfigPath:          viper.GetString("config-path"),
 		DefaultWorkers:      viper.GetInt("default-workers"),
 		DefaultMaxRetries:   viper.GetInt("default-max-retries"),
 		DefaultChannelNames: viper.GetStringSlice("default-channel-names"),
+		DefaultOwnerDepth:   viper.GetInt("default-owner-depth"),
 		MinResyncPeriod:     viper.GetDuration("min-resync-period"),
+		AuditWebhookAddr:    viper.GetString("audit-webhook-addr"),
+		AuditWebhookTLS: auditWebhookTLSOptions{
+			CertFile:     viper.GetString("audit-webhook-tls-cert-file"),
+			KeyFile:      viper.GetString("audit-webhook-tls-key-file"),
+			ClientCAFile: viper.GetString("audit-webhook-client-ca-file"),
+			TokensFile:   viper.GetString("audit-webhook-tokens-file"),
+		},
+		Retention: event_store.RetentionPolicy{
+			MaxAge:      viper.GetDuration("retention-max-age"),
+			MaxEvents:   viper.GetInt("retention-max-events"),
+			MaxVersions: viper.GetInt("retention-max-versions"),
+		},
+		PruneInterval: viper.GetDuration("prune-interval"),
+		PruneOptimize: viper.GetBool("prune-optimize"),
+		ServeAddr:     viper.GetString("serve-addr"),
+		RedactKeyFile: viper.GetString("redact-key-file"),
 	}
 	return o
 }
@@ -44,13 +90,28 @@ func newControllerCommand() *cobra.Command {
 			o := getControllerOptions()
 
 			c, err := controller.Setup(controller.Config{
-				DBDialect:           o.DatabaseOptions.DBDialect,
-				DBArgs:              o.DatabaseOptions.DBArgs,
-				Kubeconfig:          o.KubeconfigOptions.Kubeconfig,
-				DefaultWorkers:      o.DefaultWorkers,
-				DefaultMaxRetries:   o.DefaultMaxRetries,
-				DefaultChannelNames: o.DefaultChannelNames,
-				MinResyncPeriod:     o.MinResyncPeriod,
+				DBDialect:                o.DatabaseOptions.DBDialect,
+				DBArgs:                   o.DatabaseOptions.DBArgs,
+				Storage:                  o.StorageOptions.EventStoreOptions(),
+				Kubeconfig:               o.KubeconfigOptions.Kubeconfig,
+				Contexts:                 o.Contexts,
+				KubeconfigDir:            o.KubeconfigDir,
+				ConfigSource:             o.ConfigSource,
+				ConfigPath:               o.ConfigPath,
+				DefaultWorkers:           o.DefaultWorkers,
+				DefaultMaxRetries:        o.DefaultMaxRetries,
+				DefaultChannelNames:      o.DefaultChannelNames,
+				DefaultOwnerDepth:        o.DefaultOwnerDepth,
+				MinResyncPeriod:          o.MinResyncPeriod,
+				AuditWebhookAddr:         o.AuditWebhookAddr,
+				AuditWebhookTLSCertFile:  o.AuditWebhookTLS.CertFile,
+				AuditWebhookTLSKeyFile:   o.AuditWebhookTLS.KeyFile,
+				AuditWebhookClientCAFile: o.AuditWebhookTLS.ClientCAFile,
+				AuditWebhookTokensFile:   o.AuditWebhookTLS.TokensFile,
+				Retention:                o.Retention,
+				PruneInterval:            o.PruneInterval,
+				PruneOptimize:            o.PruneOptimize,
+				RedactKeyFile:            o.RedactKeyFile,
 			})
 			if err != nil {
 				klog.Exit(errors.Wrap(err, "setup controller error"))
@@ -58,6 +119,27 @@ func newControllerCommand() *cobra.Command {
 
 			stopCh := signals.SetupSignalHandler()
 
+			if o.ServeAddr != "" {
+				// APIs are served from the event store of the controller,
+				// e.g. a bolt file is locked by the controller and can't be opened by serve
+				app, err := server.SetupApp(&server.Config{
+					Env:        o.GlobalOptions.Env,
+					Version:    Version,
+					Addr:       o.ServeAddr,
+					Kubeconfig: o.KubeconfigOptions.Kubeconfig,
+					EventStore: c.EventStore,
+				})
+				if err != nil {
+					klog.Exit(errors.Wrap(err, "setup app error"))
+				}
+				go func() {
+					klog.Infof("serving APIs on: %s", app.Addr)
+					if err := app.Run(stopCh); err != nil {
+						klog.Exit(errors.Wrap(err, "serve error"))
+					}
+				}()
+			}
+
 			if err := c.Start(stopCh); err != nil {
 				klog.Exit(errors.Wrap(err, "start controller error"))
 			}
@@ -71,8 +153,26 @@ func newControllerCommand() *cobra.Command {
 	f.Int("default-workers", 3, "default workers")
 	f.Int("default-max-retries", 3, "default max retries")
 	f.StringSlice("default-channel-names", nil, "default channel names")
+	f.Int("default-owner-depth", 3, "default max depth of owner chains resolved, e.g. 2 for Pod -> ReplicaSet -> Deployment, -1 disables resolving")
 	f.Duration("min-resync-period", 12*time.Hour, "min resync period (from n to 2n)")
+	f.StringSlice("contexts", nil, "kubeconfig contexts of clusters to watch, all events are tagged with context name")
+	f.String("kubeconfig-dir", "", "directory of kubeconfig files of clusters to watch, all events are tagged with file name")
+	f.String("config-source", informers.ConfigSourceCRD, "where channels and watchers are loaded from: file|crd|both")
+	f.String("config-path", "", "YAML file or directory of channels and watchers, used with --config-source=file|both, hot reloaded")
+	f.String("audit-webhook-addr", "", "address to receive audit events from the audit webhook backend on /audit over TLS, e.g. 0.0.0.0:8985, disabled when empty")
+	f.String("audit-webhook-tls-cert-file", "", "serving certificate of the audit webhook, required with --audit-webhook-addr")
+	f.String("audit-webhook-tls-key-file", "", "serving private key of the audit webhook, required with --audit-webhook-addr")
+	f.String("audit-webhook-client-ca-file", "", "CA to verify client certificates of API servers, CommonName of a certificate is the cluster name")
+	f.String("audit-webhook-tokens-file", "", "file of lines of \"token,cluster\", bearer tokens of API servers and their cluster names")
+	f.Duration("retention-max-age", 0, "max age of events, e.g. 720h, unlimited when 0, overridden by retention of watchers")
+	f.Int("retention-max-events", 0, "max number of events of a watcher, unlimited when 0, overridden by retention of watchers")
+	f.Int("retention-max-versions", 0, "max number of events of an object, unlimited when 0, overridden by retention of watchers")
+	f.Duration("prune-interval", time.Hour, "interval to prune events by retention policies, disabled when 0")
+	f.Bool("prune-optimize", false, "reclaim space and update statistics after events are pruned, e.g. VACUUM and ANALYZE")
+	f.String("redact-key-file", "", "file of the key of hashes of redacted values, e.g. values of Secrets, generated if not exists, a random key is used when empty")
+	f.String("serve-addr", "", "address to serve backend APIs from the controller as serve does, e.g. 0.0.0.0:8984, disabled when empty")
 	genericoptions.AddDatabaseFlags(f)
+	genericoptions.AddStorageFlags(f)
 	genericoptions.AddKubeconfigFlags(f)
 	magicconch.Must(viper.BindPFlags(f))
 
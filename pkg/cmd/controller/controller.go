@@ -1,62 +1,234 @@
 package controller
 
 import (
+	"context"
 	"github.com/pkg/errors"
-	"github.com/spongeprojects/kubebigbrother/pkg/gormdb"
+	"github.com/spongeprojects/kubebigbrother/pkg/audit"
+	"github.com/spongeprojects/kubebigbrother/pkg/clusters"
 	"github.com/spongeprojects/kubebigbrother/pkg/informers"
+	"github.com/spongeprojects/kubebigbrother/pkg/redact"
 	"github.com/spongeprojects/kubebigbrother/pkg/stores/event_store"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"net/http"
 	"time"
 )
 
 type Config struct {
 	DBDialect           string
 	DBArgs              string
+	Storage             event_store.Options
 	Kubeconfig          string
+	Contexts            []string
+	KubeconfigDir       string
+	ConfigSource        string
+	ConfigPath          string
 	DefaultWorkers      int
 	DefaultMaxRetries   int
 	DefaultChannelNames []string
+	DefaultOwnerDepth   int
 	MinResyncPeriod     time.Duration
+
+	// AuditWebhookAddr is the address to receive audit events, disabled when empty
+	AuditWebhookAddr string
+
+	// AuditWebhookTLSCertFile and AuditWebhookTLSKeyFile are the serving certificate of the audit server
+	AuditWebhookTLSCertFile string
+	AuditWebhookTLSKeyFile  string
+
+	// AuditWebhookClientCAFile verifies client certificates of API servers, CommonNames are cluster names
+	AuditWebhookClientCAFile string
+
+	// AuditWebhookTokensFile maps bearer tokens of API servers to cluster names
+	AuditWebhookTokensFile string
+
+	// Retention is the global retention policy of events
+	Retention event_store.RetentionPolicy
+
+	// PruneInterval is the interval to prune events, pruning is disabled when 0
+	PruneInterval time.Duration
+
+	// PruneOptimize reclaims space after events are pruned, e.g. VACUUM
+	PruneOptimize bool
+
+	// RedactKeyFile is the file of the key of hashes of redacted values, generated if not exists,
+	// a random key is used when empty, hashes are not comparable across restarts then.
+	RedactKeyFile string
 }
 
 type Controller struct {
 	EventStore event_store.Interface
-	Informers  informers.Interface
+
+	// Informers are informer sets, one for each cluster
+	Informers []informers.Interface
+
+	// AuditServer receives audit events to attribute changes, nil when disabled
+	AuditServer *http.Server
+
+	auditCertFile string
+	auditKeyFile  string
+
+	pruner        *pruner
+	pruneInterval time.Duration
 }
 
-// Start starts the Controller
+// Start starts the Controller, blocks until all clusters stopped
 func (c *Controller) Start(stopCh <-chan struct{}) error {
-	return c.Informers.Start(stopCh)
+	if c.AuditServer != nil {
+		go func() {
+			klog.Infof("receiving audit events on %s", c.AuditServer.Addr)
+			err := c.AuditServer.ListenAndServeTLS(c.auditCertFile, c.auditKeyFile)
+			if err != nil && err != http.ErrServerClosed {
+				klog.Error(errors.Wrap(err, "audit server error"))
+			}
+		}()
+	}
+
+	if c.pruneInterval > 0 {
+		go wait.Until(c.pruner.run, c.pruneInterval, stopCh)
+	}
+
+	errCh := make(chan error, len(c.Informers))
+	for _, informerSet := range c.Informers {
+		go func(informerSet informers.Interface) {
+			errCh <- informerSet.Start(stopCh)
+		}(informerSet)
+	}
+	for range c.Informers {
+		if err := <-errCh; err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Shutdown shutdowns the Controller
 func (c *Controller) Shutdown() {
-	c.Informers.Shutdown()
+	if c.AuditServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = c.AuditServer.Shutdown(ctx)
+	}
+	for _, informerSet := range c.Informers {
+		informerSet.Shutdown()
+	}
 }
 
 // Setup sets up a new Controller
 func Setup(config Config) (*Controller, error) {
 	controller := &Controller{}
 
-	db, err := gormdb.New(config.DBDialect, config.DBArgs)
+	if config.RedactKeyFile != "" {
+		key, err := redact.LoadKey(config.RedactKeyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "load redact key error")
+		}
+		redact.SetKey(key)
+	} else {
+		klog.Warning("--redact-key-file is not set, hashes of redacted values are not comparable across restarts")
+	}
+
+	var err error
+	controller.EventStore, err = event_store.Open(config.DBDialect, config.DBArgs, config.Storage)
 	if err != nil {
-		return nil, errors.Wrap(err, "create db instance error")
+		return nil, errors.Wrap(err, "create event store error")
 	}
 
-	controller.EventStore = event_store.New(db)
+	controller.pruner = &pruner{
+		store:        controller.EventStore,
+		policy:       config.Retention,
+		informerSets: make(map[string]*informers.InformerSet),
+		optimize:     config.PruneOptimize,
+	}
+	controller.pruneInterval = config.PruneInterval
+
+	var auditCache *audit.Cache
+	var auditAuth *audit.Authenticator
+	if config.AuditWebhookAddr != "" {
+		auditCache = audit.NewCache()
+		auditAuth, err = setupAuditServer(controller, config, auditCache)
+		if err != nil {
+			return nil, errors.Wrap(err, "setup audit server error")
+		}
+	}
 
-	informerInstance, err := informers.Setup(informers.Config{
-		Kubeconfig:          config.Kubeconfig,
-		DefaultWorkers:      config.DefaultWorkers,
-		DefaultMaxRetries:   config.DefaultMaxRetries,
-		DefaultChannelNames: config.DefaultChannelNames,
-		MinResyncPeriod:     config.MinResyncPeriod,
-		JustWatch:           false,
-		EventStore:          controller.EventStore,
+	clusterList, err := clusters.Discover(clusters.Config{
+		Kubeconfig:    config.Kubeconfig,
+		Contexts:      config.Contexts,
+		KubeconfigDir: config.KubeconfigDir,
 	})
 	if err != nil {
-		return nil, errors.Wrap(err, "setup informers error")
+		return nil, errors.Wrap(err, "discover clusters error")
+	}
+
+	for _, cluster := range clusterList {
+		if cluster.Name != "" {
+			klog.Infof("watching cluster: %s", cluster.Name)
+		}
+		if auditAuth != nil {
+			auditAuth.Clusters[cluster.Name] = true
+		}
+
+		informerInstance, err := informers.Setup(informers.Config{
+			Cluster:             cluster.Name,
+			RESTConfig:          cluster.RESTConfig,
+			DefaultWorkers:      config.DefaultWorkers,
+			DefaultMaxRetries:   config.DefaultMaxRetries,
+			DefaultChannelNames: config.DefaultChannelNames,
+			DefaultOwnerDepth:   config.DefaultOwnerDepth,
+			MinResyncPeriod:     config.MinResyncPeriod,
+			JustWatch:           false,
+			EventStore:          controller.EventStore,
+			ConfigSource:        config.ConfigSource,
+			ConfigPath:          config.ConfigPath,
+			AuditCache:          auditCache,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "setup informers error, cluster: %s", cluster.Name)
+		}
+		controller.Informers = append(controller.Informers, informerInstance)
+		controller.pruner.informerSets[cluster.Name] = informerInstance
 	}
-	controller.Informers = informerInstance
 
 	return controller, nil
 }
+
+// setupAuditServer sets up the audit server serving TLS, API servers are authenticated by
+// client certificates or bearer tokens, clusters are added to the authenticator when discovered.
+func setupAuditServer(controller *Controller, config Config,
+	auditCache *audit.Cache) (*audit.Authenticator, error) {
+	if config.AuditWebhookTLSCertFile == "" || config.AuditWebhookTLSKeyFile == "" {
+		return nil, errors.New("--audit-webhook-tls-cert-file and --audit-webhook-tls-key-file are required")
+	}
+	if config.AuditWebhookClientCAFile == "" && config.AuditWebhookTokensFile == "" {
+		return nil, errors.New("--audit-webhook-client-ca-file or --audit-webhook-tokens-file is required")
+	}
+
+	auth := &audit.Authenticator{Clusters: make(map[string]bool)}
+	if config.AuditWebhookTokensFile != "" {
+		tokens, err := audit.LoadTokens(config.AuditWebhookTokensFile)
+		if err != nil {
+			return nil, err
+		}
+		auth.Tokens = tokens
+	}
+	tlsConfig, err := audit.TLSConfig(config.AuditWebhookClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/audit", &audit.Receiver{
+		Store: controller.EventStore,
+		Cache: auditCache,
+		Auth:  auth,
+	})
+	controller.AuditServer = &http.Server{
+		Addr:      config.AuditWebhookAddr,
+		Handler:   mux,
+		TLSConfig: tlsConfig,
+	}
+	controller.auditCertFile = config.AuditWebhookTLSCertFile
+	controller.auditKeyFile = config.AuditWebhookTLSKeyFile
+	return auth, nil
+}
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spongeprojects/kubebigbrother/pkg/informers"
+	"github.com/spongeprojects/kubebigbrother/pkg/stores/event_store"
+	"k8s.io/klog/v2"
+)
+
+// pruner prunes events by retention policies periodically
+type pruner struct {
+	store event_store.Interface
+
+	// policy is the global retention policy, overridden by policies of watchers
+	policy event_store.RetentionPolicy
+
+	// informerSets maps from cluster name to informer set, to find policies of watchers
+	informerSets map[string]*informers.InformerSet
+
+	// optimize reclaims space after events are pruned
+	optimize bool
+}
+
+func (p *pruner) run() {
+	policies := make(map[string]map[string]event_store.RetentionPolicy, len(p.informerSets))
+	for cluster, informerSet := range p.informerSets {
+		policies[cluster] = informerSet.RetentionPolicies()
+	}
+	policyOf := func(cluster, informerName string) event_store.RetentionPolicy {
+		if override, ok := policies[cluster][informerName]; ok {
+			return p.policy.Override(override)
+		}
+		return p.policy
+	}
+
+	results, err := p.store.Prune(policyOf, event_store.PruneOptions{})
+	var total int64
+	for _, result := range results {
+		klog.V(2).Infof("[pruner] %d events pruned: %s", result.Total(), result.InformerName)
+		total += result.Total()
+	}
+	if err != nil {
+		klog.Warning(errors.Wrap(err, "prune events error"))
+		return
+	}
+	klog.V(1).Infof("[pruner] %d events pruned", total)
+
+	if p.optimize && total > 0 {
+		if err := p.store.Optimize(); err != nil {
+			klog.Warning(errors.Wrap(err, "optimize database error"))
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/spongeprojects/kubebigbrother/pkg/cmd/genericoptions"
+	"github.com/spongeprojects/kubebigbrother/pkg/gormdb"
+	"github.com/spongeprojects/kubebigbrother/pkg/stores/event_store"
+	"github.com/spongeprojects/magicconch"
+	"k8s.io/klog/v2"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+func newDBCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Maintain the database",
+	}
+
+	cmd.AddCommand(
+		newDBPruneCommand(),
+		newDBConvertCommand(),
+		newDBMigrateCommand(),
+		newDBReindexCommand(),
+	)
+
+	return cmd
+}
+
+type dbPruneOptions struct {
+	GlobalOptions   *genericoptions.GlobalOptions
+	DatabaseOptions *genericoptions.DatabaseOptions
+
+	Retention event_store.RetentionPolicy
+	BatchSize int
+	DryRun    bool
+	Optimize  bool
+}
+
+func getDBPruneOptions() *dbPruneOptions {
+	o := &dbPruneOptions{
+		GlobalOptions:   genericoptions.GetGlobalOptions(),
+		DatabaseOptions: genericoptions.GetDatabaseOptions(),
+		Retention: event_store.RetentionPolicy{
+			MaxAge:      viper.GetDuration("max-age"),
+			MaxEvents:   viper.GetInt("max-events"),
+			MaxVersions: viper.GetInt("max-versions"),
+		},
+		BatchSize: viper.GetInt("batch-size"),
+		DryRun:    viper.GetBool("dry-run"),
+		Optimize:  viper.GetBool("optimize"),
+	}
+	return o
+}
+
+func newDBPruneCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Prune events by retention policy (retention of watchers is only applied by the controller)",
+		Run: func(cmd *cobra.Command, args []string) {
+			o := getDBPruneOptions()
+			if o.Retention.IsZero() {
+				klog.Exit("at least one of --max-age, --max-events and --max-versions should be set")
+			}
+
+			store, err := event_store.Open(o.DatabaseOptions.DBDialect, o.DatabaseOptions.DBArgs,
+				event_store.Options{})
+			if err != nil {
+				klog.Exit(errors.Wrap(err, "connect to db error"))
+			}
+			results, err := store.Prune(func(string, string) event_store.RetentionPolicy {
+				return o.Retention
+			}, event_store.PruneOptions{
+				BatchSize: o.BatchSize,
+				DryRun:    o.DryRun,
+			})
+			printPruneResults(results, o.DryRun)
+			if err != nil {
+				klog.Exit(errors.Wrap(err, "prune events error"))
+			}
+
+			if o.Optimize && !o.DryRun {
+				if err := store.Optimize(); err != nil {
+					klog.Exit(errors.Wrap(err, "optimize database error"))
+				}
+			}
+		},
+	}
+
+	f := cmd.PersistentFlags()
+	f.Duration("max-age", 0, "max age of events, e.g. 720h, unlimited when 0")
+	f.Int("max-events", 0, "max number of events of a watcher, unlimited when 0")
+	f.Int("max-versions", 0, "max number of events of an object, unlimited when 0")
+	f.Int("batch-size", event_store.DefaultPruneBatchSize, "number of events deleted in a statement")
+	f.Bool("dry-run", false, "report events to prune without deleting them")
+	f.Bool("optimize", false, "reclaim space and update statistics after events are pruned, e.g. VACUUM and ANALYZE")
+	genericoptions.AddDatabaseFlags(f)
+	magicconch.Must(viper.BindPFlags(f))
+
+	return cmd
+}
+
+func printPruneResults(results []event_store.PruneResult, dryRun bool) {
+	if len(results) == 0 {
+		fmt.Println("nothing to prune")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "CLUSTER\tINFORMER\tEXPIRED\tOVER MAX EVENTS\tOVER MAX VERSIONS\tTOTAL")
+	var total int64
+	for _, r := range results {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\n", r.Cluster, r.InformerName,
+			r.Expired, r.OverMaxEvents, r.OverMaxVersions, r.Total())
+		total += r.Total()
+	}
+	_ = w.Flush()
+	if dryRun {
+		fmt.Printf("%d events would be pruned (dry run)\n", total)
+	} else {
+		fmt.Printf("%d events pruned\n", total)
+	}
+}
+
+type dbConvertOptions struct {
+	GlobalOptions   *genericoptions.GlobalOptions
+	DatabaseOptions *genericoptions.DatabaseOptions
+	StorageOptions  *genericoptions.StorageOptions
+
+	DryRun bool
+}
+
+func getDBConvertOptions() *dbConvertOptions {
+	o := &dbConvertOptions{
+		GlobalOptions:   genericoptions.GetGlobalOptions(),
+		DatabaseOptions: genericoptions.GetDatabaseOptions(),
+		StorageOptions:  genericoptions.GetStorageOptions(),
+		DryRun:          viper.GetBool("dry-run"),
+	}
+	return o
+}
+
+func newDBConvertCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "convert",
+		Short: "Convert stored events to the storage mode and compression, e.g. from full objects to compressed diffs",
+		Run: func(cmd *cobra.Command, args []string) {
+			o := getDBConvertOptions()
+
+			store, err := event_store.Open(o.DatabaseOptions.DBDialect, o.DatabaseOptions.DBArgs,
+				o.StorageOptions.EventStoreOptions())
+			if err != nil {
+				klog.Exit(errors.Wrap(err, "connect to db error"))
+			}
+			result, err := store.Convert(o.DryRun)
+			if o.DryRun {
+				fmt.Printf("%d events of %d objects would be converted (dry run)\n",
+					result.Converted, result.Objects)
+			} else {
+				fmt.Printf("%d events of %d objects converted\n", result.Converted, result.Objects)
+			}
+			if err != nil {
+				klog.Exit(errors.Wrap(err, "convert events error"))
+			}
+		},
+	}
+
+	f := cmd.PersistentFlags()
+	f.Bool("dry-run", false, "report events to convert without converting them")
+	genericoptions.AddDatabaseFlags(f)
+	genericoptions.AddStorageFlags(f)
+	magicconch.Must(viper.BindPFlags(f))
+
+	return cmd
+}
+
+type dbMigrateOptions struct {
+	GlobalOptions   *genericoptions.GlobalOptions
+	DatabaseOptions *genericoptions.DatabaseOptions
+
+	Version int
+	Steps   int
+}
+
+func getDBMigrateOptions() *dbMigrateOptions {
+	o := &dbMigrateOptions{
+		GlobalOptions:   genericoptions.GetGlobalOptions(),
+		DatabaseOptions: genericoptions.GetDatabaseOptions(),
+		Version:         viper.GetInt("version"),
+		Steps:           viper.GetInt("steps"),
+	}
+	return o
+}
+
+func newDBMigrateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:       "migrate [up|down|status]",
+		Short:     "Migrate the schema of the database (pending migrations are applied by other commands automatically)",
+		Args:      cobra.ExactValidArgs(1),
+		ValidArgs: []string{"up", "down", "status"},
+		Run: func(cmd *cobra.Command, args []string) {
+			o := getDBMigrateOptions()
+			if o.DatabaseOptions.DBDialect == event_store.DialectBolt {
+				klog.Exit("migrations are not needed by bolt")
+			}
+
+			db, err := gormdb.Open(o.DatabaseOptions.DBDialect, o.DatabaseOptions.DBArgs)
+			if err != nil {
+				klog.Exit(errors.Wrap(err, "connect to db error"))
+			}
+
+			switch args[0] {
+			case "up":
+				migrated, err := gormdb.MigrateUp(db, o.Version)
+				for _, m := range migrated {
+					fmt.Printf("applied %d: %s\n", m.Version, m.Name)
+				}
+				if err != nil {
+					klog.Exit(errors.Wrap(err, "migrate up error"))
+				}
+				if len(migrated) == 0 {
+					fmt.Println("no pending migrations")
+				}
+			case "down":
+				reverted, err := gormdb.MigrateDown(db, o.Steps)
+				for _, m := range reverted {
+					fmt.Printf("reverted %d: %s\n", m.Version, m.Name)
+				}
+				if err != nil {
+					klog.Exit(errors.Wrap(err, "migrate down error"))
+				}
+				if len(reverted) == 0 {
+					fmt.Println("no migrations applied")
+				}
+			case "status":
+				statuses, err := gormdb.Status(db)
+				if err != nil {
+					klog.Exit(errors.Wrap(err, "get migration status error"))
+				}
+				printMigrationStatuses(statuses)
+			}
+		},
+	}
+
+	f := cmd.PersistentFlags()
+	f.Int("version", 0, "version to migrate up to, the latest when 0, used with up")
+	f.Int("steps", 1, "number of migrations to revert, used with down")
+	genericoptions.AddDatabaseFlags(f)
+	magicconch.Must(viper.BindPFlags(f))
+
+	return cmd
+}
+
+func printMigrationStatuses(statuses []gormdb.MigrationStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, s := range statuses {
+		appliedAt := "pending"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format(time.RFC3339)
+		}
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+	}
+	_ = w.Flush()
+}
+
+type dbReindexOptions struct {
+	GlobalOptions   *genericoptions.GlobalOptions
+	DatabaseOptions *genericoptions.DatabaseOptions
+
+	BatchSize int
+}
+
+func getDBReindexOptions() *dbReindexOptions {
+	o := &dbReindexOptions{
+		GlobalOptions:   genericoptions.GetGlobalOptions(),
+		DatabaseOptions: genericoptions.GetDatabaseOptions(),
+		BatchSize:       viper.GetInt("batch-size"),
+	}
+	return o
+}
+
+func newDBReindexCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reindex",
+		Short: "Rebuild the full-text index of contents of objects, e.g. after --index-content is enabled",
+		Run: func(cmd *cobra.Command, args []string) {
+			o := getDBReindexOptions()
+
+			store, err := event_store.Open(o.DatabaseOptions.DBDialect, o.DatabaseOptions.DBArgs,
+				event_store.Options{})
+			if err != nil {
+				klog.Exit(errors.Wrap(err, "connect to db error"))
+			}
+
+			indexed, err := store.Reindex(o.BatchSize)
+			fmt.Printf("%d events indexed\n", indexed)
+			if err != nil {
+				klog.Exit(errors.Wrap(err, "reindex events error"))
+			}
+		},
+	}
+
+	f := cmd.PersistentFlags()
+	f.Int("batch-size", event_store.DefaultReindexBatchSize, "number of events indexed in a transaction")
+	genericoptions.AddDatabaseFlags(f)
+	magicconch.Must(viper.BindPFlags(f))
+
+	return cmd
+}
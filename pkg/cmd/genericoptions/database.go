@@ -21,6 +21,6 @@ func GetDatabaseOptions() *DatabaseOptions {
 
 // AddDatabaseFlags adds database flags to flag set
 func AddDatabaseFlags(fs *pflag.FlagSet) {
-	fs.String("db-dialect", "sqlite", "database dialect [mysql, postgres, sqlite]")
-	fs.String("db-args", "", "database args")
+	fs.String("db-dialect", "sqlite", "database dialect [mysql, postgres, sqlite, bolt]")
+	fs.String("db-args", "", "database args, path of the file for sqlite and bolt")
 }
@@ -0,0 +1,43 @@
+package genericoptions
+
+import (
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/spongeprojects/kubebigbrother/pkg/stores/event_store"
+)
+
+// StorageOptions is options of how events are stored
+type StorageOptions struct {
+	StorageMode      string
+	SnapshotInterval int
+	Compression      string
+	IndexContent     bool
+}
+
+// GetStorageOptions gets storage options from viper flags
+func GetStorageOptions() *StorageOptions {
+	return &StorageOptions{
+		StorageMode:      viper.GetString("storage-mode"),
+		SnapshotInterval: viper.GetInt("snapshot-interval"),
+		Compression:      viper.GetString("compression"),
+		IndexContent:     viper.GetBool("index-content"),
+	}
+}
+
+// EventStoreOptions returns options of the event store
+func (o *StorageOptions) EventStoreOptions() event_store.Options {
+	return event_store.Options{
+		StorageMode:      o.StorageMode,
+		SnapshotInterval: o.SnapshotInterval,
+		Compression:      o.Compression,
+		IndexContent:     o.IndexContent,
+	}
+}
+
+// AddStorageFlags adds storage flags to flag set
+func AddStorageFlags(fs *pflag.FlagSet) {
+	fs.String("storage-mode", event_store.StorageModeFull, "how objects are stored [full, diff], diff stores merge patches against the previous version")
+	fs.Int("snapshot-interval", event_store.DefaultSnapshotInterval, "max number of patches between full snapshots of an object, used with --storage-mode=diff")
+	fs.String("compression", event_store.CompressionNone, "codec objects are compressed with [none, gzip, zstd]")
+	fs.Bool("index-content", false, "index content of objects for full-text search, use kbb db reindex to index events stored already")
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/spongeprojects/kubebigbrother/pkg/cmd/genericoptions"
+	"github.com/spongeprojects/kubebigbrother/pkg/helpers/style"
+	"github.com/spongeprojects/kubebigbrother/pkg/stores/event_store"
+	"github.com/spongeprojects/magicconch"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"strings"
+	"time"
+)
+
+type historyOptions struct {
+	GlobalOptions   *genericoptions.GlobalOptions
+	DatabaseOptions *genericoptions.DatabaseOptions
+
+	Cluster string
+	Limit   int
+}
+
+func getHistoryOptions() *historyOptions {
+	o := &historyOptions{
+		GlobalOptions:   genericoptions.GetGlobalOptions(),
+		DatabaseOptions: genericoptions.GetDatabaseOptions(),
+		Cluster:         viper.GetString("cluster"),
+		Limit:           viper.GetInt("limit"),
+	}
+	return o
+}
+
+// historyOptionsOf parses the resource, e.g. deployments.apps, and the object, namespace/name or name
+func (o *historyOptions) historyOptionsOf(resource, object string) (event_store.HistoryOptions, error) {
+	gr := schema.ParseGroupResource(resource)
+	options := event_store.HistoryOptions{
+		Cluster:  o.Cluster,
+		Group:    gr.Group,
+		Resource: gr.Resource,
+		Name:     object,
+		Limit:    o.Limit,
+	}
+	if parts := strings.SplitN(object, "/", 2); len(parts) == 2 {
+		options.Namespace, options.Name = parts[0], parts[1]
+	}
+	return options, options.Validate()
+}
+
+func newHistoryCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "history <resource> <namespace/name>",
+		Short:   "Show versions of an object with diffs, e.g. history deployments.apps prod/api",
+		Example: "  kbb history deployments.apps prod/api\n  kbb history nodes node-1",
+		Args:    cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			o := getHistoryOptions()
+			options, err := o.historyOptionsOf(args[0], args[1])
+			if err != nil {
+				klog.Exit(errors.Wrap(err, "invalid options"))
+			}
+
+			store, err := event_store.Open(o.DatabaseOptions.DBDialect, o.DatabaseOptions.DBArgs,
+				event_store.Options{ReadOnly: true})
+			if err != nil {
+				klog.Exit(errors.Wrap(err, "connect to db error"))
+			}
+
+			versions, err := store.History(options)
+			if err != nil {
+				klog.Exit(errors.Wrap(err, "list history error"))
+			}
+			printHistory(versions)
+		},
+	}
+
+	f := cmd.PersistentFlags()
+	f.String("cluster", "", "cluster of the object")
+	f.Int("limit", event_store.DefaultHistoryLimit,
+		fmt.Sprintf("number of latest events of the object, at most %d", event_store.MaxHistoryLimit))
+	genericoptions.AddDatabaseFlags(f)
+	magicconch.Must(viper.BindPFlags(f))
+
+	return cmd
+}
+
+func printHistory(versions []event_store.Version) {
+	if len(versions) == 0 {
+		fmt.Println("nothing")
+		return
+	}
+	for _, v := range versions {
+		e := v.Event
+		header := fmt.Sprintf("%s %s ID: %d, resourceVersion: %s, informer: %s",
+			e.CreateTime.Format(time.RFC3339), e.EventType, e.ID, e.ResourceVersion, e.InformerName)
+		if v.Actor != nil {
+			actor := v.Actor.Username
+			if actor == "" {
+				actor = v.Actor.Manager
+			}
+			header += ", by: " + actor
+		}
+		fmt.Println(style.Fg(style.Info, "%s", header))
+		if v.Diff == "" {
+			fmt.Println(style.Faint("no changes"))
+		} else {
+			printDiff(v.Diff)
+		}
+		fmt.Println()
+	}
+}
+
+// printDiff prints unified diff, additions in green, deletions in red
+func printDiff(d string) {
+	for _, line := range strings.Split(strings.TrimSuffix(d, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			fmt.Println(style.Faint("%s", line))
+		case strings.HasPrefix(line, "@@"):
+			fmt.Println(style.Fg(style.Cyan, "%s", line))
+		case strings.HasPrefix(line, "+"):
+			fmt.Println(style.Fg(style.Success, "%s", line))
+		case strings.HasPrefix(line, "-"):
+			fmt.Println(style.Fg(style.Danger, "%s", line))
+		default:
+			fmt.Println(line)
+		}
+	}
+}
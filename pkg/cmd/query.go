@@ -6,54 +6,208 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/spongeprojects/kubebigbrother/pkg/cmd/genericoptions"
-	"github.com/spongeprojects/kubebigbrother/pkg/gormdb"
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
 	"github.com/spongeprojects/kubebigbrother/pkg/stores/event_store"
 	"github.com/spongeprojects/magicconch"
 	"k8s.io/klog/v2"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
 )
 
 type queryOptions struct {
 	GlobalOptions   *genericoptions.GlobalOptions
 	DatabaseOptions *genericoptions.DatabaseOptions
+
+	Cluster   string
+	Informer  string
+	Q         string
+	Owner     string
+	Types     []string
+	Kind      string
+	Resource  string
+	Namespace string
+	Name      string
+	UID       string
+	Since     string
+	Until     string
+	Selector  string
+	Content   string
+	Limit     int
+	Cursor    string
+	Total     bool
+	Objects   bool
 }
 
 func getQueryOptions() *queryOptions {
 	o := &queryOptions{
 		GlobalOptions:   genericoptions.GetGlobalOptions(),
 		DatabaseOptions: genericoptions.GetDatabaseOptions(),
+		Cluster:         viper.GetString("cluster"),
+		Informer:        viper.GetString("informer"),
+		Q:               viper.GetString("q"),
+		Owner:           viper.GetString("owner"),
+		Types:           viper.GetStringSlice("type"),
+		Kind:            viper.GetString("kind"),
+		Resource:        viper.GetString("resource"),
+		Namespace:       viper.GetString("namespace"),
+		Name:            viper.GetString("name"),
+		UID:             viper.GetString("uid"),
+		Since:           viper.GetString("since"),
+		Until:           viper.GetString("until"),
+		Selector:        viper.GetString("selector"),
+		Content:         viper.GetString("content"),
+		Limit:           viper.GetInt("limit"),
+		Cursor:          viper.GetString("cursor"),
+		Total:           viper.GetBool("total"),
+		Objects:         viper.GetBool("objects"),
 	}
 	return o
 }
 
+// parseTime parses RFC3339 time, or duration before now, e.g. 1h, zero when empty
+func parseTime(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, errors.Errorf("invalid time, expect RFC3339 or duration: %s", s)
+	}
+	return t, nil
+}
+
+func (o *queryOptions) listOptions() (event_store.ListOptions, error) {
+	now := time.Now()
+	since, err := parseTime(o.Since, now)
+	if err != nil {
+		return event_store.ListOptions{}, err
+	}
+	until, err := parseTime(o.Until, now)
+	if err != nil {
+		return event_store.ListOptions{}, err
+	}
+	var eventTypes []string
+	for _, t := range o.Types {
+		eventTypes = append(eventTypes, strings.ToUpper(t))
+	}
+	options := event_store.ListOptions{
+		Cluster:       o.Cluster,
+		InformerName:  o.Informer,
+		Q:             o.Q,
+		Owner:         o.Owner,
+		EventTypes:    eventTypes,
+		Kind:          o.Kind,
+		GroupResource: o.Resource,
+		Namespace:     o.Namespace,
+		Name:          o.Name,
+		UID:           o.UID,
+		Since:         since,
+		Until:         until,
+		LabelSelector: o.Selector,
+		Content:       o.Content,
+		Limit:         o.Limit,
+		Cursor:        o.Cursor,
+		CountTotal:    o.Total,
+		OmitObjects:   !o.Objects,
+	}
+	return options, options.Validate()
+}
+
 func newQueryCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "query",
 		Short: "Query event history",
 		Run: func(cmd *cobra.Command, args []string) {
 			o := getQueryOptions()
+			listOptions, err := o.listOptions()
+			if err != nil {
+				klog.Exit(errors.Wrap(err, "invalid options"))
+			}
 
-			db, err := gormdb.New(o.DatabaseOptions.DBDialect, o.DatabaseOptions.DBArgs)
+			store, err := event_store.Open(o.DatabaseOptions.DBDialect, o.DatabaseOptions.DBArgs,
+				event_store.Options{ReadOnly: true})
 			if err != nil {
 				klog.Exit(errors.Wrap(err, "connect to db error"))
 			}
-
-			store := event_store.New(db)
-			events, err := store.List(event_store.ListOptions{})
+			page, err := store.ListPage(listOptions)
 			if err != nil {
 				klog.Exit(errors.Wrap(err, "list events error"))
 			}
-			if len(events) == 0 {
-				fmt.Println("nothing")
-			}
-			for _, event := range events {
-				fmt.Printf("ID: %d, %s\n", event.ID, event.Obj)
-			}
+			printPage(page, o.Objects)
 		},
 	}
 
 	f := cmd.PersistentFlags()
+	f.String("cluster", "", "cluster of events")
+	f.String("informer", "", "informer of events, e.g. watcher-<namespace>-<name>, clusterwatcher-<name>")
+	f.StringP("q", "q", "", "partial match of name, namespace, group, version or resource")
+	f.String("owner", "", "owner of objects at any depth, e.g. Deployment/foo")
+	f.StringSlice("type", nil, "event types, e.g. ADDED,UPDATED")
+	f.String("kind", "", "kind of objects, e.g. Deployment")
+	f.String("resource", "", "resource of objects, e.g. deployments.apps, pods")
+	f.StringP("namespace", "n", "", "namespace of objects")
+	f.String("name", "", "name of objects")
+	f.String("uid", "", "UID of objects")
+	f.String("since", "", "events created since, RFC3339 time or duration ago, e.g. 2h")
+	f.String("until", "", "events created before, RFC3339 time or duration ago, e.g. 1h")
+	f.StringP("selector", "l", "", "label selector of objects, e.g. app=foo,tier in (web,api)")
+	f.String("content", "", "phrase in objects, e.g. a hostname or an image, only events indexed are searched")
+	f.Int("limit", event_store.DefaultListLimit, fmt.Sprintf("number of events in a page, at most %d", event_store.MaxListLimit))
+	f.String("cursor", "", "cursor of the page, printed after the previous page")
+	f.Bool("total", true, "count events matched")
+	f.Bool("objects", false, "print objects of events")
 	genericoptions.AddDatabaseFlags(f)
 	magicconch.Must(viper.BindPFlags(f))
 
 	return cmd
 }
+
+func printPage(page *event_store.Page, objects bool) {
+	if len(page.Events) == 0 {
+		fmt.Println("nothing")
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "ID\tTIME\tCLUSTER\tINFORMER\tTYPE\tKIND\tNAMESPACE\tNAME")
+		for _, e := range page.Events {
+			_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", e.ID,
+				e.CreateTime.Format(time.RFC3339), e.Cluster, e.InformerName,
+				e.EventType, e.Kind, e.Namespace, e.Name)
+			for _, h := range e.Highlights {
+				_, _ = fmt.Fprintf(w, "\t%s\n", highlightReplacer.Replace(h))
+			}
+		}
+		_ = w.Flush()
+	}
+	if objects {
+		for _, e := range page.Events {
+			printObjects(&e)
+		}
+	}
+
+	if page.Total != nil {
+		fmt.Printf("total: %d\n", *page.Total)
+	}
+	if page.NextCursor != "" {
+		fmt.Printf("older: --cursor %s\n", page.NextCursor)
+	}
+	if page.PrevCursor != "" {
+		fmt.Printf("newer: --cursor %s\n", page.PrevCursor)
+	}
+}
+
+// highlightReplacer renders highlights in terminals, matches in bold
+var highlightReplacer = strings.NewReplacer(
+	"<mark>", "\x1b[1m", "</mark>", "\x1b[0m",
+	"&lt;", "<", "&gt;", ">", "&amp;", "&", "&#39;", "'", "&#34;", `"`)
+
+func printObjects(e *models.Event) {
+	fmt.Printf("--- ID: %d, obj:\n%s\n", e.ID, e.Obj)
+	if e.OldObj != nil {
+		fmt.Printf("--- ID: %d, old obj:\n%s\n", e.ID, e.OldObj)
+	}
+}
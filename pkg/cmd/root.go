@@ -18,12 +18,20 @@ var Version = "unknown"
 func NewKbbCommand() *cobra.Command {
 	var cmd = &cobra.Command{
 		Use: "kbb",
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			// flags of subcommands share keys in viper, e.g. db-dialect,
+			// rebind flags of the command running, so that they are not shadowed by other commands.
+			magicconch.Must(viper.BindPFlags(cmd.Flags()))
+		},
 	}
 
 	cmd.AddCommand(
 		newControllerCommand(),
+		newDBCommand(),
+		newHistoryCommand(),
 		newQueryCommand(),
 		newServeCommand(),
+		newSnapshotCommand(),
 		newWatchCommand(),
 	)
 
@@ -6,7 +6,6 @@ import (
 	spgc "github.com/spongeprojects/client-go/client/clientset/versioned"
 	spgi "github.com/spongeprojects/client-go/client/informers/externalversions"
 	spgl "github.com/spongeprojects/client-go/client/listers/spongeprojects.com/v1alpha1"
-	"github.com/spongeprojects/kubebigbrother/pkg/gormdb"
 	"github.com/spongeprojects/kubebigbrother/pkg/stores/event_store"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
@@ -22,6 +21,10 @@ type Config struct {
 	DBArgs     string
 	GinDebug   bool
 	Kubeconfig string
+
+	// EventStore serves events of a store opened already, e.g. by the controller,
+	// DBDialect and DBArgs are ignored when set
+	EventStore event_store.Interface
 }
 
 type App struct {
@@ -56,13 +59,15 @@ func SetupApp(config *Config) (*App, error) {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	db, err := gormdb.New(config.DBDialect, config.DBArgs)
-	if err != nil {
-		return nil, errors.Wrap(err, "create db instance error")
+	app.EventStore = config.EventStore
+	if app.EventStore == nil {
+		eventStore, err := event_store.Open(config.DBDialect, config.DBArgs, event_store.Options{ReadOnly: true})
+		if err != nil {
+			return nil, errors.Wrap(err, "create event store error")
+		}
+		app.EventStore = eventStore
 	}
 
-	app.EventStore = event_store.New(db)
-
 	restConfig, err := clientcmd.BuildConfigFromFlags("", config.Kubeconfig)
 	if err != nil {
 		return nil, errors.Wrap(err, "get kube config error")
@@ -103,6 +108,10 @@ func SetupApp(config *Config) (*App, error) {
 	r.GET("/api/v1/config", app.HandlerConfig)
 	r.GET("/api/v1/events", app.HandlerEventList)
 	r.GET("/api/v1/events/:id", app.HandlerEvent)
+	r.GET("/api/v1/events/:id/related", app.HandlerEventRelated)
+	r.GET("/api/v1/snapshot", app.HandlerSnapshot)
+	r.GET("/api/v1/snapshot/diff", app.HandlerSnapshotDiff)
+	r.GET("/api/v1/objects/:group/:resource/:namespace/:name/history", app.HandlerObjectHistory)
 
 	r.HandleMethodNotAllowed = true
 
@@ -111,7 +120,7 @@ func SetupApp(config *Config) (*App, error) {
 	return app, nil
 }
 
-func (app *App) Run(stopCh chan struct{}) error {
+func (app *App) Run(stopCh <-chan struct{}) error {
 	go app.ChannelInformer.Run(stopCh)
 	go app.WatcherInformer.Run(stopCh)
 	go app.ClusterWatcherInformer.Run(stopCh)
@@ -7,38 +7,89 @@ import (
 	"github.com/spongeprojects/kubebigbrother/pkg/stores/event_store"
 	"github.com/spongeprojects/magicconch"
 	"gopkg.in/yaml.v3"
+	"strings"
+	"time"
 )
 
-// HandlerEventList queries events
+// EventListQuery is the query of HandlerEventList,
+// namespace and name identify the watcher, object_namespace and object_name identify the object.
+type EventListQuery struct {
+	Cluster         string    `form:"cluster"`
+	Namespace       string    `form:"namespace"`
+	Name            string    `form:"name"`
+	Q               string    `form:"q"`
+	After           uint      `form:"after"`
+	Owner           string    `form:"owner"`
+	Types           []string  `form:"type"`
+	Kind            string    `form:"kind"`
+	Resource        string    `form:"resource"`
+	ObjectNamespace string    `form:"object_namespace"`
+	ObjectName      string    `form:"object_name"`
+	UID             string    `form:"uid"`
+	Since           time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Until           time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00"`
+	Selector        string    `form:"selector"`
+	Content         string    `form:"content"`
+	Limit           int       `form:"limit"`
+	Cursor          string    `form:"cursor"`
+	Total           *bool     `form:"total"`
+}
+
+// HandlerEventList queries events, latest first, with cursors of adjacent pages,
+// events are counted unless total=false.
 func (app *App) HandlerEventList(c *gin.Context) {
-	namespace := c.Query("namespace")
-	name := c.Query("name")
+	var query EventListQuery
+	if !app.MustBindQuery(c, &query) {
+		return
+	}
+
 	var informerName string
-	if namespace != "" {
-		informerName = models.WatcherInformerName(namespace, name)
-	} else if name != "" {
-		informerName = models.ClusterWatcherInformerName(name)
+	if query.Namespace != "" {
+		informerName = models.WatcherInformerName(query.Namespace, query.Name)
+	} else if query.Name != "" {
+		informerName = models.ClusterWatcherInformerName(query.Name)
 	}
-	events, err := app.EventStore.List(event_store.ListOptions{
-		InformerName: informerName,
-		Q:            c.Query("q"),
-		After:        magicconch.StringToUint(c.Query("after")),
-	})
-	if err != nil {
-		app.handle(c, errors.Wrap(err, "list events error"))
+	var eventTypes []string
+	for _, t := range query.Types {
+		for _, eventType := range strings.Split(t, ",") {
+			if eventType = strings.ToUpper(strings.TrimSpace(eventType)); eventType != "" {
+				eventTypes = append(eventTypes, eventType)
+			}
+		}
+	}
+	options := event_store.ListOptions{
+		Cluster:       query.Cluster,
+		InformerName:  informerName,
+		Q:             query.Q,
+		After:         query.After,
+		Owner:         query.Owner,
+		EventTypes:    eventTypes,
+		Kind:          query.Kind,
+		GroupResource: query.Resource,
+		Namespace:     query.ObjectNamespace,
+		Name:          query.ObjectName,
+		UID:           query.UID,
+		Since:         query.Since,
+		Until:         query.Until,
+		LabelSelector: query.Selector,
+		Content:       query.Content,
+		Limit:         query.Limit,
+		Cursor:        query.Cursor,
+		CountTotal:    query.Total == nil || *query.Total,
+		OmitObjects:   true,
+	}
+	if err := options.Validate(); err != nil {
+		app.handle(c, e(400, ReasonInvalidRequest, err.Error()))
 		return
 	}
 
-	for i := range events {
-		evt := events[i]
-		evt.Obj = nil
-		evt.OldObj = nil
-		events[i] = evt
+	page, err := app.EventStore.ListPage(options)
+	if err != nil {
+		app.handle(c, errors.Wrap(err, "list events error"))
+		return
 	}
 
-	c.JSON(200, gin.H{
-		"events": events,
-	})
+	c.JSON(200, page)
 	return
 }
 
@@ -50,8 +101,16 @@ func (app *App) HandlerEvent(c *gin.Context) {
 		return
 	}
 
-	obj := event.GetObj()
-	oldObj := event.GetOldObj()
+	obj, err := event.GetObj()
+	if err != nil {
+		app.handle(c, errors.Wrap(err, "get object error"))
+		return
+	}
+	oldObj, err := event.GetOldObj()
+	if err != nil {
+		app.handle(c, errors.Wrap(err, "get old object error"))
+		return
+	}
 
 	var objYamlBytes, oldObjYamlBytes []byte
 
@@ -86,3 +145,24 @@ func (app *App) HandlerEvent(c *gin.Context) {
 	})
 	return
 }
+
+// HandlerEventRelated lists events related to the event,
+// Kubernetes Events recorded in events mode are linked to events of their involved objects.
+func (app *App) HandlerEventRelated(c *gin.Context) {
+	event, err := app.EventStore.Find(magicconch.StringToUint(c.Param("id")))
+	if err != nil {
+		app.handle(c, errors.Wrap(err, "find events error"))
+		return
+	}
+
+	events, err := app.EventStore.ListRelated(event)
+	if err != nil {
+		app.handle(c, errors.Wrap(err, "list related events error"))
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"events": events,
+	})
+	return
+}
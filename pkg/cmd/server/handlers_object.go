@@ -0,0 +1,61 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/spongeprojects/kubebigbrother/pkg/stores/event_store"
+)
+
+const (
+	// CoreGroup is the group of core resources in paths, e.g. pods, as the group is empty
+	CoreGroup = "core"
+
+	// NoNamespace is the namespace of cluster-scoped objects in paths, e.g. nodes
+	NoNamespace = "_"
+)
+
+// ObjectHistoryQuery is the query of HandlerObjectHistory
+type ObjectHistoryQuery struct {
+	Cluster string `form:"cluster"`
+	Limit   int    `form:"limit"`
+}
+
+// HandlerObjectHistory lists versions of an object across informers, oldest first,
+// with diffs from previous versions, the group is "core" for core resources,
+// the namespace is "_" for cluster-scoped objects.
+func (app *App) HandlerObjectHistory(c *gin.Context) {
+	var query ObjectHistoryQuery
+	if !app.MustBindQuery(c, &query) {
+		return
+	}
+
+	options := event_store.HistoryOptions{
+		Cluster:   query.Cluster,
+		Group:     c.Param("group"),
+		Resource:  c.Param("resource"),
+		Namespace: c.Param("namespace"),
+		Name:      c.Param("name"),
+		Limit:     query.Limit,
+	}
+	if options.Group == CoreGroup {
+		options.Group = ""
+	}
+	if options.Namespace == NoNamespace {
+		options.Namespace = ""
+	}
+	if err := options.Validate(); err != nil {
+		app.handle(c, e(400, ReasonInvalidRequest, err.Error()))
+		return
+	}
+
+	versions, err := app.EventStore.History(options)
+	if err != nil {
+		app.handle(c, errors.Wrap(err, "list history error"))
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"versions": versions,
+	})
+	return
+}
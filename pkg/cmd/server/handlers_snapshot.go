@@ -0,0 +1,120 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	"github.com/spongeprojects/kubebigbrother/pkg/stores/event_store"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"time"
+)
+
+// SnapshotQuery is the query of HandlerSnapshot and HandlerSnapshotDiff,
+// namespace and name identify the watcher, object_namespace is the namespace of objects.
+type SnapshotQuery struct {
+	Cluster         string    `form:"cluster"`
+	Namespace       string    `form:"namespace"`
+	Name            string    `form:"name"`
+	ObjectNamespace string    `form:"object_namespace"`
+	At              time.Time `form:"at" time_format:"2006-01-02T15:04:05Z07:00"`
+	From            time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To              time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+func (q *SnapshotQuery) options() event_store.SnapshotOptions {
+	var informerName string
+	if q.Namespace != "" {
+		informerName = models.WatcherInformerName(q.Namespace, q.Name)
+	} else if q.Name != "" {
+		informerName = models.ClusterWatcherInformerName(q.Name)
+	}
+	return event_store.SnapshotOptions{
+		Cluster:      q.Cluster,
+		InformerName: informerName,
+		Namespace:    q.ObjectNamespace,
+	}
+}
+
+// SnapshotObject is an object in a snapshot, with the event recording its state
+type SnapshotObject struct {
+	Event  models.Event               `json:"event"`
+	Object *unstructured.Unstructured `json:"object"`
+}
+
+// HandlerSnapshot reconstructs objects existing at the time, now when at is not set
+func (app *App) HandlerSnapshot(c *gin.Context) {
+	var query SnapshotQuery
+	if !app.MustBindQuery(c, &query) {
+		return
+	}
+	options := query.options()
+	if err := options.Validate(); err != nil {
+		app.handle(c, e(400, ReasonInvalidRequest, err.Error()))
+		return
+	}
+	at := query.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	events, err := app.EventStore.Snapshot(options, at)
+	if err != nil {
+		app.handle(c, errors.Wrap(err, "snapshot error"))
+		return
+	}
+
+	objects := make([]SnapshotObject, 0, len(events))
+	for _, event := range events {
+		obj, err := event.GetObj()
+		if err != nil {
+			app.handle(c, errors.Wrap(err, "get object error"))
+			return
+		}
+		event.Obj = nil
+		event.OldObj = nil
+		objects = append(objects, SnapshotObject{Event: event, Object: obj})
+	}
+
+	c.JSON(200, gin.H{
+		"at":      at,
+		"objects": objects,
+	})
+	return
+}
+
+// HandlerSnapshotDiff lists objects added, removed and changed between snapshots,
+// from is required, to is now when not set.
+func (app *App) HandlerSnapshotDiff(c *gin.Context) {
+	var query SnapshotQuery
+	if !app.MustBindQuery(c, &query) {
+		return
+	}
+	options := query.options()
+	if err := options.Validate(); err != nil {
+		app.handle(c, e(400, ReasonInvalidRequest, err.Error()))
+		return
+	}
+	if query.From.IsZero() {
+		app.handle(c, e(400, ReasonInvalidRequest, "from is required"))
+		return
+	}
+	to := query.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+
+	d, err := app.EventStore.DiffSnapshots(options, query.From, to)
+	if err != nil {
+		app.handle(c, errors.Wrap(err, "diff snapshots error"))
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"from":    query.From,
+		"to":      to,
+		"added":   d.Added,
+		"removed": d.Removed,
+		"changed": d.Changed,
+	})
+	return
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/spongeprojects/kubebigbrother/pkg/cmd/genericoptions"
+	"github.com/spongeprojects/kubebigbrother/pkg/helpers/style"
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	"github.com/spongeprojects/kubebigbrother/pkg/stores/event_store"
+	"github.com/spongeprojects/magicconch"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+	"time"
+)
+
+type snapshotOptions struct {
+	GlobalOptions   *genericoptions.GlobalOptions
+	DatabaseOptions *genericoptions.DatabaseOptions
+
+	Cluster   string
+	Informer  string
+	Namespace string
+	At        string
+	From      string
+}
+
+func getSnapshotOptions() *snapshotOptions {
+	o := &snapshotOptions{
+		GlobalOptions:   genericoptions.GetGlobalOptions(),
+		DatabaseOptions: genericoptions.GetDatabaseOptions(),
+		Cluster:         viper.GetString("cluster"),
+		Informer:        viper.GetString("informer"),
+		Namespace:       viper.GetString("namespace"),
+		At:              viper.GetString("at"),
+		From:            viper.GetString("from"),
+	}
+	return o
+}
+
+func newSnapshotCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Dump objects existing at a time as multi-document YAML, or changes since another time with --from",
+		Example: "  kbb snapshot -n prod --at 2021-06-01T14:32:00+08:00 > prod.yaml\n" +
+			"  kbb snapshot -n prod --from 2h --at 1h",
+		Run: func(cmd *cobra.Command, args []string) {
+			o := getSnapshotOptions()
+			options := event_store.SnapshotOptions{
+				Cluster:      o.Cluster,
+				InformerName: o.Informer,
+				Namespace:    o.Namespace,
+			}
+			if err := options.Validate(); err != nil {
+				klog.Exit(errors.Wrap(err, "invalid options"))
+			}
+			now := time.Now()
+			at, err := parseTime(o.At, now)
+			if err != nil {
+				klog.Exit(errors.Wrap(err, "invalid options"))
+			}
+			if at.IsZero() {
+				at = now
+			}
+			from, err := parseTime(o.From, now)
+			if err != nil {
+				klog.Exit(errors.Wrap(err, "invalid options"))
+			}
+
+			store, err := event_store.Open(o.DatabaseOptions.DBDialect, o.DatabaseOptions.DBArgs,
+				event_store.Options{ReadOnly: true})
+			if err != nil {
+				klog.Exit(errors.Wrap(err, "connect to db error"))
+			}
+
+			if !from.IsZero() {
+				d, err := store.DiffSnapshots(options, from, at)
+				if err != nil {
+					klog.Exit(errors.Wrap(err, "diff snapshots error"))
+				}
+				printSnapshotDiff(d)
+				return
+			}
+
+			events, err := store.Snapshot(options, at)
+			if err != nil {
+				klog.Exit(errors.Wrap(err, "snapshot error"))
+			}
+			if err := printSnapshot(events); err != nil {
+				klog.Exit(err)
+			}
+		},
+	}
+
+	f := cmd.PersistentFlags()
+	f.String("cluster", "", "cluster of objects")
+	f.String("informer", "", "informer of objects, e.g. watcher-<namespace>-<name>, clusterwatcher-<name>")
+	f.StringP("namespace", "n", "", "namespace of objects")
+	f.String("at", "", "time of the snapshot, RFC3339 time or duration ago, e.g. 2h, now when empty")
+	f.String("from", "", "print changes from this time to --at instead, RFC3339 time or duration ago")
+	genericoptions.AddDatabaseFlags(f)
+	magicconch.Must(viper.BindPFlags(f))
+
+	return cmd
+}
+
+// printSnapshot prints objects as multi-document YAML
+func printSnapshot(events []models.Event) error {
+	for _, e := range events {
+		obj, err := e.GetObj()
+		if err != nil {
+			return err
+		}
+		if obj == nil {
+			continue
+		}
+		b, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return errors.Wrap(err, "yaml marshal error")
+		}
+		fmt.Printf("---\n%s", b)
+	}
+	return nil
+}
+
+func printSnapshotDiff(d *event_store.SnapshotDiff) {
+	if len(d.Added)+len(d.Removed)+len(d.Changed) == 0 {
+		fmt.Println("nothing")
+		return
+	}
+	for _, e := range d.Added {
+		fmt.Println(style.Fg(style.Success, "added: %s %s/%s", e.Kind, e.Namespace, e.Name))
+	}
+	for _, e := range d.Removed {
+		fmt.Println(style.Fg(style.Danger, "removed: %s %s/%s", e.Kind, e.Namespace, e.Name))
+	}
+	for _, c := range d.Changed {
+		fmt.Println(style.Fg(style.Warning, "changed: %s %s/%s", c.To.Kind, c.To.Namespace, c.To.Name))
+		printDiff(c.Diff)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/spongeprojects/kubebigbrother/pkg/channels"
 	"github.com/spongeprojects/kubebigbrother/pkg/cmd/genericoptions"
 	"github.com/spongeprojects/kubebigbrother/pkg/cmd/watcher"
 	"github.com/spongeprojects/kubebigbrother/pkg/helpers/style"
@@ -16,32 +17,66 @@ import (
 type watchOptions struct {
 	GlobalOptions     *genericoptions.GlobalOptions
 	KubeconfigOptions *genericoptions.KubeconfigOptions
+
+	Namespace string
+	Events    []string
+	UpdateOn  []string
+	Selector  string
+	Output    string
+	Template  string
+	Diff      bool
 }
 
 func getWatchOptions() *watchOptions {
 	o := &watchOptions{
 		GlobalOptions:     genericoptions.GetGlobalOptions(),
 		KubeconfigOptions: genericoptions.GetKubeconfigOptions(),
+		Namespace:         viper.GetString("namespace"),
+		Events:            viper.GetStringSlice("events"),
+		UpdateOn:          viper.GetStringSlice("update-on"),
+		Selector:          viper.GetString("selector"),
+		Output:            viper.GetString("output"),
+		Template:          viper.GetString("template"),
+		Diff:              viper.GetBool("diff"),
 	}
 	return o
 }
 
 func newWatchCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "watch",
+		Use:   "watch [resource]",
 		Short: "Watch events lively",
+		Long: "Watch events lively.\n\n" +
+			"Without resource, watchers in Kubernetes are used, e.g.:\n" +
+			"  kbb watch\n\n" +
+			"With resource, it's watched directly, no watcher is needed in Kubernetes, e.g.:\n" +
+			"  kbb watch deployments.apps -n prod --events=updated --update-on=.spec.replicas -l app=foo --diff",
+		Args: cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Println(style.Fg(style.Warning, ""+
-				"--------------------------------------------------\n"+
-				"|  Watch should only be used for debugging.      |\n"+
-				"|  In watch mode, all channels will be replaced  |\n"+
-				"|  by a single \"print to stdout\" channel.        |\n"+
-				"--------------------------------------------------"))
-
 			o := getWatchOptions()
 
+			var resource string
+			if len(args) > 0 {
+				resource = args[0]
+			} else {
+				fmt.Println(style.Fg(style.Warning, ""+
+					"--------------------------------------------------\n"+
+					"|  Watch should only be used for debugging.      |\n"+
+					"|  In watch mode, all channels will be replaced  |\n"+
+					"|  by a single \"print to stdout\" channel.        |\n"+
+					"--------------------------------------------------"))
+			}
+
 			w, err := watcher.Setup(watcher.Config{
 				Kubeconfig: o.KubeconfigOptions.Kubeconfig,
+				Resource:   resource,
+				Namespace:  o.Namespace,
+				Events:     o.Events,
+				UpdateOn:   o.UpdateOn,
+				Selector:   o.Selector,
+				Output:     o.Output,
+				Template:   o.Template,
+				Diff:       o.Diff,
 			})
 			if err != nil {
 				klog.Exit(errors.Wrap(err, "setup watcher error"))
@@ -59,6 +94,13 @@ func newWatchCommand() *cobra.Command {
 	}
 
 	f := cmd.PersistentFlags()
+	f.StringP("namespace", "n", "", "namespace of resource to watch, all namespaces are watched when empty")
+	f.StringSlice("events", []string{"added", "updated", "deleted"}, "types of events of resource to print: added|updated|deleted")
+	f.StringSlice("update-on", nil, "fields of resource to watch for updates, e.g. .spec.replicas, all fields are watched when empty")
+	f.StringP("selector", "l", "", "label selector of resource to watch, e.g. app=foo")
+	f.StringP("output", "o", channels.PrintFormatJSON, "output format: json|yaml|template")
+	f.String("template", "", "template of output, used with --output=template, default templates are used when empty")
+	f.Bool("diff", false, "print diff of updated objects")
 	genericoptions.AddKubeconfigFlags(f)
 	magicconch.Must(viper.BindPFlags(f))
 
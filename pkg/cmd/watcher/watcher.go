@@ -2,11 +2,43 @@ package watcher
 
 import (
 	"github.com/pkg/errors"
+	spg "github.com/spongeprojects/client-go/api/spongeprojects.com/v1alpha1"
+	"github.com/spongeprojects/kubebigbrother/pkg/event"
 	"github.com/spongeprojects/kubebigbrother/pkg/informers"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"strings"
 )
 
+// adHocWatcherName is the name of the watcher created from command line flags
+const adHocWatcherName = "watch"
+
 type Config struct {
 	Kubeconfig string
+
+	// Resource is the resource to watch, watchers in Kubernetes are used when empty
+	Resource string
+
+	// Namespace to watch, all namespaces are watched when empty
+	Namespace string
+
+	// Events are types of events to print, e.g. ADDED, UPDATED, DELETED (case-insensitive)
+	Events []string
+
+	// UpdateOn are fields to watch for updates, all fields are watched when empty
+	UpdateOn []string
+
+	// Selector is a label selector, e.g. app=foo
+	Selector string
+
+	// Output is the output format, json, yaml or template
+	Output string
+
+	// Template overrides templates of all event types, only used when Output is template
+	Template string
+
+	// Diff prints diff of updated objects
+	Diff bool
 }
 
 type Watcher struct {
@@ -24,10 +56,35 @@ func (w *Watcher) Shutdown() {
 func Setup(config Config) (*Watcher, error) {
 	watcher := &Watcher{}
 
-	informerSet, err := informers.Setup(informers.Config{
+	if config.Template != "" && !strings.HasSuffix(config.Template, "\n") {
+		config.Template += "\n"
+	}
+
+	informersConfig := informers.Config{
 		Kubeconfig: config.Kubeconfig,
 		JustWatch:  true,
-	})
+		PrintConfig: &spg.ChannelPrintConfig{
+			Format:            config.Output,
+			Diff:              config.Diff,
+			AddedTemplate:     config.Template,
+			DeletedTemplate:   config.Template,
+			UpdatedTemplate:   config.Template,
+			TriggeredTemplate: config.Template,
+			ResolvedTemplate:  config.Template,
+			StuckTemplate:     config.Template,
+			OccurredTemplate:  config.Template,
+		},
+	}
+	if config.Resource != "" {
+		obj, err := buildWatcher(config)
+		if err != nil {
+			return nil, errors.Wrap(err, "build watcher error")
+		}
+		informersConfig.ConfigSource = informers.ConfigSourceStatic
+		informersConfig.StaticObjects = []runtime.Object{obj}
+	}
+
+	informerSet, err := informers.Setup(informersConfig)
 	if err != nil {
 		return nil, errors.Wrap(err, "setup informers error")
 	}
@@ -36,3 +93,35 @@ func Setup(config Config) (*Watcher, error) {
 
 	return watcher, nil
 }
+
+// buildWatcher builds a Watcher when namespace is set, otherwise a ClusterWatcher
+func buildWatcher(config Config) (runtime.Object, error) {
+	spec := spg.WatcherSpec{
+		Resource:      config.Resource,
+		UpdateOn:      config.UpdateOn,
+		LabelSelector: config.Selector,
+	}
+	for _, t := range config.Events {
+		switch event.Type(strings.ToUpper(strings.TrimSpace(t))) {
+		case event.TypeAdded:
+			spec.NoticeWhenAdded = true
+		case event.TypeUpdated:
+			spec.NoticeWhenUpdated = true
+		case event.TypeDeleted:
+			spec.NoticeWhenDeleted = true
+		default:
+			return nil, errors.Errorf("unsupported event type: %s", t)
+		}
+	}
+
+	if config.Namespace == "" {
+		return &spg.ClusterWatcher{
+			ObjectMeta: metav1.ObjectMeta{Name: adHocWatcherName},
+			Spec:       spec,
+		}, nil
+	}
+	return &spg.Watcher{
+		ObjectMeta: metav1.ObjectMeta{Name: adHocWatcherName, Namespace: config.Namespace},
+		Spec:       spec,
+	}, nil
+}
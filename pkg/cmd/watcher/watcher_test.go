@@ -0,0 +1,45 @@
+package watcher
+
+import (
+	spg "github.com/spongeprojects/client-go/api/spongeprojects.com/v1alpha1"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestBuildWatcher(t *testing.T) {
+	assertions := require.New(t)
+
+	obj, err := buildWatcher(Config{
+		Resource:  "deployments.apps",
+		Namespace: "prod",
+		Events:    []string{"updated"},
+		UpdateOn:  []string{".spec.replicas"},
+		Selector:  "app=foo",
+	})
+	assertions.Nil(err)
+	watcher, ok := obj.(*spg.Watcher)
+	assertions.True(ok)
+	assertions.Equal("prod", watcher.Namespace)
+	assertions.Equal("deployments.apps", watcher.Spec.Resource)
+	assertions.False(watcher.Spec.NoticeWhenAdded)
+	assertions.True(watcher.Spec.NoticeWhenUpdated)
+	assertions.False(watcher.Spec.NoticeWhenDeleted)
+	assertions.Equal([]string{".spec.replicas"}, watcher.Spec.UpdateOn)
+	assertions.Equal("app=foo", watcher.Spec.LabelSelector)
+
+	obj, err = buildWatcher(Config{
+		Resource: "pods",
+		Events:   []string{"ADDED", "deleted"},
+	})
+	assertions.Nil(err)
+	clusterWatcher, ok := obj.(*spg.ClusterWatcher)
+	assertions.True(ok)
+	assertions.True(clusterWatcher.Spec.NoticeWhenAdded)
+	assertions.True(clusterWatcher.Spec.NoticeWhenDeleted)
+
+	_, err = buildWatcher(Config{
+		Resource: "pods",
+		Events:   []string{"created"},
+	})
+	assertions.NotNil(err)
+}
@@ -0,0 +1,30 @@
+package event
+
+import (
+	"strings"
+)
+
+// Actor is who made the change, from audit logs,
+// only Manager is set when no audit record is matched.
+type Actor struct {
+	Username  string   `json:"username,omitempty"`
+	Groups    []string `json:"groups,omitempty"`
+	UserAgent string   `json:"userAgent,omitempty"`
+	SourceIPs []string `json:"sourceIPs,omitempty"`
+
+	// Manager is the field manager of the latest change in metadata.managedFields, e.g. kubectl-edit
+	Manager string `json:"manager,omitempty"`
+}
+
+// String returns username, or manager when username is unknown
+func (a *Actor) String() string {
+	if a.Username != "" {
+		return a.Username
+	}
+	return a.Manager
+}
+
+// joinList joins list as comma separated string
+func joinList(list []string) string {
+	return strings.Join(list, ",")
+}
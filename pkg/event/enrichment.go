@@ -0,0 +1,44 @@
+package event
+
+// Enrichment is context attached to notifications, collected when enrichment is enabled,
+// it's not recorded in the database.
+type Enrichment struct {
+	// WarningEvents are recent Warning Events of the object, latest first
+	WarningEvents []KubeEvent `json:"warningEvents,omitempty"`
+
+	// Containers are statuses of containers of a Pod, init containers first
+	Containers []ContainerStatus `json:"containers,omitempty"`
+
+	// Logs are tails of logs of crashed containers of a Pod
+	Logs []ContainerLog `json:"logs,omitempty"`
+}
+
+// ContainerStatus is the essential part of a container status
+type ContainerStatus struct {
+	Name         string `json:"name"`
+	Ready        bool   `json:"ready"`
+	RestartCount int32  `json:"restartCount"`
+
+	// State is the current state, Waiting, Running or Terminated,
+	// Reason is the reason of the state, e.g. CrashLoopBackOff
+	State  string `json:"state"`
+	Reason string `json:"reason,omitempty"`
+
+	// LastTerminationReason and LastTerminationExitCode are set when the container has terminated before,
+	// e.g. OOMKilled, 137
+	LastTerminationReason   string `json:"lastTerminationReason,omitempty"`
+	LastTerminationExitCode int32  `json:"lastTerminationExitCode,omitempty"`
+}
+
+// ContainerLog is tail of logs of a container
+type ContainerLog struct {
+	Container string `json:"container"`
+
+	// Previous is true when the logs are of the previous terminated instance of the container
+	Previous bool `json:"previous,omitempty"`
+
+	Tail string `json:"tail"`
+
+	// Truncated is true when the tail is cut by the size limit
+	Truncated bool `json:"truncated,omitempty"`
+}
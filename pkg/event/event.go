@@ -3,18 +3,26 @@ package event
 import (
 	"github.com/spongeprojects/kubebigbrother/pkg/helpers/style"
 	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	"github.com/spongeprojects/kubebigbrother/pkg/redact"
 	"github.com/spongeprojects/kubebigbrother/pkg/utils"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"time"
 )
 
 // Type of event
 type Type string
 
 const (
-	TypeAdded   = "ADDED"   // resource created
-	TypeDeleted = "DELETED" // resource deleted
-	TypeUpdated = "UPDATED" // resource updated
+	TypeAdded   = models.EventTypeAdded   // resource created
+	TypeDeleted = models.EventTypeDeleted // resource deleted
+	TypeUpdated = models.EventTypeUpdated // resource updated
+
+	TypeTriggered = models.EventTypeTriggered // trigger expression becomes true
+	TypeResolved  = models.EventTypeResolved  // trigger expression becomes false again
+	TypeStuck     = models.EventTypeStuck     // timer expression holds for the duration
+
+	TypeOccurred = models.EventTypeOccurred // Kubernetes Event occurred, in events mode
 )
 
 // Event is representation of Kubernetes event
@@ -22,12 +30,45 @@ type Event struct {
 	// Type is the type of the event
 	Type Type `json:"type"`
 
+	// Cluster is the name of the cluster where the event happened,
+	// empty when not watching multiple clusters
+	Cluster string `json:"cluster,omitempty"`
+
 	// Obj is the resource affected
 	Obj *unstructured.Unstructured `json:"obj"`
 
 	// OldObj is only set for EventTypeUpdated
 	OldObj *unstructured.Unstructured `json:"oldObj,omitempty"`
 
+	// UpdatedFields is only set for EventTypeUpdated,
+	// it's the paths of changed fields matching UpdateOn, e.g. .spec.replicas
+	UpdatedFields []string `json:"updatedFields,omitempty"`
+
+	// TriggerExpression is only set for EventTypeTriggered, EventTypeResolved and EventTypeStuck
+	TriggerExpression string `json:"triggerExpression,omitempty"`
+
+	// TimerName and StuckFor are only set for EventTypeStuck
+	TimerName string `json:"timerName,omitempty"`
+	StuckFor  string `json:"stuckFor,omitempty"`
+
+	// FinalStateUnknown is only set for EventTypeDeleted,
+	// it means the deletion was missed by the watch (e.g. during a disconnection),
+	// Obj is the last known state of the resource and may be stale.
+	FinalStateUnknown bool `json:"finalStateUnknown,omitempty"`
+
+	// KubeEvent is only set for EventTypeOccurred, Obj is the Kubernetes Event
+	KubeEvent *KubeEvent `json:"kubeEvent,omitempty"`
+
+	// Owners is the controller owner chain of the affected resource, nearest first,
+	// e.g. ReplicaSet, Deployment of a Pod, empty when not resolved
+	Owners []Owner `json:"owners,omitempty"`
+
+	// Actor is who made the change, nil when unknown
+	Actor *Actor `json:"actor,omitempty"`
+
+	// Enrichment is only set when enrichment is enabled, and only for events to notify
+	Enrichment *Enrichment `json:"enrichment,omitempty"`
+
 	// gvkNameCache is a cache for GroupVersionKindName
 	gvkNameCache string
 }
@@ -50,6 +91,15 @@ func (e *Event) NamespaceKey() string {
 	return utils.NamespaceKey(e.Obj)
 }
 
+// RootOwner returns the outermost owner resolved, e.g. Deployment of a Pod,
+// nil when the resource has no owner
+func (e *Event) RootOwner() *Owner {
+	if len(e.Owners) == 0 {
+		return nil
+	}
+	return &e.Owners[len(e.Owners)-1]
+}
+
 // Color returns theme color for the type of the event
 func (e *Event) Color() string {
 	switch e.Type {
@@ -57,35 +107,78 @@ func (e *Event) Color() string {
 		return style.Success
 	case TypeDeleted:
 		return style.Warning
+	case TypeTriggered, TypeStuck:
+		return style.Danger
+	case TypeResolved:
+		return style.Success
+	case TypeOccurred:
+		if e.KubeEvent != nil && e.KubeEvent.Type == "Warning" {
+			return style.Warning
+		}
+		return style.Info
 	default:
 		return style.Info
 	}
 }
 
-// ToModel translate Event into *models.Event
+// Redact replaces Obj and OldObj with redacted copies
+func (e *Event) Redact(r *redact.Redactor) {
+	e.Obj = r.Redact(e.Obj)
+	e.OldObj = r.Redact(e.OldObj)
+}
+
+// ToModel translate Event into *models.Event,
+// Secrets are always redacted before they are stored.
 func (e *Event) ToModel(
 	informerName string, gvr schema.GroupVersionResource) *models.Event {
+	obj := redact.Default.Redact(e.Obj)
+	oldObj := redact.Default.Redact(e.OldObj)
+
 	model := &models.Event{
+		Cluster:      e.Cluster,
 		InformerName: informerName,
 		EventType:    string(e.Type),
-		Namespace:    e.Obj.GetNamespace(),
-		Name:         e.Obj.GetName(),
+		Namespace:    obj.GetNamespace(),
+		Name:         obj.GetName(),
+
+		UID:             string(obj.GetUID()),
+		ResourceVersion: obj.GetResourceVersion(),
+
+		FinalStateUnknown: e.FinalStateUnknown,
+
+		OwnerChain: OwnerChain(e.Owners),
+		Labels:     models.FormatLabels(obj.GetLabels()),
 	}
 
 	model.Group = gvr.Group
 	model.Version = gvr.Version
 	model.Resource = gvr.Resource
-	gvk := e.Obj.GroupVersionKind()
+	gvk := obj.GroupVersionKind()
 	model.Kind = gvk.Kind
 
-	objJSON, _ := e.Obj.MarshalJSON()
+	objJSON, _ := obj.MarshalJSON()
 	model.Obj = objJSON
 
-	if e.OldObj != nil {
-		oldObjJSON, _ := e.OldObj.MarshalJSON()
+	if oldObj != nil {
+		oldObjJSON, _ := oldObj.MarshalJSON()
 		model.OldObj = oldObjJSON
 	}
 
+	if e.Actor != nil {
+		model.Username = e.Actor.Username
+		model.UserGroups = joinList(e.Actor.Groups)
+		model.UserAgent = e.Actor.UserAgent
+		model.SourceIPs = joinList(e.Actor.SourceIPs)
+		model.Manager = e.Actor.Manager
+	}
+
+	if e.KubeEvent != nil {
+		model.InvolvedKind = e.KubeEvent.InvolvedObject.Kind
+		model.InvolvedNamespace = e.KubeEvent.InvolvedObject.Namespace
+		model.InvolvedName = e.KubeEvent.InvolvedObject.Name
+		model.InvolvedUID = e.KubeEvent.InvolvedObject.UID
+	}
+
 	return model
 }
 
@@ -105,6 +198,55 @@ func NewDeleted(obj *unstructured.Unstructured) *Event {
 	}
 }
 
+// NewDeletedFinalStateUnknown creates deleted event from a tombstone,
+// obj is the last known state of the resource
+func NewDeletedFinalStateUnknown(obj *unstructured.Unstructured) *Event {
+	return &Event{
+		Type:              TypeDeleted,
+		Obj:               obj,
+		FinalStateUnknown: true,
+	}
+}
+
+// NewTriggered creates triggered event
+func NewTriggered(obj *unstructured.Unstructured, expression string) *Event {
+	return &Event{
+		Type:              TypeTriggered,
+		Obj:               obj,
+		TriggerExpression: expression,
+	}
+}
+
+// NewResolved creates resolved event
+func NewResolved(obj *unstructured.Unstructured, expression string) *Event {
+	return &Event{
+		Type:              TypeResolved,
+		Obj:               obj,
+		TriggerExpression: expression,
+	}
+}
+
+// NewStuck creates stuck event
+func NewStuck(obj *unstructured.Unstructured,
+	timerName, expression string, stuckFor time.Duration) *Event {
+	return &Event{
+		Type:              TypeStuck,
+		Obj:               obj,
+		TriggerExpression: expression,
+		TimerName:         timerName,
+		StuckFor:          stuckFor.String(),
+	}
+}
+
+// NewOccurred creates occurred event, obj is the Kubernetes Event
+func NewOccurred(obj *unstructured.Unstructured, kubeEvent *KubeEvent) *Event {
+	return &Event{
+		Type:      TypeOccurred,
+		Obj:       obj,
+		KubeEvent: kubeEvent,
+	}
+}
+
 // NewUpdated creates updated event
 func NewUpdated(obj, oldObj *unstructured.Unstructured) *Event {
 	return &Event{
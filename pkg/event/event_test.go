@@ -3,6 +3,8 @@ package event
 import (
 	"github.com/spongeprojects/kubebigbrother/pkg/helpers/style"
 	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"testing"
 )
 
@@ -15,3 +17,23 @@ func TestEvent_Color(t *testing.T) {
 
 	assertions.Equal(style.Green, e.Color())
 }
+
+func TestEvent_ToModelRedactsSecrets(t *testing.T) {
+	assertions := require.New(t)
+
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"namespace": "demo",
+			"name":      "canary",
+		},
+		"data": map[string]interface{}{
+			"password": "cGFzc3dvcmQ=",
+		},
+	}}
+	model := NewAdded(secret).ToModel("test", schema.GroupVersionResource{Version: "v1", Resource: "secrets"})
+	assertions.NotContains(string(model.Obj), "cGFzc3dvcmQ=")
+	assertions.Contains(string(model.Obj), "password")
+	assertions.Equal("cGFzc3dvcmQ=", secret.Object["data"].(map[string]interface{})["password"])
+}
@@ -0,0 +1,90 @@
+package event
+
+import (
+	"fmt"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// KubeEvent is the essential part of a Kubernetes Event,
+// both v1 and events.k8s.io/v1 Events are supported.
+type KubeEvent struct {
+	// Type is the type of the Event, Normal or Warning
+	Type string `json:"type"`
+
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+
+	// Count is the number of occurrences aggregated in the Event
+	Count int64 `json:"count"`
+
+	InvolvedObject InvolvedObject `json:"involvedObject"`
+}
+
+// InvolvedObject is the object the Event is about
+type InvolvedObject struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	UID       string `json:"uid,omitempty"`
+}
+
+// String returns kind, namespace and name of the object, e.g. Pod demo/nginx
+func (o InvolvedObject) String() string {
+	if o.Namespace == "" {
+		return fmt.Sprintf("%s %s", o.Kind, o.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", o.Kind, o.Namespace, o.Name)
+}
+
+// ParseKubeEvent parses obj as a Kubernetes Event, returns false if obj is not an Event
+func ParseKubeEvent(obj *unstructured.Unstructured) (*KubeEvent, bool) {
+	if obj.GetKind() != "Event" {
+		return nil, false
+	}
+	gv := obj.GroupVersionKind().GroupVersion()
+
+	str := func(path ...string) string {
+		s, _, _ := unstructured.NestedString(obj.Object, path...)
+		return s
+	}
+	count := func(path ...string) int64 {
+		i, _, _ := unstructured.NestedInt64(obj.Object, path...)
+		return i
+	}
+
+	e := &KubeEvent{
+		Type:   str("type"),
+		Reason: str("reason"),
+	}
+
+	switch gv.Group {
+	case "":
+		e.Message = str("message")
+		e.Count = count("count")
+		e.InvolvedObject = InvolvedObject{
+			Kind:      str("involvedObject", "kind"),
+			Namespace: str("involvedObject", "namespace"),
+			Name:      str("involvedObject", "name"),
+			UID:       str("involvedObject", "uid"),
+		}
+	case "events.k8s.io":
+		e.Message = str("note")
+		e.Count = count("series", "count")
+		if e.Count == 0 {
+			e.Count = count("deprecatedCount")
+		}
+		e.InvolvedObject = InvolvedObject{
+			Kind:      str("regarding", "kind"),
+			Namespace: str("regarding", "namespace"),
+			Name:      str("regarding", "name"),
+			UID:       str("regarding", "uid"),
+		}
+	default:
+		return nil, false
+	}
+
+	if e.Count < 1 {
+		e.Count = 1 // single occurrence events may have no count
+	}
+	return e, true
+}
@@ -0,0 +1,51 @@
+package event
+
+import (
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+)
+
+func TestParseKubeEvent(t *testing.T) {
+	assertions := require.New(t)
+
+	_, ok := ParseKubeEvent(&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+	}})
+	assertions.False(ok)
+
+	e, ok := ParseKubeEvent(&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "events.k8s.io/v1",
+		"kind":       "Event",
+		"type":       "Warning",
+		"reason":     "FailedScheduling",
+		"note":       "0/3 nodes are available",
+		"series": map[string]interface{}{
+			"count": int64(4),
+		},
+		"regarding": map[string]interface{}{
+			"kind":      "Pod",
+			"namespace": "demo",
+			"name":      "canary",
+		},
+	}})
+	assertions.True(ok)
+	assertions.Equal("0/3 nodes are available", e.Message)
+	assertions.Equal(int64(4), e.Count)
+	assertions.Equal("Pod demo/canary", e.InvolvedObject.String())
+
+	e, ok = ParseKubeEvent(&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Event",
+		"type":       "Normal",
+		"reason":     "Scheduled",
+		"involvedObject": map[string]interface{}{
+			"kind": "Node",
+			"name": "node-1",
+		},
+	}})
+	assertions.True(ok)
+	assertions.Equal(int64(1), e.Count)
+	assertions.Equal("Node node-1", e.InvolvedObject.String())
+}
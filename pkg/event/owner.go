@@ -0,0 +1,27 @@
+package event
+
+import (
+	"strings"
+)
+
+// Owner is an owner in the owner reference chain of the affected resource
+type Owner struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	UID        string `json:"uid"`
+}
+
+// String returns kind and name of the owner, e.g. Deployment/foo
+func (o Owner) String() string {
+	return o.Kind + "/" + o.Name
+}
+
+// OwnerChain joins owners as string, e.g. ReplicaSet/foo-5d4b7c,Deployment/foo
+func OwnerChain(owners []Owner) string {
+	chain := make([]string, 0, len(owners))
+	for _, owner := range owners {
+		chain = append(chain, owner.String())
+	}
+	return strings.Join(chain, ",")
+}
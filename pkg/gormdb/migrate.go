@@ -0,0 +1,127 @@
+package gormdb
+
+import (
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Migration is a versioned change of the schema
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// SchemaMigration is a migration applied, recorded in the schema version table
+type SchemaMigration struct {
+	Version   int `gorm:"primarykey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrationStatus is a migration and when it's applied, AppliedAt is nil when pending
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// LatestVersion is the version of the latest migration
+func LatestVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// applied returns migrations applied by version
+func applied(db *gorm.DB) (map[int]SchemaMigration, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, errors.Wrap(err, "create schema version table error")
+	}
+	var records []SchemaMigration
+	if err := db.Find(&records).Error; err != nil {
+		return nil, errors.Wrap(err, "list applied migrations error")
+	}
+	appliedMigrations := make(map[int]SchemaMigration, len(records))
+	for _, r := range records {
+		appliedMigrations[r.Version] = r
+	}
+	return appliedMigrations, nil
+}
+
+// MigrateUp applies pending migrations up to version, all of them when version is 0,
+// each migration is applied in a transaction.
+func MigrateUp(db *gorm.DB, version int) (migrated []Migration, err error) {
+	appliedMigrations, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range migrations {
+		if version > 0 && m.Version > version {
+			break
+		}
+		if _, ok := appliedMigrations[m.Version]; ok {
+			continue
+		}
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{
+				Version:   m.Version,
+				Name:      m.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		}); err != nil {
+			return migrated, errors.Wrapf(err, "apply migration %d error", m.Version)
+		}
+		migrated = append(migrated, m)
+	}
+	return migrated, nil
+}
+
+// MigrateDown reverts the latest steps migrations applied, latest first
+func MigrateDown(db *gorm.DB, steps int) (reverted []Migration, err error) {
+	appliedMigrations, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+		m := migrations[i]
+		if _, ok := appliedMigrations[m.Version]; !ok {
+			continue
+		}
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, m.Version).Error
+		}); err != nil {
+			return reverted, errors.Wrapf(err, "revert migration %d error", m.Version)
+		}
+		reverted = append(reverted, m)
+	}
+	return reverted, nil
+}
+
+// Status lists all migrations with when they're applied
+func Status(db *gorm.DB) ([]MigrationStatus, error) {
+	appliedMigrations, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status := MigrationStatus{Version: m.Version, Name: m.Name}
+		if r, ok := appliedMigrations[m.Version]; ok {
+			appliedAt := r.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
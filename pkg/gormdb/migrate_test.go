@@ -0,0 +1,94 @@
+package gormdb
+
+import (
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	assertions := require.New(t)
+
+	db, err := Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	assertions.Nil(err)
+
+	statuses, err := Status(db)
+	assertions.Nil(err)
+	assertions.Len(statuses, LatestVersion())
+	for _, s := range statuses {
+		assertions.Nil(s.AppliedAt)
+	}
+
+	migrated, err := MigrateUp(db, 1)
+	assertions.Nil(err)
+	assertions.Len(migrated, 1)
+	assertions.True(db.Migrator().HasTable("events"))
+	assertions.False(db.Migrator().HasColumn(&eventV2{}, "cluster"))
+
+	migrated, err = MigrateUp(db, 0)
+	assertions.Nil(err)
+	assertions.Len(migrated, LatestVersion()-1)
+	for _, index := range eventIndexes {
+		assertions.True(db.Migrator().HasIndex(&eventV2{}, index.Name), index.Name)
+	}
+	assertions.Nil(db.Create(&models.Event{Name: "a", Cluster: "c1", Codec: models.CodecGzip}).Error)
+
+	migrated, err = MigrateUp(db, 0)
+	assertions.Nil(err)
+	assertions.Empty(migrated)
+
+	assertions.True(db.Migrator().HasTable("event_contents"))
+
+	reverted, err := MigrateDown(db, 2)
+	assertions.Nil(err)
+	assertions.Len(reverted, 2)
+	assertions.Equal(LatestVersion(), reverted[0].Version)
+	assertions.False(db.Migrator().HasTable("event_contents"))
+	assertions.False(db.Migrator().HasColumn(&eventV4{}, "labels"))
+	assertions.True(db.Migrator().HasIndex(&eventV2{}, "idx_events_object"))
+
+	reverted, err = MigrateDown(db, 2)
+	assertions.Nil(err)
+	assertions.Len(reverted, 2)
+	assertions.False(db.Migrator().HasIndex(&eventV2{}, "idx_events_object"))
+	assertions.False(db.Migrator().HasColumn(&eventV2{}, "cluster"))
+
+	statuses, err = Status(db)
+	assertions.Nil(err)
+	assertions.NotNil(statuses[0].AppliedAt)
+	assertions.Nil(statuses[1].AppliedAt)
+
+	migrated, err = MigrateUp(db, 0)
+	assertions.Nil(err)
+	assertions.Len(migrated, LatestVersion()-1)
+	var e models.Event
+	assertions.Nil(db.Where("cluster = ?", "").Where("codec = ?", models.CodecNone).First(&e).Error)
+	assertions.Equal("a", e.Name)
+}
+
+// TestMigrateLegacy migrates databases created by AutoMigrate before migrations are introduced
+func TestMigrateLegacy(t *testing.T) {
+	assertions := require.New(t)
+
+	db, err := Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	assertions.Nil(err)
+	assertions.Nil(db.AutoMigrate(&eventV1{}))
+	assertions.Nil(db.Create(&eventV1{InformerName: "w1", EventType: "ADDED", Name: "a"}).Error)
+	// columns added by AutoMigrate later are null in existing rows
+	assertions.Nil(db.Migrator().AddColumn(&eventV2{}, "Cluster"))
+	assertions.Nil(db.Migrator().AddColumn(&eventV2{}, "BaseID"))
+	assertions.Nil(db.Exec("CREATE INDEX idx_events_base_id ON events (base_id)").Error)
+
+	migrated, err := MigrateUp(db, 0)
+	assertions.Nil(err)
+	assertions.Len(migrated, LatestVersion())
+
+	var count int64
+	assertions.Nil(db.Model(&models.Event{}).
+		Where("cluster = ?", "").
+		Where("username = ?", "").
+		Where("informer_name = ?", "w1").
+		Count(&count).Error)
+	assertions.Equal(int64(1), count)
+}
@@ -0,0 +1,286 @@
+package gormdb
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// migrations are applied in order, a migration must never be changed once released,
+// models are frozen copies of the schema at the version, instead of models in use.
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create events table",
+		Up: func(tx *gorm.DB) error {
+			// databases created by AutoMigrate before migrations are adopted as is
+			return tx.AutoMigrate(&eventV1{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&eventV1{})
+		},
+	},
+	{
+		Version: 2,
+		Name:    "add cluster, identity, involved object, owner, attribution and storage columns",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&eventV2{}); err != nil {
+				return err
+			}
+			// columns added by AutoMigrate to existing rows are null, but queries compare with zero values
+			for column, value := range eventV2Columns {
+				if err := tx.Model(&eventV2{}).
+					Where(fmt.Sprintf("%s is null", column)).
+					Update(column, value).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for column := range eventV2Columns {
+				if tx.Migrator().HasColumn(&eventV2{}, column) {
+					if err := tx.Migrator().DropColumn(&eventV2{}, column); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 3,
+		Name:    "add indexes of events",
+		Up: func(tx *gorm.DB) error {
+			return createIndexes(tx, eventIndexes)
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, index := range eventIndexes {
+				if !tx.Migrator().HasIndex(&eventV2{}, index.Name) {
+					continue
+				}
+				if err := tx.Migrator().DropIndex(&eventV2{}, index.Name); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 4,
+		Name:    "add labels column of events",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&eventV4{}); err != nil {
+				return err
+			}
+			return tx.Model(&eventV4{}).Where("labels is null").Update("labels", "").Error
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropColumn(&eventV4{}, "labels"); err != nil {
+				return err
+			}
+			// the table is recreated to drop a column in SQLite, indexes are lost
+			return createIndexes(tx, eventIndexes)
+		},
+	},
+	{
+		Version: 5,
+		Name:    "create full-text index of event contents",
+		Up:      createContentIndex,
+		Down: func(tx *gorm.DB) error {
+			return tx.Exec("DROP TABLE IF EXISTS event_contents").Error
+		},
+	},
+}
+
+// createContentIndex creates event_contents, contents of objects with full-text index,
+// FTS5 is used in SQLite when compiled in (with build tag sqlite_fts5), FTS4 otherwise.
+func createContentIndex(tx *gorm.DB) error {
+	var statements []string
+	switch tx.Dialector.Name() {
+	case "sqlite":
+		var fts5 int64
+		if err := tx.Raw("SELECT count(*) FROM pragma_compile_options WHERE compile_options = ?",
+			"ENABLE_FTS5").Scan(&fts5).Error; err != nil {
+			return err
+		}
+		if fts5 > 0 {
+			statements = []string{"CREATE VIRTUAL TABLE IF NOT EXISTS event_contents USING fts5(content)"}
+		} else {
+			statements = []string{"CREATE VIRTUAL TABLE IF NOT EXISTS event_contents USING fts4(content, tokenize=unicode61)"}
+		}
+	case "postgres":
+		statements = []string{
+			"CREATE TABLE IF NOT EXISTS event_contents " +
+				"(event_id bigint PRIMARY KEY, content text NOT NULL, tsv tsvector NOT NULL)",
+			"CREATE INDEX IF NOT EXISTS idx_event_contents_tsv ON event_contents USING GIN (tsv)",
+		}
+	case "mysql":
+		statements = []string{
+			"CREATE TABLE IF NOT EXISTS event_contents " +
+				"(event_id bigint unsigned PRIMARY KEY, content longtext NOT NULL, " +
+				"FULLTEXT INDEX idx_event_contents_content (content)) ENGINE=InnoDB",
+		}
+	default:
+		return errors.Errorf("unsupported dialect: %s", tx.Dialector.Name())
+	}
+	for _, statement := range statements {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eventV1 is the events table before migrations are introduced
+type eventV1 struct {
+	ID           uint      `gorm:"primarykey"`
+	CreateTime   time.Time `gorm:"autoCreateTime"`
+	InformerName string
+	EventType    string
+	Group        string `gorm:"column:event_group"`
+	Version      string
+	Resource     string
+	Kind         string
+	Namespace    string
+	Name         string
+	Obj          []byte
+	OldObj       []byte
+}
+
+func (eventV1) TableName() string {
+	return "events"
+}
+
+// eventV2 is the events table with columns added by AutoMigrate before migrations are introduced
+type eventV2 struct {
+	eventV1
+
+	Cluster           string
+	UID               string
+	ResourceVersion   string
+	FinalStateUnknown bool
+	InvolvedKind      string
+	InvolvedNamespace string
+	InvolvedName      string
+	InvolvedUID       string
+	Username          string
+	UserGroups        string
+	UserAgent         string
+	SourceIPs         string
+	Manager           string
+	OwnerChain        string
+	BaseID            uint
+	PatchDepth        int
+	Codec             string
+}
+
+// eventV2Columns are columns added in version 2, with their zero values
+var eventV2Columns = map[string]interface{}{
+	"cluster":             "",
+	"uid":                 "",
+	"resource_version":    "",
+	"final_state_unknown": false,
+	"involved_kind":       "",
+	"involved_namespace":  "",
+	"involved_name":       "",
+	"involved_uid":        "",
+	"username":            "",
+	"user_groups":         "",
+	"user_agent":          "",
+	"source_ips":          "",
+	"manager":             "",
+	"owner_chain":         "",
+	"base_id":             0,
+	"patch_depth":         0,
+	"codec":               "",
+}
+
+// eventV4 is the events table with labels of objects
+type eventV4 struct {
+	eventV2
+
+	Labels string
+}
+
+// index is an index of the events table
+type index struct {
+	Name    string
+	Columns []indexColumn
+}
+
+// indexColumn is a column of an index,
+// text columns are indexed by prefix in MySQL, as the key length is limited.
+type indexColumn struct {
+	Name        string
+	MySQLPrefix int
+}
+
+func (i index) createStatement(dialect string) string {
+	columns := make([]string, 0, len(i.Columns))
+	for _, c := range i.Columns {
+		if dialect == "mysql" && c.MySQLPrefix > 0 {
+			columns = append(columns, fmt.Sprintf("%s(%d)", c.Name, c.MySQLPrefix))
+		} else {
+			columns = append(columns, c.Name)
+		}
+	}
+	return fmt.Sprintf("CREATE INDEX %s ON events (%s)", i.Name, strings.Join(columns, ", "))
+}
+
+// createIndexes creates indexes not existing yet
+func createIndexes(tx *gorm.DB, indexes []index) error {
+	for _, index := range indexes {
+		// idx_events_base_id may have been created by AutoMigrate
+		if tx.Migrator().HasIndex(&eventV2{}, index.Name) {
+			continue
+		}
+		if err := tx.Exec(index.createStatement(tx.Dialector.Name())).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eventIndexes are indexes for query patterns of the event store
+var eventIndexes = []index{
+	{
+		// versions of an object, e.g. ListCurrentlyAdded and MaxVersions of retention
+		Name: "idx_events_object",
+		Columns: []indexColumn{
+			{"cluster", 32}, {"informer_name", 96}, {"event_group", 64}, {"version", 16},
+			{"resource", 64}, {"namespace", 64}, {"name", 160}, {"id", 0},
+		},
+	},
+	{
+		// events of an informer, latest first, e.g. List and MaxEvents of retention
+		Name:    "idx_events_informer",
+		Columns: []indexColumn{{"cluster", 32}, {"informer_name", 96}, {"id", 0}},
+	},
+	{
+		// events in a time range, e.g. MaxAge of retention and attribution
+		Name:    "idx_events_create_time",
+		Columns: []indexColumn{{"create_time", 0}},
+	},
+	{
+		// events of an object across informers, e.g. attribution
+		Name: "idx_events_name",
+		Columns: []indexColumn{
+			{"cluster", 32}, {"namespace", 64}, {"name", 160}, {"resource", 64},
+		},
+	},
+	{
+		// Kubernetes Events of an object, e.g. ListRelated
+		Name: "idx_events_involved",
+		Columns: []indexColumn{
+			{"cluster", 32}, {"involved_namespace", 64}, {"involved_name", 160}, {"involved_kind", 64},
+		},
+	},
+	{
+		// events stored as patches against an event
+		Name:    "idx_events_base_id",
+		Columns: []indexColumn{{"base_id", 0}},
+	},
+}
@@ -2,7 +2,6 @@ package gormdb
 
 import (
 	"github.com/pkg/errors"
-	"github.com/spongeprojects/kubebigbrother/pkg/models"
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
@@ -11,20 +10,20 @@ import (
 	"path"
 )
 
+// New connects to the database, and applies pending migrations
 func New(dialect, dsn string) (*gorm.DB, error) {
-	dbi, err := newDB(dialect, dsn)
+	dbi, err := Open(dialect, dsn)
 	if err != nil {
 		return nil, err
 	}
-	if err := dbi.AutoMigrate(
-		&models.Event{},
-	); err != nil {
-		return nil, errors.Wrap(err, "auto migrate error")
+	if _, err := MigrateUp(dbi, 0); err != nil {
+		return nil, errors.Wrap(err, "migrate error")
 	}
 	return dbi, nil
 }
 
-func newDB(dialect, dsn string) (*gorm.DB, error) {
+// Open connects to the database without applying migrations
+func Open(dialect, dsn string) (*gorm.DB, error) {
 	config := &gorm.Config{}
 	switch dialect {
 	case "mysql":
@@ -0,0 +1,62 @@
+package informers
+
+import (
+	"github.com/spongeprojects/kubebigbrother/pkg/event"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// actorOf finds who made the change from audit records received already,
+// the field manager of the latest change is used when there is no audit record.
+// Deletions are not recorded in managedFields, the actor is unknown without audit records.
+func (s *InformerSet) actorOf(e *event.Event, gvr schema.GroupVersionResource) *event.Actor {
+	if e.Type == event.TypeOccurred || e.Obj == nil {
+		return nil
+	}
+
+	manager := ""
+	if e.Type != event.TypeDeleted {
+		manager = latestManager(e.Obj)
+	}
+
+	if s.AuditCache != nil {
+		actor, ok := s.AuditCache.Lookup(s.Cluster, gvr,
+			e.Obj.GetNamespace(), e.Obj.GetName(), e.Obj.GetResourceVersion())
+		if ok {
+			actor.Manager = manager
+			return actor
+		}
+	}
+
+	if manager == "" {
+		return nil
+	}
+	return &event.Actor{Manager: manager}
+}
+
+// latestManager returns the manager of the latest entry in metadata.managedFields
+func latestManager(obj *unstructured.Unstructured) string {
+	if entry := latestManagedFieldsEntry(obj); entry != nil {
+		return entry.Manager
+	}
+	return ""
+}
+
+// latestManagedFieldsEntry returns the latest entry in metadata.managedFields, nil if there is none
+func latestManagedFieldsEntry(obj *unstructured.Unstructured) *metav1.ManagedFieldsEntry {
+	var latest *metav1.ManagedFieldsEntry
+	var latestTime int64 = -1
+	entries := obj.GetManagedFields()
+	for i := range entries {
+		var t int64
+		if entries[i].Time != nil {
+			t = entries[i].Time.UnixNano()
+		}
+		if t > latestTime {
+			latestTime = t
+			latest = &entries[i]
+		}
+	}
+	return latest
+}
@@ -0,0 +1,32 @@
+package informers
+
+import (
+	"github.com/spongeprojects/kubebigbrother/pkg/event"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"testing"
+	"time"
+)
+
+var deploymentsGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+func TestActorOf(t *testing.T) {
+	assertions := require.New(t)
+
+	now := time.Now()
+	obj := newDeployment("2", 2, 1)
+	obj.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{Manager: "kube-controller-manager", Time: &metav1.Time{Time: now.Add(-time.Minute)}},
+		{Manager: "kubectl-scale", Time: &metav1.Time{Time: now}},
+		{Manager: "unknown"},
+	})
+	assertions.Equal("kubectl-scale", latestManager(obj))
+
+	s := &InformerSet{}
+	actor := s.actorOf(event.NewUpdated(obj, nil), deploymentsGVR)
+	assertions.Equal(&event.Actor{Manager: "kubectl-scale"}, actor)
+	assertions.Equal("kubectl-scale", actor.String())
+
+	assertions.Nil(s.actorOf(event.NewDeleted(obj), deploymentsGVR))
+}
@@ -5,10 +5,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spongeprojects/kubebigbrother/pkg/models"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/util/wait"
-	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
-	"time"
 )
 
 func (s *InformerSet) RunClusterWatcherWorker() {
@@ -44,29 +41,32 @@ func (s *InformerSet) processClusterWatcher(key string) error {
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			klog.V(2).Infof("[clusterwatcher] clusterwatcher deleted: %s", key)
-			if informer, ok := s.ClusterWatcherMap[key]; ok {
+			s.informersMu.Lock()
+			for _, informer := range s.ClusterWatcherMap[key] {
 				informer.ShutDown()
 			}
 			delete(s.ClusterWatcherMap, key)
+			s.informersMu.Unlock()
 			return nil
 		}
 		return errors.Wrap(err, "get watcher error")
 	}
 
-	informer, err := s.setupInformer(
-		"", models.ClusterWatcherInformerName(key), watcher.Spec)
+	s.informersMu.Lock()
+	current := s.ClusterWatcherMap[key]
+	s.informersMu.Unlock()
+
+	running, err := s.reconcileInformers(
+		"", models.ClusterWatcherInformerName(key), watcher.Spec, current)
+
+	s.informersMu.Lock()
+	s.ClusterWatcherMap[key] = running
+	s.informersMu.Unlock()
+
 	if err != nil {
 		return errors.Wrap(err, "create informer error")
 	}
-	klog.V(2).Infof("[clusterwatcher] clusterwatcher added: %s", key)
-	go informer.Informer.Run(informer.StopCh)
-	cache.WaitForCacheSync(informer.StopCh, informer.Informer.HasSynced)
-
-	for i := 0; i < informer.Workers; i++ {
-		go wait.Until(informer.RunWorker, time.Second, informer.StopCh)
-	}
-
-	s.ClusterWatcherMap[key] = informer
+	klog.V(2).Infof("[clusterwatcher] clusterwatcher reconciled: %s, %d informers running", key, len(running))
 	return nil
 }
 
@@ -2,11 +2,21 @@ package informers
 
 import (
 	"github.com/pkg/errors"
+	spg "github.com/spongeprojects/client-go/api/spongeprojects.com/v1alpha1"
+	"github.com/spongeprojects/kubebigbrother/pkg/audit"
 	"github.com/spongeprojects/kubebigbrother/pkg/stores/event_store"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
 	"time"
 )
 
 type Config struct {
+	// Cluster is the name of the cluster, recorded in every event
+	Cluster string
+
+	// RESTConfig is the config to connect to the cluster, built from Kubeconfig when nil
+	RESTConfig *rest.Config
+
 	Kubeconfig          string
 	DefaultWorkers      int
 	DefaultMaxRetries   int
@@ -14,11 +24,38 @@ type Config struct {
 	MinResyncPeriod     time.Duration
 	JustWatch           bool
 	EventStore          event_store.Interface
+
+	// DefaultOwnerDepth is the max depth of owner chains resolved, 3 when 0, negative disables resolving
+	DefaultOwnerDepth int
+
+	// AuditCache keeps recent audit records to attribute changes, nil when not receiving audit events
+	AuditCache *audit.Cache
+
+	// ConfigSource is where channels and watchers are loaded from, crd, file or both, crd by default
+	ConfigSource string
+
+	// ConfigPath is a YAML file or a directory of YAML files, used when loading from files
+	ConfigPath string
+
+	// StaticObjects are channels and watchers used when ConfigSource is static
+	StaticObjects []runtime.Object
+
+	// PrintConfig is config of the print channel used when just watching
+	PrintConfig *spg.ChannelPrintConfig
 }
 
 func (c *Config) Validate() error {
 	if !c.JustWatch && c.EventStore == nil {
 		return errors.New("event store cannot be nil when not just watching")
 	}
+	switch c.ConfigSource {
+	case "", ConfigSourceCRD, ConfigSourceStatic:
+	case ConfigSourceFile, ConfigSourceBoth:
+		if c.ConfigPath == "" {
+			return errors.Errorf("config path cannot be empty when config source is %s", c.ConfigSource)
+		}
+	default:
+		return errors.Errorf("unknown config source: %s", c.ConfigSource)
+	}
 	return nil
 }
@@ -0,0 +1,234 @@
+package informers
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	spg "github.com/spongeprojects/client-go/api/spongeprojects.com/v1alpha1"
+	"github.com/spongeprojects/kubebigbrother/pkg/event"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"sort"
+	"time"
+)
+
+const (
+	defaultMaxWarningEvents = 5
+	defaultLogLines         = 20
+	defaultLogBytes         = 4096
+
+	// maxCrashedContainerLogs limits log tails attached for a Pod with many crashed containers
+	maxCrashedContainerLogs = 3
+
+	// enrichTimeout bounds time spent on enriching an event, as a worker of the informer is blocked
+	enrichTimeout = 5 * time.Second
+)
+
+// enricher attaches context to events before notification
+type enricher struct {
+	id         string
+	kubeClient kubernetes.Interface
+	config     spg.WatcherEnrich
+}
+
+// newEnricher creates enricher, returns nil when nothing to enrich
+func newEnricher(id string, kubeClient kubernetes.Interface, config *spg.WatcherEnrich) *enricher {
+	if config == nil || (!config.WarningEvents && !config.ContainerStatuses && !config.Logs) {
+		return nil
+	}
+	c := *config
+	if c.MaxWarningEvents < 1 {
+		c.MaxWarningEvents = defaultMaxWarningEvents
+	}
+	if c.LogLines < 1 {
+		c.LogLines = defaultLogLines
+	}
+	if c.LogBytes < 1 {
+		c.LogBytes = defaultLogBytes
+	}
+	return &enricher{
+		id:         id,
+		kubeClient: kubeClient,
+		config:     c,
+	}
+}
+
+// enrich attaches enrichment to e, errors are logged and skipped,
+// OCCURRED events are not enriched, as they are Kubernetes Events themselves.
+func (r *enricher) enrich(e *event.Event) {
+	if e.Type == event.TypeOccurred || e.Obj == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), enrichTimeout)
+	defer cancel()
+
+	enrichment := &event.Enrichment{}
+	if r.config.WarningEvents {
+		warningEvents, err := r.warningEvents(ctx, e.Obj)
+		if err != nil {
+			klog.Warningf("[%s] list warning events error: %s", r.id, err)
+		}
+		enrichment.WarningEvents = warningEvents
+	}
+
+	if e.Obj.GetAPIVersion() == "v1" && e.Obj.GetKind() == "Pod" &&
+		(r.config.ContainerStatuses || r.config.Logs) {
+		pod := &corev1.Pod{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(e.Obj.Object, pod); err != nil {
+			klog.Warningf("[%s] convert pod error: %s", r.id, err)
+		} else {
+			statuses := append(append([]corev1.ContainerStatus{},
+				pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+			if r.config.ContainerStatuses {
+				enrichment.Containers = containerStatuses(statuses)
+			}
+			// logs of deleted Pods are gone
+			if r.config.Logs && e.Type != event.TypeDeleted {
+				enrichment.Logs = r.crashedContainerLogs(ctx, pod, statuses)
+			}
+		}
+	}
+
+	e.Enrichment = enrichment
+}
+
+// warningEvents lists recent Warning Events of obj, latest first
+func (r *enricher) warningEvents(
+	ctx context.Context, obj *unstructured.Unstructured) ([]event.KubeEvent, error) {
+	list, err := r.kubeClient.CoreV1().Events(obj.GetNamespace()).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.Set{
+			"involvedObject.uid": string(obj.GetUID()),
+			"type":               corev1.EventTypeWarning,
+		}.String(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list events error")
+	}
+
+	var items []corev1.Event
+	for _, item := range list.Items {
+		// filtered again, in case field selectors are not supported
+		if item.InvolvedObject.UID == obj.GetUID() && item.Type == corev1.EventTypeWarning {
+			items = append(items, item)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return lastSeen(&items[i]).After(lastSeen(&items[j]))
+	})
+	if len(items) > r.config.MaxWarningEvents {
+		items = items[:r.config.MaxWarningEvents]
+	}
+
+	warningEvents := make([]event.KubeEvent, 0, len(items))
+	for _, item := range items {
+		count := int64(item.Count)
+		if item.Series != nil && int64(item.Series.Count) > count {
+			count = int64(item.Series.Count)
+		}
+		warningEvents = append(warningEvents, event.KubeEvent{
+			Type:    item.Type,
+			Reason:  item.Reason,
+			Message: item.Message,
+			Count:   count,
+			InvolvedObject: event.InvolvedObject{
+				Kind:      item.InvolvedObject.Kind,
+				Namespace: item.InvolvedObject.Namespace,
+				Name:      item.InvolvedObject.Name,
+				UID:       string(item.InvolvedObject.UID),
+			},
+		})
+	}
+	return warningEvents, nil
+}
+
+// lastSeen returns the time an Event was last seen
+func lastSeen(e *corev1.Event) time.Time {
+	switch {
+	case e.Series != nil:
+		return e.Series.LastObservedTime.Time
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	default:
+		return e.CreationTimestamp.Time
+	}
+}
+
+func containerStatuses(statuses []corev1.ContainerStatus) []event.ContainerStatus {
+	containers := make([]event.ContainerStatus, 0, len(statuses))
+	for _, status := range statuses {
+		container := event.ContainerStatus{
+			Name:         status.Name,
+			Ready:        status.Ready,
+			RestartCount: status.RestartCount,
+		}
+		switch {
+		case status.State.Waiting != nil:
+			container.State = "Waiting"
+			container.Reason = status.State.Waiting.Reason
+		case status.State.Running != nil:
+			container.State = "Running"
+		case status.State.Terminated != nil:
+			container.State = "Terminated"
+			container.Reason = status.State.Terminated.Reason
+		}
+		if terminated := status.LastTerminationState.Terminated; terminated != nil {
+			container.LastTerminationReason = terminated.Reason
+			container.LastTerminationExitCode = terminated.ExitCode
+		}
+		containers = append(containers, container)
+	}
+	return containers
+}
+
+// crashedContainerLogs gets log tails of crashed containers,
+// logs of the previous instance are used when the container has restarted.
+func (r *enricher) crashedContainerLogs(ctx context.Context,
+	pod *corev1.Pod, statuses []corev1.ContainerStatus) []event.ContainerLog {
+	var logs []event.ContainerLog
+	for _, status := range statuses {
+		if len(logs) >= maxCrashedContainerLogs {
+			break
+		}
+		var previous bool
+		switch {
+		case status.State.Terminated != nil && status.State.Terminated.ExitCode != 0:
+			previous = false
+		case status.LastTerminationState.Terminated != nil &&
+			status.LastTerminationState.Terminated.ExitCode != 0:
+			previous = true
+		default:
+			continue
+		}
+
+		tailLines := r.config.LogLines
+		limitBytes := r.config.LogBytes
+		b, err := r.kubeClient.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+			Container:  status.Name,
+			Previous:   previous,
+			TailLines:  &tailLines,
+			LimitBytes: &limitBytes,
+		}).DoRaw(ctx)
+		if err != nil {
+			klog.V(2).Infof("[%s] get logs error: %s/%s: %s", r.id, pod.Name, status.Name, err)
+			continue
+		}
+		truncated := int64(len(b)) >= limitBytes
+		if truncated {
+			b = b[:limitBytes]
+		}
+		logs = append(logs, event.ContainerLog{
+			Container: status.Name,
+			Previous:  previous,
+			Tail:      string(b),
+			Truncated: truncated,
+		})
+	}
+	return logs
+}
@@ -0,0 +1,107 @@
+package informers
+
+import (
+	spg "github.com/spongeprojects/client-go/api/spongeprojects.com/v1alpha1"
+	"github.com/spongeprojects/kubebigbrother/pkg/event"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"testing"
+	"time"
+)
+
+func newWarningEvent(name, reason string, uid types.UID, lastTimestamp time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: name},
+		InvolvedObject: corev1.ObjectReference{
+			Kind: "Pod", Namespace: "demo", Name: "canary", UID: uid,
+		},
+		Type:          corev1.EventTypeWarning,
+		Reason:        reason,
+		Count:         1,
+		LastTimestamp: metav1.NewTime(lastTimestamp),
+	}
+}
+
+func TestEnricher(t *testing.T) {
+	assertions := require.New(t)
+
+	assertions.Nil(newEnricher("test", nil, nil))
+	assertions.Nil(newEnricher("test", nil, &spg.WatcherEnrich{}))
+
+	now := time.Now()
+	kubeClient := fake.NewSimpleClientset(
+		newWarningEvent("e1", "BackOff", "pod", now.Add(-time.Minute)),
+		newWarningEvent("e2", "Unhealthy", "pod", now),
+		newWarningEvent("e3", "FailedMount", "pod", now.Add(-time.Hour)),
+		newWarningEvent("e4", "BackOff", "another", now),
+	)
+	enricher := newEnricher("test", kubeClient, &spg.WatcherEnrich{
+		WarningEvents:     true,
+		MaxWarningEvents:  2,
+		ContainerStatuses: true,
+		Logs:              true,
+	})
+
+	pod := &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "canary", UID: "pod"},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "app",
+				RestartCount: 3,
+				State: corev1.ContainerState{
+					Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+				},
+				LastTerminationState: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137},
+				},
+			}, {
+				Name:  "sidecar",
+				Ready: true,
+				State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			}},
+		},
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pod)
+	assertions.Nil(err)
+
+	e := event.NewUpdated(&unstructured.Unstructured{Object: obj}, nil)
+	enricher.enrich(e)
+	assertions.NotNil(e.Enrichment)
+
+	assertions.Len(e.Enrichment.WarningEvents, 2)
+	assertions.Equal("Unhealthy", e.Enrichment.WarningEvents[0].Reason)
+	assertions.Equal("BackOff", e.Enrichment.WarningEvents[1].Reason)
+
+	assertions.Equal([]event.ContainerStatus{{
+		Name:                    "app",
+		RestartCount:            3,
+		State:                   "Waiting",
+		Reason:                  "CrashLoopBackOff",
+		LastTerminationReason:   "OOMKilled",
+		LastTerminationExitCode: 137,
+	}, {
+		Name:  "sidecar",
+		Ready: true,
+		State: "Running",
+	}}, e.Enrichment.Containers)
+
+	assertions.Len(e.Enrichment.Logs, 1)
+	assertions.Equal("app", e.Enrichment.Logs[0].Container)
+	assertions.True(e.Enrichment.Logs[0].Previous)
+
+	// events are enriched by workers of the informer, once whether retried or not
+	informer := &Informer{ID: "test", enricher: enricher}
+	item := &eventWrapper{Event: event.NewUpdated(&unstructured.Unstructured{Object: obj}, nil)}
+	assertions.Nil(informer.processItem(item))
+	assertions.True(item.enriched)
+	assertions.Len(item.Enrichment.WarningEvents, 2)
+	enrichment := item.Enrichment
+	assertions.Nil(informer.processItem(item))
+	assertions.Same(enrichment, item.Enrichment)
+}
@@ -19,6 +19,9 @@ type eventWrapper struct {
 
 	// ChannelsToProcess defines channels to process
 	ChannelsToProcess []ChannelToProcess
+
+	// enriched is true once the event is enriched, it's not enriched again when retried
+	enriched bool
 }
 
 func (s *InformerSet) wrap(e *event.Event, channelNames []string) *eventWrapper {
@@ -0,0 +1,321 @@
+package informers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"github.com/pkg/errors"
+	spg "github.com/spongeprojects/client-go/api/spongeprojects.com/v1alpha1"
+	spgl "github.com/spongeprojects/client-go/client/listers/spongeprojects.com/v1alpha1"
+	"io"
+	"io/ioutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Config sources, where channels and watchers are loaded from
+const (
+	ConfigSourceCRD  = "crd"
+	ConfigSourceFile = "file"
+	ConfigSourceBoth = "both"
+
+	// ConfigSourceStatic loads objects given in Config.StaticObjects, never changing
+	ConfigSourceStatic = "static"
+)
+
+// fileSourcePollPeriod is the period to check files for changes
+const fileSourcePollPeriod = 10 * time.Second
+
+// FileSource loads Channels, Watchers and ClusterWatchers from YAML files,
+// manifests are the same as those applied to the cluster when using CRDs.
+// Objects are kept in indexers, listers on top of them are used like those of CRD informers,
+// handlers are notified of changes like those of CRD informers as well.
+type FileSource struct {
+	// Path is a YAML file, or a directory of YAML files,
+	// empty for static sources, whose objects never change
+	Path string
+
+	static []runtime.Object
+
+	channels        cache.Indexer
+	watchers        cache.Indexer
+	clusterWatchers cache.Indexer
+
+	channelHandlers        []cache.ResourceEventHandler
+	watcherHandlers        []cache.ResourceEventHandler
+	clusterWatcherHandlers []cache.ResourceEventHandler
+
+	// digest is the digest of files loaded, used to skip reloading unchanged files
+	digest []byte
+}
+
+// NewFileSource creates FileSource, files are not loaded until Run
+func NewFileSource(path string) *FileSource {
+	newIndexer := func() cache.Indexer {
+		return cache.NewIndexer(cache.MetaNamespaceKeyFunc,
+			cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	}
+	return &FileSource{
+		Path:            path,
+		channels:        newIndexer(),
+		watchers:        newIndexer(),
+		clusterWatchers: newIndexer(),
+	}
+}
+
+// NewStaticSource creates FileSource of objects given, e.g. watchers built from command line arguments
+func NewStaticSource(objs ...runtime.Object) *FileSource {
+	f := NewFileSource("")
+	f.static = objs
+	return f
+}
+
+func (f *FileSource) ChannelLister() spgl.ChannelLister {
+	return spgl.NewChannelLister(f.channels)
+}
+
+func (f *FileSource) WatcherLister() spgl.WatcherLister {
+	return spgl.NewWatcherLister(f.watchers)
+}
+
+func (f *FileSource) ClusterWatcherLister() spgl.ClusterWatcherLister {
+	return spgl.NewClusterWatcherLister(f.clusterWatchers)
+}
+
+func (f *FileSource) AddChannelHandler(handler cache.ResourceEventHandler) {
+	f.channelHandlers = append(f.channelHandlers, handler)
+}
+
+func (f *FileSource) AddWatcherHandler(handler cache.ResourceEventHandler) {
+	f.watcherHandlers = append(f.watcherHandlers, handler)
+}
+
+func (f *FileSource) AddClusterWatcherHandler(handler cache.ResourceEventHandler) {
+	f.clusterWatcherHandlers = append(f.clusterWatcherHandlers, handler)
+}
+
+// Load loads files and notifies handlers of changes,
+// nothing is changed if any of the files is invalid.
+func (f *FileSource) Load() error {
+	objs, digest, err := f.read()
+	if err != nil {
+		return err
+	}
+	if digest != nil && bytes.Equal(digest, f.digest) {
+		return nil
+	}
+	f.digest = digest
+
+	var channels, watchers, clusterWatchers []interface{}
+	for _, obj := range objs {
+		switch obj.(type) {
+		case *spg.Channel:
+			channels = append(channels, obj)
+		case *spg.Watcher:
+			watchers = append(watchers, obj)
+		case *spg.ClusterWatcher:
+			clusterWatchers = append(clusterWatchers, obj)
+		}
+	}
+
+	klog.V(2).Infof("[file] config loaded: %d channels, %d watchers, %d cluster watchers",
+		len(channels), len(watchers), len(clusterWatchers))
+
+	syncIndexer(f.channels, channels, f.channelHandlers)
+	syncIndexer(f.watchers, watchers, f.watcherHandlers)
+	syncIndexer(f.clusterWatchers, clusterWatchers, f.clusterWatcherHandlers)
+	return nil
+}
+
+// read reads objects from files, digest of files is returned to detect changes,
+// objects of static sources are returned with nil digest.
+func (f *FileSource) read() (objs []runtime.Object, digest []byte, err error) {
+	if f.Path == "" {
+		return f.static, nil, nil
+	}
+
+	files, err := f.files()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	h := sha256.New()
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "read config file error: %s", file)
+		}
+		_, _ = h.Write(content)
+
+		fileObjs, err := decodeConfigObjects(content)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid config file: %s", file)
+		}
+		objs = append(objs, fileObjs...)
+	}
+	return objs, h.Sum(nil), nil
+}
+
+// Run polls files for changes until stopCh is closed, Load should be called before Run
+func (f *FileSource) Run(stopCh <-chan struct{}) {
+	if f.Path == "" {
+		return // static sources never change
+	}
+	wait.Until(func() {
+		if err := f.Load(); err != nil {
+			klog.Warningf("[file] reload config error, previous config is kept: %s", err)
+		}
+	}, fileSourcePollPeriod, stopCh)
+}
+
+// files lists config files, hidden files are skipped, e.g. ..data of mounted ConfigMaps
+func (f *FileSource) files() ([]string, error) {
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return nil, errors.Wrap(err, "stat config path error")
+	}
+	if !info.IsDir() {
+		return []string{f.Path}, nil
+	}
+
+	entries, err := ioutil.ReadDir(f.Path)
+	if err != nil {
+		return nil, errors.Wrap(err, "read config dir error")
+	}
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		switch filepath.Ext(name) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		file := filepath.Join(f.Path, name)
+		// entries of mounted ConfigMaps are symlinks, stat follows them
+		if info, err := os.Stat(file); err != nil || info.IsDir() {
+			continue
+		}
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// decodeConfigObjects decodes Channels, Watchers and ClusterWatchers in multi-document YAML,
+// Watchers without namespace are put in the default namespace.
+func decodeConfigObjects(content []byte) ([]runtime.Object, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), 4096)
+	var objs []runtime.Object
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, errors.Wrap(err, "decode yaml error")
+		}
+		if len(raw) == 0 || string(raw) == "null" {
+			continue // empty document
+		}
+
+		var typeMeta metav1.TypeMeta
+		if err := json.Unmarshal(raw, &typeMeta); err != nil {
+			return nil, errors.Wrap(err, "decode type meta error")
+		}
+		if typeMeta.APIVersion != spg.SchemeGroupVersion.String() {
+			return nil, errors.Errorf("unsupported apiVersion: %s", typeMeta.APIVersion)
+		}
+
+		var obj runtime.Object
+		switch typeMeta.Kind {
+		case "Channel":
+			obj = &spg.Channel{}
+		case "Watcher":
+			obj = &spg.Watcher{}
+		case "ClusterWatcher":
+			obj = &spg.ClusterWatcher{}
+		default:
+			return nil, errors.Errorf("unsupported kind: %s", typeMeta.Kind)
+		}
+		if err := json.Unmarshal(raw, obj); err != nil {
+			return nil, errors.Wrapf(err, "decode %s error", typeMeta.Kind)
+		}
+
+		accessor := obj.(metav1.Object)
+		if accessor.GetName() == "" {
+			return nil, errors.Errorf("name of %s cannot be empty", typeMeta.Kind)
+		}
+		switch typeMeta.Kind {
+		case "Watcher":
+			if accessor.GetNamespace() == "" {
+				accessor.SetNamespace(metav1.NamespaceDefault)
+			}
+		default:
+			accessor.SetNamespace("")
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// syncIndexer replaces objects in indexer with objs, handlers are notified of changes,
+// objects are compared by spec, metadata changes are ignored.
+func syncIndexer(indexer cache.Indexer, objs []interface{}, handlers []cache.ResourceEventHandler) {
+	keep := make(map[string]bool, len(objs))
+	for _, obj := range objs {
+		key, _ := cache.MetaNamespaceKeyFunc(obj)
+		if keep[key] {
+			klog.Warningf("[file] duplicated config, the last one is used: %s", key)
+		}
+		keep[key] = true
+
+		old, exist, _ := indexer.GetByKey(key)
+		_ = indexer.Add(obj)
+		switch {
+		case !exist:
+			for _, h := range handlers {
+				h.OnAdd(obj)
+			}
+		case !reflect.DeepEqual(specOf(old), specOf(obj)):
+			for _, h := range handlers {
+				h.OnUpdate(old, obj)
+			}
+		}
+	}
+
+	for _, old := range indexer.List() {
+		key, _ := cache.MetaNamespaceKeyFunc(old)
+		if keep[key] {
+			continue
+		}
+		_ = indexer.Delete(old)
+		for _, h := range handlers {
+			h.OnDelete(old)
+		}
+	}
+}
+
+func specOf(obj interface{}) interface{} {
+	switch o := obj.(type) {
+	case *spg.Channel:
+		return o.Spec
+	case *spg.Watcher:
+		return o.Spec
+	case *spg.ClusterWatcher:
+		return o.Spec
+	}
+	return obj
+}
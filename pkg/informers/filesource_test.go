@@ -0,0 +1,97 @@
+package informers
+
+import (
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"k8s.io/client-go/tools/cache"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const fileSourceConfig = `
+apiVersion: "spongeprojects.com/v1alpha1"
+kind: Channel
+metadata:
+  name: print-to-stdout
+spec:
+  type: print
+  print:
+    writer: stdout
+---
+apiVersion: "spongeprojects.com/v1alpha1"
+kind: Watcher
+metadata:
+  name: secrets
+spec:
+  resource: secrets
+  noticeWhenAdded: true
+  workers: 2
+---
+apiVersion: "spongeprojects.com/v1alpha1"
+kind: ClusterWatcher
+metadata:
+  name: configmaps
+spec:
+  resource: configmaps
+  noticeWhenAdded: true
+`
+
+func TestFileSource(t *testing.T) {
+	assertions := require.New(t)
+
+	dir, err := ioutil.TempDir("", "kubebigbrother")
+	assertions.Nil(err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "config.yaml")
+	assertions.Nil(ioutil.WriteFile(file, []byte(fileSourceConfig), 0644))
+	assertions.Nil(ioutil.WriteFile(filepath.Join(dir, ".hidden.yaml"), []byte("invalid"), 0644))
+
+	f := NewFileSource(dir)
+	var added, updated, deleted []string
+	f.AddWatcherHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			k, _ := cache.MetaNamespaceKeyFunc(obj)
+			added = append(added, k)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			k, _ := cache.MetaNamespaceKeyFunc(newObj)
+			updated = append(updated, k)
+		},
+		DeleteFunc: func(obj interface{}) {
+			k, _ := cache.MetaNamespaceKeyFunc(obj)
+			deleted = append(deleted, k)
+		},
+	})
+
+	assertions.Nil(f.Load())
+	assertions.Equal([]string{"default/secrets"}, added)
+
+	channel, err := f.ChannelLister().Get("print-to-stdout")
+	assertions.Nil(err)
+	assertions.Equal("stdout", channel.Spec.Print.Writer)
+	watcher, err := f.WatcherLister().Watchers("default").Get("secrets")
+	assertions.Nil(err)
+	assertions.Equal(2, watcher.Spec.Workers)
+	_, err = f.ClusterWatcherLister().Get("configmaps")
+	assertions.Nil(err)
+
+	// unchanged files are not reloaded, invalid files are not applied
+	assertions.Nil(f.Load())
+	assertions.Nil(ioutil.WriteFile(file, []byte("kind: Unknown"), 0644))
+	assertions.NotNil(f.Load())
+	assertions.Len(added, 1)
+	assertions.Empty(deleted)
+
+	changed := strings.Replace(fileSourceConfig, "workers: 2", "workers: 3", 1)
+	assertions.Nil(ioutil.WriteFile(file, []byte(changed), 0644))
+	assertions.Nil(f.Load())
+	assertions.Equal([]string{"default/secrets"}, updated)
+
+	assertions.Nil(ioutil.WriteFile(file, []byte(""), 0644))
+	assertions.Nil(f.Load())
+	assertions.Equal([]string{"default/secrets"}, deleted)
+	_, err = f.ChannelLister().Get("print-to-stdout")
+	assertions.NotNil(err)
+}
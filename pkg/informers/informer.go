@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/dustin/go-humanize"
 	"github.com/pkg/errors"
+	spg "github.com/spongeprojects/client-go/api/spongeprojects.com/v1alpha1"
 	"github.com/spongeprojects/kubebigbrother/pkg/channels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/tools/cache"
@@ -23,9 +24,27 @@ type Informer struct {
 	// GVR is group version kind of Resource
 	GVR schema.GroupVersionResource
 
+	// Spec is the watcher spec the informer is created from
+	Spec spg.WatcherSpec
+
 	// UpdateOn defines fields to watch, used with NoticeWhenUpdated
 	UpdateOn []string
 
+	// IgnoreOn defines fields not to watch, used with NoticeWhenUpdated
+	IgnoreOn []string
+
+	// TriggerExpression is a CEL expression, notice when it becomes true
+	TriggerExpression string
+
+	// IgnoreFields defines fields to ignore when comparing objects, used with NoticeWhenUpdated
+	IgnoreFields []string
+
+	// MetadataOnly means only metadata of objects are watched and cached
+	MetadataOnly bool
+
+	// RedactFields defines fields to redact before storage and notification
+	RedactFields []string
+
 	// ChannelMap defines channels to send notification
 	ChannelMap channels.ChannelMap
 
@@ -36,6 +55,15 @@ type Informer struct {
 
 	processingItems *sync.WaitGroup
 
+	// timerScheduler schedules timers of this informer
+	timerScheduler *TimerScheduler
+
+	// enricher attaches context to events in workers before they are sent, nil when disabled
+	enricher *enricher
+
+	// stateIndexes are states preloaded from the event store, retained to the cache once synced
+	stateIndexes []*stateIndex
+
 	StopCh chan struct{}
 
 	// Workers is number of workers
@@ -78,8 +106,14 @@ func (i *Informer) processNextItem() bool {
 	return true
 }
 
-// processItem process an item synchronously
+// processItem process an item synchronously,
+// the event is enriched before it's sent for the first time, so that informer handlers are never blocked.
 func (i *Informer) processItem(item *eventWrapper) error {
+	if i.enricher != nil && !item.enriched {
+		i.enricher.enrich(item.Event)
+		item.enriched = true
+	}
+
 	errs := make(map[ChannelToProcess]error)
 	for _, ch := range item.ChannelsToProcess {
 		if channel, ok := i.ChannelMap[ch.ChannelName]; ok {
@@ -141,4 +175,7 @@ func (i *Informer) handleErr(item *eventWrapper, result error) {
 func (i *Informer) ShutDown() {
 	i.Queue.ShutDown()
 	close(i.StopCh)
+	if i.timerScheduler != nil {
+		i.timerScheduler.CancelPrefix(timerKeyPrefix(i.ID))
+	}
 }
@@ -4,19 +4,32 @@ import (
 	"github.com/pkg/errors"
 	spg "github.com/spongeprojects/client-go/api/spongeprojects.com/v1alpha1"
 	"github.com/spongeprojects/kubebigbrother/pkg/event"
+	"github.com/spongeprojects/kubebigbrother/pkg/redact"
 	"github.com/spongeprojects/kubebigbrother/pkg/utils"
+	"github.com/spongeprojects/kubebigbrother/pkg/utils/fieldpath"
+	"github.com/spongeprojects/kubebigbrother/pkg/utils/resourcebuilder"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
-	"reflect"
-	"strings"
 	"sync"
 )
 
-func (s *InformerSet) setupInformer(
-	namespace, informerName string, c spg.WatcherSpec) (*Informer, error) {
+// setupInformer sets up an informer of gvr for the watcher spec,
+// informers of a wildcard watcher share the same informerName but have different IDs.
+func (s *InformerSet) setupInformer(namespace, informerName string,
+	gvr schema.GroupVersionResource, c spg.WatcherSpec) (*Informer, error) {
+	id := informerName
+	if resourcebuilder.IsWildcard(c.Resource) {
+		id = informerName + "@" + gvr.GroupResource().String()
+	}
+
+	if err := validateMetadataOnly(c); err != nil {
+		return nil, err
+	}
+
 	channelNames := c.ChannelNames
 	if len(channelNames) == 0 {
 		channelNames = s.DefaultChannelNames
@@ -35,37 +48,151 @@ func (s *InformerSet) setupInformer(
 		workers = s.DefaultWorkers
 	}
 
+	ignoreFields := c.IgnoreFields
+	if len(ignoreFields) == 0 && c.SkipNoiseUpdates {
+		ignoreFields = DefaultIgnoreFields
+	}
+
+	updateFilter, err := newUpdateFilter(
+		c.UpdateOn, append(append([]string{}, ignoreFields...), c.IgnoreOn...))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid updateOn or ignoreOn")
+	}
+
 	maxRetries := c.MaxRetries
 	if maxRetries < 1 {
 		maxRetries = s.DefaultMaxRetries
 	}
 
-	gvr, err := s.ResourceBuilder.ParseGroupResource(c.Resource)
-	if err != nil {
-		return nil, errors.Wrapf(err, "invalid resource: %s", c.Resource)
+	var currentState *stateIndex
+	if !s.JustWatch && c.NoticeWhenAdded && c.Events == nil {
+		// preload in bulk, ADDED events are emitted for every resource
+		// when the informer starts, querying one by one is too slow.
+		currentlyAdded, err := s.EventStore.ListCurrentlyAdded(
+			s.Cluster, informerName, gvr.Group, gvr.Version, gvr.Resource)
+		if err != nil {
+			return nil, errors.Wrap(err, "list currently added resources error")
+		}
+		klog.V(2).Infof("[%s] %d resources currently added",
+			id, len(currentlyAdded))
+		currentState = newStateIndex(currentlyAdded)
 	}
 
-	saveSilently := func(e *event.Event) {
-		s.EventStore.SaveSilently(e.ToModel(informerName, gvr))
+	var trigger *trigger
+	var currentlyTriggered *stateIndex
+	if c.TriggerExpression != "" {
+		trigger, err = newTrigger(id, c.TriggerExpression, c.NoticeWhenResolved)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid trigger expression: %s", c.TriggerExpression)
+		}
+		if !s.JustWatch {
+			triggered, err := s.EventStore.ListCurrentlyTriggered(
+				s.Cluster, informerName, gvr.Group, gvr.Version, gvr.Resource)
+			if err != nil {
+				return nil, errors.Wrap(err, "list currently triggered resources error")
+			}
+			klog.V(2).Infof("[%s] %d resources currently triggered",
+				id, len(triggered))
+			currentlyTriggered = newStateIndex(triggered)
+		}
+	}
+
+	var kubeEventWatch *kubeEventWatch
+	var currentlyOccurred *stateIndex
+	if c.Events != nil {
+		kubeEventWatch, err = newKubeEventWatch(id, gvr, c.Events)
+		if err != nil {
+			return nil, err
+		}
+		if !s.JustWatch {
+			occurred, err := s.EventStore.ListCurrentlyOccurred(
+				s.Cluster, informerName, gvr.Group, gvr.Version, gvr.Resource)
+			if err != nil {
+				return nil, errors.Wrap(err, "list currently occurred events error")
+			}
+			klog.V(2).Infof("[%s] %d events noticed already",
+				id, len(occurred))
+			currentlyOccurred = newStateIndex(occurred)
+		}
+	}
+
+	var timers []*timerWatch
+	for _, timerConfig := range c.Timers {
+		timer, err := newTimerWatch(id, timerConfig)
+		if err != nil {
+			return nil, err
+		}
+		timers = append(timers, timer)
+	}
+
+	if _, err := retentionPolicyOf(c.Retention); err != nil {
+		return nil, err
+	}
+
+	ownerDepth := c.OwnerDepth
+	if ownerDepth == 0 {
+		ownerDepth = s.DefaultOwnerDepth
+	}
+
+	enricher := newEnricher(id, s.KubeClient, c.Enrich)
+
+	redactor, err := redact.New(c.RedactFields)
+	if err != nil {
+		return nil, err
 	}
 
 	rateLimiter := workqueue.DefaultControllerRateLimiter()
 	queue := workqueue.NewRateLimitingQueue(rateLimiter)
-	informerFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(
-		s.DynamicClient, resyncPeriod, namespace, nil)
-	resourceInformer := informerFactory.ForResource(gvr).Informer()
+
+	// emit records the event, and sends notifications if notify is true
+	emit := func(e *event.Event, notify bool) {
+		e.Cluster = s.Cluster
+		e.Actor = s.actorOf(e, gvr)
+		if ownerDepth > 0 && s.OwnerResolver != nil {
+			e.Owners = s.OwnerResolver.resolve(e.Obj, ownerDepth)
+		}
+		e.Redact(redactor)
+
+		klog.V(5).Infof("[%s] received: [%s] [%s]",
+			id, e.Type, e.GroupVersionKindName())
+
+		if !s.JustWatch {
+			s.EventStore.SaveSilently(e.ToModel(informerName, gvr))
+		}
+
+		if notify {
+			queue.Add(s.wrap(e, channelNames))
+		}
+	}
+	transform, err := newTransform(c.StripManagedFields, c.StripFields)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := labels.Parse(c.LabelSelector); err != nil {
+		return nil, errors.Wrapf(err, "invalid label selector: %s", c.LabelSelector)
+	}
+	resourceInformer, err := s.newResourceInformer(
+		gvr, namespace, c.LabelSelector, resyncPeriod, c.MetadataOnly, transform)
+	if err != nil {
+		return nil, errors.Wrap(err, "create resource informer error")
+	}
 
 	handlerFuncs := cache.ResourceEventHandlerFuncs{}
-	if !c.NoticeWhenAdded &&
+	if kubeEventWatch != nil {
+		// NoticeWhen* flags are ignored in events mode
+		resourceInformer.AddEventHandler(kubeEventWatch.handlerFuncs(currentlyOccurred, emit))
+	} else if !c.NoticeWhenAdded &&
 		!c.NoticeWhenDeleted &&
-		!c.NoticeWhenUpdated {
+		!c.NoticeWhenUpdated &&
+		trigger == nil &&
+		len(timers) == 0 {
 		// for some reason, informer won't start if they are all false,
 		// maybe someday someone can clarify why this happen.
 		return nil, errors.Errorf(
 			"NoticeWhenAdded, NoticeWhenDeleted and NoticeWhenUpdated " +
-				"cannot be false simultaneously")
+				"cannot be false simultaneously without TriggerExpression or Timers")
 	}
-	if c.NoticeWhenAdded {
+	if kubeEventWatch == nil && c.NoticeWhenAdded {
 		handlerFuncs.AddFunc = func(obj interface{}) {
 			st, ok := obj.(*unstructured.Unstructured)
 			if !ok {
@@ -73,107 +200,101 @@ func (s *InformerSet) setupInformer(
 			}
 			e := event.NewAdded(st)
 
-			if !s.JustWatch {
-				isCurrentlyAdded, err := s.EventStore.IsCurrentlyAdded(
-					informerName, gvr.Group, gvr.Version, gvr.Resource,
-					e.Obj.GetNamespace(), e.Obj.GetName())
-				if err != nil {
-					klog.Warning(errors.Wrap(err, "find latest record error"))
-				} else if isCurrentlyAdded {
-					klog.V(5).Infof(
-						"[%s] resource is currently added, skip ADDED event: [%s] [%s]",
-						informerName, e.Type, e.GroupVersionKindName())
-					return // ADDED event are emitted when controller restart
-				}
-			}
-
-			klog.V(5).Infof("[%s] received: [%s] [%s]",
-				informerName, e.Type, e.GroupVersionKindName())
-
-			if !s.JustWatch {
-				saveSilently(e)
+			if currentState != nil && currentState.pop(st) {
+				klog.V(5).Infof(
+					"[%s] resource is currently added, skip ADDED event: [%s] [%s]",
+					id, e.Type, e.GroupVersionKindName())
+				return // ADDED event are emitted when controller restart
 			}
 
-			queue.Add(s.wrap(e, channelNames))
+			emit(e, true)
 		}
 	}
-	if c.NoticeWhenDeleted {
-		handlerFuncs.DeleteFunc = func(obj interface{}) {
-			st, ok := obj.(*unstructured.Unstructured)
-			if !ok {
-				return
-			}
-			e := event.NewDeleted(st)
-
-			klog.V(5).Infof("[%s] received: [%s] [%s]",
-				informerName, e.Type, utils.GroupVersionKindName(st))
-
-			if !s.JustWatch {
-				saveSilently(e)
-			}
-
-			queue.Add(s.wrap(e, channelNames))
-		}
+	if kubeEventWatch == nil && c.NoticeWhenDeleted {
+		handlerFuncs.DeleteFunc = deleteFunc(id, currentState, emit)
 	}
-	if c.NoticeWhenUpdated {
+	if kubeEventWatch == nil && c.NoticeWhenUpdated {
 		handlerFuncs.UpdateFunc = func(oldObj, newObj interface{}) {
 			oldSt, ok1 := oldObj.(*unstructured.Unstructured)
 			st, ok2 := newObj.(*unstructured.Unstructured)
 			if !ok1 || !ok2 {
 				return
 			}
-			updated := false
-			for _, field := range c.UpdateOn {
-				fieldPath := strings.Split(strings.TrimPrefix(field, "."), ".")
-				f1, exist1, err1 := unstructured.NestedFieldNoCopy(
-					st.Object, fieldPath...)
-				f2, exist2, err2 := unstructured.NestedFieldNoCopy(
-					oldSt.Object, fieldPath...)
-				if !exist1 || !exist2 {
-					klog.Warningf("[%s] field not exist in resource: %s: %s",
-						informerName, utils.GroupVersionKindName(st), field)
-				}
-				if err1 != nil {
-					klog.Warningf("[%s] get field value error, resource: %s: %s",
-						informerName, utils.GroupVersionKindName(st), err1)
-				}
-				if err2 != nil {
-					klog.Warningf("[%s] get field value error, resource: %s: %s",
-						informerName, utils.GroupVersionKindName(st), err2)
-				}
-				if exist1 && exist2 && err1 == nil && err2 == nil &&
-					!reflect.DeepEqual(f1, f2) {
-					updated = true
-					break
-				}
+			if isResync(st, oldSt) {
+				return
+			}
+			matchedPaths := updateFilter.matchedPaths(st, oldSt)
+			if len(matchedPaths) == 0 {
+				klog.V(5).Infof("[%s] no field watched is changed, skip UPDATED event: [%s]",
+					id, utils.GroupVersionKindName(st))
+				return
 			}
-			if c.UpdateOn == nil || updated {
-				e := event.NewUpdated(st, oldSt)
-
-				klog.V(5).Infof("[%s] received: [%s] [%s]",
-					informerName, e.Type, utils.GroupVersionKindName(st))
 
-				if !s.JustWatch {
-					saveSilently(e)
-				}
+			e := event.NewUpdated(st, oldSt)
+			e.UpdatedFields = fieldpath.Strings(matchedPaths)
 
-				queue.Add(s.wrap(e, channelNames))
-			}
+			emit(e, true)
 		}
 	}
 	resourceInformer.AddEventHandler(handlerFuncs)
+	if trigger != nil {
+		resourceInformer.AddEventHandler(trigger.handlerFuncs(currentlyTriggered, emit))
+	}
+	for _, timer := range timers {
+		resourceInformer.AddEventHandler(timer.handlerFuncs(
+			s.TimerScheduler, resourceInformer.GetStore(), emit))
+	}
 
 	return &Informer{
-		ID:              informerName,
-		Resource:        c.Resource,
-		GVR:             gvr,
-		UpdateOn:        c.UpdateOn,
-		ChannelMap:      s.ChannelMap,
-		Informer:        resourceInformer,
-		Queue:           queue,
-		Workers:         workers,
-		MaxRetries:      maxRetries,
-		processingItems: &sync.WaitGroup{}, // TODO: wait before exit
-		StopCh:          make(chan struct{}),
+		ID:                id,
+		Resource:          c.Resource,
+		GVR:               gvr,
+		Spec:              c,
+		UpdateOn:          c.UpdateOn,
+		IgnoreOn:          c.IgnoreOn,
+		IgnoreFields:      ignoreFields,
+		TriggerExpression: c.TriggerExpression,
+		MetadataOnly:      c.MetadataOnly,
+		RedactFields:      c.RedactFields,
+		ChannelMap:        s.ChannelMap,
+		Informer:          resourceInformer,
+		Queue:             queue,
+		Workers:           workers,
+		MaxRetries:        maxRetries,
+		processingItems:   &sync.WaitGroup{}, // TODO: wait before exit
+		StopCh:            make(chan struct{}),
+		timerScheduler:    s.TimerScheduler,
+		enricher:          enricher,
+		stateIndexes:      nonNilStateIndexes(currentState, currentlyTriggered, currentlyOccurred),
 	}, nil
 }
+
+// deleteFunc builds the handler to emit DELETED events,
+// deletions missed during a watch disconnection arrive as tombstones, their final states are unknown.
+func deleteFunc(id string, currentState *stateIndex,
+	emit func(e *event.Event, notify bool)) func(obj interface{}) {
+	return func(obj interface{}) {
+		tombstone, finalStateUnknown := obj.(cache.DeletedFinalStateUnknown)
+		if finalStateUnknown {
+			obj = tombstone.Obj
+		}
+		st, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			klog.Warningf("[%s] unexpected object type in delete handler: %T",
+				id, obj)
+			return
+		}
+		var e *event.Event
+		if finalStateUnknown {
+			e = event.NewDeletedFinalStateUnknown(st)
+		} else {
+			e = event.NewDeleted(st)
+		}
+
+		if currentState != nil {
+			currentState.forget(st)
+		}
+
+		emit(e, true)
+	}
+}
@@ -0,0 +1,49 @@
+package informers
+
+import (
+	"github.com/spongeprojects/kubebigbrother/pkg/event"
+	"github.com/spongeprojects/kubebigbrother/pkg/gormdb"
+	"github.com/spongeprojects/kubebigbrother/pkg/stores/event_store"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+	"path/filepath"
+	"testing"
+)
+
+func TestDeleteFunc(t *testing.T) {
+	assertions := require.New(t)
+
+	db, err := gormdb.New("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	assertions.Nil(err)
+	store := event_store.New(db)
+
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	var emitted []*event.Event
+	deleted := deleteFunc("test", nil, func(e *event.Event, notify bool) {
+		assertions.True(notify)
+		emitted = append(emitted, e)
+		assertions.Nil(store.Save(e.ToModel("test", gvr)))
+	})
+
+	deleted(newDeployment("1", 1, 1))
+	deleted(cache.DeletedFinalStateUnknown{Key: "demo/canary", Obj: newDeployment("2", 1, 1)})
+	// unexpected objects in tombstones are ignored
+	deleted(cache.DeletedFinalStateUnknown{Key: "demo/canary", Obj: "unexpected"})
+
+	assertions.Len(emitted, 2)
+	assertions.Equal(event.Type(event.TypeDeleted), emitted[0].Type)
+	assertions.False(emitted[0].FinalStateUnknown)
+	assertions.Equal(event.Type(event.TypeDeleted), emitted[1].Type)
+	assertions.True(emitted[1].FinalStateUnknown)
+	assertions.Equal("2", emitted[1].Obj.GetResourceVersion())
+
+	stored, err := store.Find(2)
+	assertions.Nil(err)
+	assertions.Equal("DELETED", stored.EventType)
+	assertions.True(stored.FinalStateUnknown)
+	assertions.Equal("canary", stored.Name)
+	stored, err = store.Find(1)
+	assertions.Nil(err)
+	assertions.False(stored.FinalStateUnknown)
+}
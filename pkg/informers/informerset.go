@@ -1,15 +1,20 @@
 package informers
 
 import (
+	"github.com/pkg/errors"
 	spgl "github.com/spongeprojects/client-go/client/listers/spongeprojects.com/v1alpha1"
+	"github.com/spongeprojects/kubebigbrother/pkg/audit"
 	"github.com/spongeprojects/kubebigbrother/pkg/channels"
 	"github.com/spongeprojects/kubebigbrother/pkg/stores/event_store"
 	"github.com/spongeprojects/kubebigbrother/pkg/utils/resourcebuilder"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
+	"sync"
 	"time"
 )
 
@@ -23,6 +28,9 @@ type Interface interface {
 }
 
 type InformerSet struct {
+	// Cluster is the name of the cluster watched, recorded in every event
+	Cluster string
+
 	JustWatch  bool
 	EventStore event_store.Interface
 
@@ -30,6 +38,7 @@ type InformerSet struct {
 	DefaultMaxRetries       int
 	DefaultChannelNames     []string
 	DefaultResyncPeriodFunc ResyncPeriodFunc
+	DefaultOwnerDepth       int
 
 	// ChannelQueue is the queue for channel delta, item: channel name
 	ChannelQueue    workqueue.RateLimitingInterface
@@ -42,35 +51,76 @@ type InformerSet struct {
 	WatcherInformer cache.SharedIndexInformer
 	WatcherLister   spgl.WatcherLister
 
-	// WatcherMap maps from namespaced key to *Informer
-	WatcherMap map[string]*Informer
+	// WatcherMap maps from namespaced key to informers,
+	// a wildcard watcher has an informer for each resource matched
+	WatcherMap map[string][]*Informer
 
 	// ClusterWatcherQueue is the queue for channel delta, item: cluster watcher name
 	ClusterWatcherQueue    workqueue.RateLimitingInterface
 	ClusterWatcherInformer cache.SharedIndexInformer
 	ClusterWatcherLister   spgl.ClusterWatcherLister
 
-	// ClusterWatcherMap maps from namespaced key to *Informer
-	ClusterWatcherMap map[string]*Informer
+	// ClusterWatcherMap maps from namespaced key to informers,
+	// a wildcard watcher has an informer for each resource matched
+	ClusterWatcherMap map[string][]*Informer
+
+	// informersMu guards WatcherMap and ClusterWatcherMap
+	informersMu sync.Mutex
+
+	// CRDInformer watches CRDs to reconcile wildcard watchers,
+	// it's started on demand when the first wildcard watcher is processed
+	CRDInformer  cache.SharedIndexInformer
+	crdWatchOnce sync.Once
+	crdStopCh    chan struct{}
+
+	// crdRequeue coalesces requeues of wildcard watchers for bursts of CRD changes
+	crdRequeue *debouncer
+
+	// FileSource loads channels and watchers from files, nil when loading from CRDs only,
+	// informers of CRDs are nil when loading from files only.
+	FileSource *FileSource
+
+	// TimerScheduler schedules timers of all informers
+	TimerScheduler *TimerScheduler
 
 	ResourceBuilder resourcebuilder.Interface
+	KubeClient      kubernetes.Interface
 	DynamicClient   dynamic.Interface
+	MetadataClient  metadata.Interface
+
+	// AuditCache keeps recent audit records to attribute changes, nil when not receiving audit events
+	AuditCache *audit.Cache
+
+	// OwnerResolver resolves owner chains of resources in events
+	OwnerResolver *ownerResolver
 }
 
 func (s *InformerSet) Start(stopCh <-chan struct{}) error {
-	if !s.JustWatch {
-		go s.ChannelInformer.Run(stopCh)
+	if s.FileSource != nil {
+		if err := s.FileSource.Load(); err != nil {
+			return errors.Wrap(err, "load config files error")
+		}
+		go s.FileSource.Run(stopCh)
+	}
+
+	var informers []cache.SharedIndexInformer
+	for _, informer := range []cache.SharedIndexInformer{
+		s.ChannelInformer, s.WatcherInformer, s.ClusterWatcherInformer,
+	} {
+		if informer != nil {
+			informers = append(informers, informer)
+		}
+	}
+
+	for _, informer := range informers {
+		go informer.Run(stopCh)
 	}
-	go s.WatcherInformer.Run(stopCh)
-	go s.ClusterWatcherInformer.Run(stopCh)
 
 	klog.Info("waiting for caches to sync...")
 
-	if !s.JustWatch {
-		cache.WaitForCacheSync(stopCh, s.ChannelInformer.HasSynced)
+	for _, informer := range informers {
+		cache.WaitForCacheSync(stopCh, informer.HasSynced)
 	}
-	cache.WaitForCacheSync(stopCh, s.WatcherInformer.HasSynced)
-	cache.WaitForCacheSync(stopCh, s.ClusterWatcherInformer.HasSynced)
 
 	klog.Info("caches synced, starting workers...")
 
@@ -100,11 +150,21 @@ func (s *InformerSet) Shutdown() {
 	s.WatcherQueue.ShutDown()
 	s.ClusterWatcherQueue.ShutDown()
 
-	for _, informer := range s.WatcherMap {
-		informer.ShutDown()
+	close(s.crdStopCh)
+
+	s.informersMu.Lock()
+	for _, informers := range s.WatcherMap {
+		for _, informer := range informers {
+			informer.ShutDown()
+		}
 	}
 
-	for _, informer := range s.ClusterWatcherMap {
-		informer.ShutDown()
+	for _, informers := range s.ClusterWatcherMap {
+		for _, informer := range informers {
+			informer.ShutDown()
+		}
 	}
+	s.informersMu.Unlock()
+
+	s.TimerScheduler.Stop()
 }
@@ -0,0 +1,273 @@
+package informers
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	spg "github.com/spongeprojects/client-go/api/spongeprojects.com/v1alpha1"
+	"github.com/spongeprojects/kubebigbrother/pkg/utils/resourcebuilder"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// crdSettleDelay is the delay before wildcard watchers are reconciled after CRDs change,
+// API server takes a while to serve resources of a newly created CRD.
+const crdSettleDelay = 5 * time.Second
+
+// informerSyncTimeout bounds the wait for caches of informers started by a reconcile,
+// e.g. a resource the controller can't list, or a broken aggregated API, never syncs.
+const informerSyncTimeout = time.Minute
+
+var crdGVR = schema.GroupVersionResource{
+	Group:    "apiextensions.k8s.io",
+	Version:  "v1",
+	Resource: "customresourcedefinitions",
+}
+
+// resolveResources returns resources to watch for the watcher spec,
+// wildcards are expanded through discovery.
+func (s *InformerSet) resolveResources(
+	namespace string, spec spg.WatcherSpec) ([]schema.GroupVersionResource, error) {
+	if !resourcebuilder.IsWildcard(spec.Resource) {
+		gvr, err := s.ResourceBuilder.ParseGroupResource(spec.Resource)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid resource: %s", spec.Resource)
+		}
+		return []schema.GroupVersionResource{gvr}, nil
+	}
+
+	s.watchCRDs()
+
+	// cluster watchers watch all namespaces, cluster scoped resources are watched as well
+	namespacedOnly := namespace != ""
+	gvrs, err := s.ResourceBuilder.ExpandWildcard(spec.Resource, namespacedOnly, spec.ExcludeResources)
+	if err != nil {
+		return nil, errors.Wrapf(err, "expand wildcard error: %s", spec.Resource)
+	}
+	return gvrs, nil
+}
+
+// reconcileInformers makes informers of a watcher match its spec,
+// informers of resources no longer present are shut down, informers of new resources are started,
+// informers are recreated when the spec changed, the informers running are returned even on error.
+func (s *InformerSet) reconcileInformers(namespace, informerName string,
+	spec spg.WatcherSpec, current []*Informer) ([]*Informer, error) {
+	gvrs, err := s.resolveResources(namespace, spec)
+	if err != nil {
+		return current, err
+	}
+
+	wanted := make(map[schema.GroupVersionResource]bool, len(gvrs))
+	for _, gvr := range gvrs {
+		wanted[gvr] = true
+	}
+
+	var running []*Informer
+	existing := make(map[schema.GroupVersionResource]bool, len(current))
+	for _, informer := range current {
+		if !wanted[informer.GVR] {
+			klog.V(2).Infof("[%s] resource is gone, shutting down informer", informer.ID)
+			informer.ShutDown()
+			continue
+		}
+		if !reflect.DeepEqual(informer.Spec, spec) {
+			klog.V(2).Infof("[%s] watcher spec changed, recreating informer", informer.ID)
+			informer.ShutDown()
+			continue
+		}
+		existing[informer.GVR] = true
+		running = append(running, informer)
+	}
+
+	// resources failed are skipped, so that others are started, they are retried with the watcher
+	var es []string
+	var created []*Informer
+	for _, gvr := range gvrs {
+		if existing[gvr] {
+			continue
+		}
+		informer, err := s.setupInformer(namespace, informerName, gvr, spec)
+		if err != nil {
+			es = append(es, fmt.Sprintf("create informer error, resource: %s: %s", gvr, err))
+			continue
+		}
+		created = append(created, informer)
+	}
+	for _, result := range startInformers(created, informerSyncTimeout) {
+		if result.err != nil {
+			klog.Warningf("[%s] start informer error, skipped: %s", result.informer.ID, result.err)
+			es = append(es, fmt.Sprintf("start informer error, resource: %s: %s",
+				result.informer.GVR, result.err))
+			continue
+		}
+		klog.V(2).Infof("[%s] informer started", result.informer.ID)
+		running = append(running, result.informer)
+	}
+
+	if len(es) > 0 {
+		return running, errors.Errorf("%d of %d resources failed: %s",
+			len(es), len(gvrs), strings.Join(es, ","))
+	}
+	return running, nil
+}
+
+// startResult is the result of starting an informer
+type startResult struct {
+	informer *Informer
+	err      error
+}
+
+// startInformers starts informers and their workers, caches are waited concurrently for up to timeout,
+// informers not synced by then are shut down, results are in the order of informers.
+func startInformers(informers []*Informer, timeout time.Duration) []startResult {
+	results := make([]startResult, len(informers))
+	var wg sync.WaitGroup
+	for i, informer := range informers {
+		wg.Add(1)
+		go func(i int, informer *Informer) {
+			defer wg.Done()
+			results[i] = startResult{informer: informer, err: startInformer(informer, timeout)}
+		}(i, informer)
+	}
+	wg.Wait()
+	return results
+}
+
+// startInformer starts the informer and its workers, it blocks until cache synced,
+// the informer is shut down if its cache is not synced within timeout.
+func startInformer(informer *Informer, timeout time.Duration) error {
+	go informer.Informer.Run(informer.StopCh)
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	done := make(chan struct{})
+	defer close(done)
+	stopCh := make(chan struct{})
+	go func() {
+		defer close(stopCh)
+		select {
+		case <-timer.C:
+		case <-informer.StopCh:
+		case <-done:
+		}
+	}()
+	if !cache.WaitForCacheSync(stopCh, informer.Informer.HasSynced) {
+		informer.ShutDown()
+		return errors.Errorf("cache not synced in %s", timeout)
+	}
+
+	for _, index := range informer.stateIndexes {
+		if removed := index.retain(informer.Informer.GetStore()); removed > 0 {
+			klog.V(2).Infof("[%s] %d resources gone when we were away", informer.ID, removed)
+		}
+	}
+
+	for i := 0; i < informer.Workers; i++ {
+		go wait.Until(informer.RunWorker, time.Second, informer.StopCh)
+	}
+	return nil
+}
+
+// watchCRDs starts watching CustomResourceDefinitions on first call,
+// wildcard watchers are reconciled whenever CRDs are installed or removed.
+func (s *InformerSet) watchCRDs() {
+	s.crdWatchOnce.Do(func() {
+		informerFactory := dynamicinformer.NewDynamicSharedInformerFactory(s.DynamicClient, 0)
+		s.CRDInformer = informerFactory.ForResource(crdGVR).Informer()
+		s.CRDInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				if !s.CRDInformer.HasSynced() {
+					return // CRDs existing are discovered already when wildcard watchers are reconciled
+				}
+				s.requeueWildcardWatchers("crd added")
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				oldSt, ok1 := oldObj.(*unstructured.Unstructured)
+				st, ok2 := newObj.(*unstructured.Unstructured)
+				if !ok1 || !ok2 || oldSt.GetGeneration() == st.GetGeneration() {
+					return // versions served are changed only with spec
+				}
+				s.requeueWildcardWatchers("crd updated")
+			},
+			DeleteFunc: func(obj interface{}) {
+				s.requeueWildcardWatchers("crd deleted")
+			},
+		})
+		klog.V(2).Info("watching CRDs for wildcard watchers")
+		// cache is not waited, wildcard watchers are reconciled on every change anyway
+		go s.CRDInformer.Run(s.crdStopCh)
+	})
+}
+
+// requeueWildcardWatchers requeues all wildcard watchers so that they are reconciled after crdSettleDelay,
+// changes within the delay are coalesced, e.g. when CRDs of a chart are installed.
+func (s *InformerSet) requeueWildcardWatchers(reason string) {
+	s.crdRequeue.call(func() {
+		s.requeueWildcardWatchersNow(reason)
+	})
+}
+
+// requeueWildcardWatchersNow invalidates discovery and requeues all wildcard watchers
+func (s *InformerSet) requeueWildcardWatchersNow(reason string) {
+	s.ResourceBuilder.Invalidate()
+
+	watchers, err := s.WatcherLister.List(labels.Everything())
+	if err != nil {
+		klog.Warningf("[watcher] list watchers error: %s", err)
+	}
+	for _, watcher := range watchers {
+		if resourcebuilder.IsWildcard(watcher.Spec.Resource) {
+			k, _ := cache.MetaNamespaceKeyFunc(watcher)
+			klog.V(5).Infof("[watcher] %s, reconcile wildcard watcher: %s", reason, k)
+			s.WatcherQueue.Add(k)
+		}
+	}
+
+	clusterWatchers, err := s.ClusterWatcherLister.List(labels.Everything())
+	if err != nil {
+		klog.Warningf("[clusterwatcher] list cluster watchers error: %s", err)
+	}
+	for _, watcher := range clusterWatchers {
+		if resourcebuilder.IsWildcard(watcher.Spec.Resource) {
+			klog.V(5).Infof("[clusterwatcher] %s, reconcile wildcard cluster watcher: %s",
+				reason, watcher.Name)
+			s.ClusterWatcherQueue.Add(watcher.Name)
+		}
+	}
+}
+
+// debouncer calls a function once after the delay for all calls within the delay
+type debouncer struct {
+	delay time.Duration
+
+	mu      sync.Mutex
+	pending bool
+}
+
+func newDebouncer(delay time.Duration) *debouncer {
+	return &debouncer{delay: delay}
+}
+
+// call calls f after the delay, unless a call is pending, f of the pending call is called then
+func (d *debouncer) call(f func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.pending {
+		return
+	}
+	d.pending = true
+	time.AfterFunc(d.delay, func() {
+		d.mu.Lock()
+		d.pending = false
+		d.mu.Unlock()
+		f()
+	})
+}
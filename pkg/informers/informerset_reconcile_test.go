@@ -0,0 +1,78 @@
+package informers
+
+import (
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDebouncer(t *testing.T) {
+	assertions := require.New(t)
+
+	d := newDebouncer(20 * time.Millisecond)
+	var called int32
+	f := func() { atomic.AddInt32(&called, 1) }
+
+	for i := 0; i < 10; i++ {
+		d.call(f)
+	}
+	time.Sleep(60 * time.Millisecond)
+	assertions.Equal(int32(1), atomic.LoadInt32(&called))
+
+	// calls after the pending call are called again
+	d.call(f)
+	time.Sleep(60 * time.Millisecond)
+	assertions.Equal(int32(2), atomic.LoadInt32(&called))
+}
+
+func newTestInformer(id string, listFunc cache.ListFunc) *Informer {
+	lw := &cache.ListWatch{
+		ListFunc: listFunc,
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return watch.NewFake(), nil
+		},
+	}
+	return &Informer{
+		ID:       id,
+		Informer: cache.NewSharedIndexInformer(lw, &unstructured.Unstructured{}, 0, cache.Indexers{}),
+		Queue:    workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		StopCh:   make(chan struct{}),
+	}
+}
+
+func TestStartInformers(t *testing.T) {
+	assertions := require.New(t)
+
+	synced := newTestInformer("synced", func(options metav1.ListOptions) (runtime.Object, error) {
+		return &unstructured.UnstructuredList{}, nil
+	})
+	forbidden := newTestInformer("forbidden", func(options metav1.ListOptions) (runtime.Object, error) {
+		return nil, apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "", nil)
+	})
+	defer synced.ShutDown()
+
+	start := time.Now()
+	results := startInformers([]*Informer{forbidden, synced}, 200*time.Millisecond)
+	assertions.Less(int64(time.Since(start)), int64(time.Second))
+	assertions.Len(results, 2)
+	assertions.Equal(forbidden, results[0].informer)
+	assertions.NotNil(results[0].err)
+	assertions.Nil(results[1].err)
+	assertions.True(synced.Informer.HasSynced())
+
+	// informers not synced are shut down
+	select {
+	case <-forbidden.StopCh:
+	default:
+		assertions.Fail("informer not synced is running")
+	}
+}
@@ -5,14 +5,16 @@ import (
 	spg "github.com/spongeprojects/client-go/api/spongeprojects.com/v1alpha1"
 	spgc "github.com/spongeprojects/client-go/client/clientset/versioned"
 	spgi "github.com/spongeprojects/client-go/client/informers/externalversions"
-	spgl "github.com/spongeprojects/client-go/client/listers/spongeprojects.com/v1alpha1"
 	"github.com/spongeprojects/kubebigbrother/pkg/channels"
 	"github.com/spongeprojects/kubebigbrother/pkg/utils/resourcebuilder"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
+	"reflect"
 	"time"
 )
 
@@ -32,20 +34,33 @@ func Setup(config Config) (*InformerSet, error) {
 		defaultMaxRetries = 3
 	}
 	defaultChannelNames := config.DefaultChannelNames
+	defaultOwnerDepth := config.DefaultOwnerDepth
+	if defaultOwnerDepth == 0 {
+		defaultOwnerDepth = ownerDepthDefault
+	}
 	defaultResyncPeriodFunc := buildResyncPeriodFuncByDuration(config.MinResyncPeriod)
 
 	klog.V(1).Infof(
-		"default: workers: %d, max retries: %d, channel names: %s",
-		defaultWorkers, defaultMaxRetries, defaultChannelNames)
+		"default: workers: %d, max retries: %d, channel names: %s, owner depth: %d",
+		defaultWorkers, defaultMaxRetries, defaultChannelNames, defaultOwnerDepth)
+
+	restConfig := config.RESTConfig
+	if restConfig == nil {
+		var err error
+		restConfig, err = clientcmd.BuildConfigFromFlags("", config.Kubeconfig)
+		if err != nil {
+			return nil, errors.Wrap(err, "get kube config error")
+		}
+	}
 
-	restConfig, err := clientcmd.BuildConfigFromFlags("", config.Kubeconfig)
+	resourceBuilder, err := resourcebuilder.NewForConfig(restConfig)
 	if err != nil {
-		return nil, errors.Wrap(err, "get kube config error")
+		return nil, errors.Wrap(err, "resourcebuilder.NewForConfig error")
 	}
 
-	resourceBuilder, err := resourcebuilder.New(config.Kubeconfig)
+	kubeClient, err := kubernetes.NewForConfig(restConfig)
 	if err != nil {
-		return nil, errors.Wrap(err, "resourcebuilder.New error")
+		return nil, errors.Wrap(err, "create kubernetes client error")
 	}
 
 	dynamicClient, err := dynamic.NewForConfig(restConfig)
@@ -53,22 +68,24 @@ func Setup(config Config) (*InformerSet, error) {
 		return nil, errors.Wrap(err, "create dynamic client error")
 	}
 
-	spgClientset, err := spgc.NewForConfig(restConfig)
+	metadataClient, err := metadata.NewForConfig(restConfig)
 	if err != nil {
-		return nil, errors.Wrap(err, "new clientset error")
+		return nil, errors.Wrap(err, "create metadata client error")
 	}
 
-	spgInformerFactory := spgi.NewSharedInformerFactory(spgClientset, 12*time.Hour)
-
 	var channelMap channels.ChannelMap
 	var channelQueue workqueue.RateLimitingInterface
-	var channelInformer cache.SharedIndexInformer
-	var channelLister spgl.ChannelLister
 
 	if config.JustWatch {
-		printToStdout, _ := channels.NewChannelPrint(&spg.ChannelPrintConfig{
-			Writer: channels.PrintWriterStdout,
-		})
+		printConfig := config.PrintConfig
+		if printConfig == nil {
+			printConfig = &spg.ChannelPrintConfig{}
+		}
+		printConfig.Writer = channels.PrintWriterStdout
+		printToStdout, err := channels.NewChannelPrint(printConfig)
+		if err != nil {
+			return nil, errors.Wrap(err, "create print channel error")
+		}
 		channelMap = map[string]channels.Channel{
 			channelNamePrintToStdout: printToStdout,
 		}
@@ -77,44 +94,42 @@ func Setup(config Config) (*InformerSet, error) {
 
 		channelsRateLimiter := workqueue.DefaultControllerRateLimiter()
 		channelQueue = workqueue.NewRateLimitingQueue(channelsRateLimiter)
-		channelInformer = spgInformerFactory.Spongeprojects().V1alpha1().Channels().Informer()
-		channelLister = spgInformerFactory.Spongeprojects().V1alpha1().Channels().Lister()
-
-		channelInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-			AddFunc: func(obj interface{}) {
-				channel, ok := obj.(*spg.Channel)
-				if !ok {
-					return
-				}
-				klog.V(2).Infof("[channel] received: new channel of type %s: %s", channel.Spec.Type, channel.Name)
-				channelQueue.Add(channel.Name)
-			},
-			UpdateFunc: func(oldObj, newObj interface{}) {
-				_, ok1 := oldObj.(*spg.Channel)
-				channel, ok2 := newObj.(*spg.Channel)
-				if !ok1 || !ok2 {
-					return
-				}
-				klog.V(2).Infof("[channel] received: channel updated: %s", channel.Name)
-				channelQueue.Add(channel.Name)
-			},
-			DeleteFunc: func(obj interface{}) {
-				channel, ok := obj.(*spg.Channel)
-				if !ok {
-					return
-				}
-				klog.V(2).Infof("[channel] received: channel deleted: %s", channel.Name)
-				channelQueue.Add(channel.Name)
-			},
-		})
+	}
+
+	channelHandler := cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			channel, ok := obj.(*spg.Channel)
+			if !ok {
+				return
+			}
+			klog.V(2).Infof("[channel] received: new channel of type %s: %s", channel.Spec.Type, channel.Name)
+			channelQueue.Add(channel.Name)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			_, ok1 := oldObj.(*spg.Channel)
+			channel, ok2 := newObj.(*spg.Channel)
+			if !ok1 || !ok2 {
+				return
+			}
+			klog.V(2).Infof("[channel] received: channel updated: %s", channel.Name)
+			channelQueue.Add(channel.Name)
+		},
+		DeleteFunc: func(obj interface{}) {
+			// obj is a cache.DeletedFinalStateUnknown if the deletion was missed
+			k, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+			if err != nil {
+				klog.Warning(errors.Wrap(err, "[channel] get key of deleted channel error"))
+				return
+			}
+			klog.V(2).Infof("[channel] received: channel deleted: %s", k)
+			channelQueue.Add(k)
+		},
 	}
 
 	watcherRateLimiter := workqueue.DefaultControllerRateLimiter()
 	watcherQueue := workqueue.NewRateLimitingQueue(watcherRateLimiter)
-	watcherInformer := spgInformerFactory.Spongeprojects().V1alpha1().Watchers().Informer()
-	watcherLister := spgInformerFactory.Spongeprojects().V1alpha1().Watchers().Lister()
 
-	watcherInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+	watcherHandler := cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
 			watcher, ok := obj.(*spg.Watcher)
 			if !ok {
@@ -125,33 +140,31 @@ func Setup(config Config) (*InformerSet, error) {
 			watcherQueue.Add(k)
 		},
 		UpdateFunc: func(oldObj, newObj interface{}) {
-			// TODO: make it mutable
-			//_, ok1 := oldObj.(*spg.Watcher)
-			//watcher, ok2 := newObj.(*spg.Watcher)
-			//if !ok1 || !ok2 {
-			//	return
-			//}
-			//k, _ := cache.MetaNamespaceKeyFunc(watcher)
-			//klog.V(5).Infof("[watcher] received: watcher updated: %s", k)
-			//watcherQueue.Add(k)
+			oldWatcher, ok1 := oldObj.(*spg.Watcher)
+			watcher, ok2 := newObj.(*spg.Watcher)
+			if !ok1 || !ok2 || reflect.DeepEqual(oldWatcher.Spec, watcher.Spec) {
+				return
+			}
+			k, _ := cache.MetaNamespaceKeyFunc(watcher)
+			klog.V(2).Infof("[watcher] received: watcher updated: %s", k)
+			watcherQueue.Add(k)
 		},
 		DeleteFunc: func(obj interface{}) {
-			watcher, ok := obj.(*spg.Watcher)
-			if !ok {
+			// obj is a cache.DeletedFinalStateUnknown if the deletion was missed
+			k, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+			if err != nil {
+				klog.Warning(errors.Wrap(err, "[watcher] get key of deleted watcher error"))
 				return
 			}
-			k, _ := cache.MetaNamespaceKeyFunc(watcher)
 			klog.V(2).Infof("[watcher] received: watcher deleted: %s", k)
 			watcherQueue.Add(k)
 		},
-	})
+	}
 
 	clusterWatcherRateLimiter := workqueue.DefaultControllerRateLimiter()
 	clusterWatcherQueue := workqueue.NewRateLimitingQueue(clusterWatcherRateLimiter)
-	clusterWatcherInformer := spgInformerFactory.Spongeprojects().V1alpha1().ClusterWatchers().Informer()
-	clusterWatcherLister := spgInformerFactory.Spongeprojects().V1alpha1().ClusterWatchers().Lister()
 
-	clusterWatcherInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+	clusterWatcherHandler := cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
 			watcher, ok := obj.(*spg.ClusterWatcher)
 			if !ok {
@@ -162,45 +175,111 @@ func Setup(config Config) (*InformerSet, error) {
 			clusterWatcherQueue.Add(watcher.Name)
 		},
 		UpdateFunc: func(oldObj, newObj interface{}) {
-			// TODO: make it mutable
-			//_, ok1 := oldObj.(*spg.ClusterWatcher)
-			//watcher, ok2 := newObj.(*spg.ClusterWatcher)
-			//if !ok1 || !ok2 {
-			//	return
-			//}
-			//klog.V(5).Infof("[clusterwatcher] received: cluster watcher updated: %s", watcher.Name)
-			//clusterWatcherQueue.Add(watcher.Name)
+			oldWatcher, ok1 := oldObj.(*spg.ClusterWatcher)
+			watcher, ok2 := newObj.(*spg.ClusterWatcher)
+			if !ok1 || !ok2 || reflect.DeepEqual(oldWatcher.Spec, watcher.Spec) {
+				return
+			}
+			klog.V(2).Infof("[clusterwatcher] received: cluster watcher updated: %s", watcher.Name)
+			clusterWatcherQueue.Add(watcher.Name)
 		},
 		DeleteFunc: func(obj interface{}) {
-			watcher, ok := obj.(*spg.ClusterWatcher)
-			if !ok {
+			// obj is a cache.DeletedFinalStateUnknown if the deletion was missed
+			k, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+			if err != nil {
+				klog.Warning(errors.Wrap(err, "[clusterwatcher] get key of deleted cluster watcher error"))
 				return
 			}
-			klog.V(2).Infof("[clusterwatcher] received: cluster watcher deleted: %s", watcher.Name)
-			clusterWatcherQueue.Add(watcher.Name)
+			klog.V(2).Infof("[clusterwatcher] received: cluster watcher deleted: %s", k)
+			clusterWatcherQueue.Add(k)
 		},
-	})
+	}
+
+	var channelInformer, watcherInformer, clusterWatcherInformer cache.SharedIndexInformer
+	var channelListers mergedChannelLister
+	var watcherListers mergedWatcherLister
+	var clusterWatcherListers mergedClusterWatcherLister
+
+	configSource := config.ConfigSource
+	if configSource == "" {
+		configSource = ConfigSourceCRD
+	}
+
+	if configSource == ConfigSourceCRD || configSource == ConfigSourceBoth {
+		spgClientset, err := spgc.NewForConfig(restConfig)
+		if err != nil {
+			return nil, errors.Wrap(err, "new clientset error")
+		}
+
+		spgInformerFactory := spgi.NewSharedInformerFactory(spgClientset, 12*time.Hour)
+		spgInformers := spgInformerFactory.Spongeprojects().V1alpha1()
+
+		if !config.JustWatch {
+			channelInformer = spgInformers.Channels().Informer()
+			channelInformer.AddEventHandler(channelHandler)
+			channelListers = append(channelListers, spgInformers.Channels().Lister())
+		}
+
+		watcherInformer = spgInformers.Watchers().Informer()
+		watcherInformer.AddEventHandler(watcherHandler)
+		watcherListers = append(watcherListers, spgInformers.Watchers().Lister())
+
+		clusterWatcherInformer = spgInformers.ClusterWatchers().Informer()
+		clusterWatcherInformer.AddEventHandler(clusterWatcherHandler)
+		clusterWatcherListers = append(clusterWatcherListers, spgInformers.ClusterWatchers().Lister())
+	}
+
+	var fileSource *FileSource
+	switch configSource {
+	case ConfigSourceFile, ConfigSourceBoth:
+		fileSource = NewFileSource(config.ConfigPath)
+	case ConfigSourceStatic:
+		fileSource = NewStaticSource(config.StaticObjects...)
+	}
+	if fileSource != nil {
+
+		if !config.JustWatch {
+			fileSource.AddChannelHandler(channelHandler)
+			channelListers = append(channelListers, fileSource.ChannelLister())
+		}
+
+		fileSource.AddWatcherHandler(watcherHandler)
+		watcherListers = append(watcherListers, fileSource.WatcherLister())
+
+		fileSource.AddClusterWatcherHandler(clusterWatcherHandler)
+		clusterWatcherListers = append(clusterWatcherListers, fileSource.ClusterWatcherLister())
+	}
 
 	return &InformerSet{
+		Cluster:                 config.Cluster,
 		JustWatch:               config.JustWatch,
 		EventStore:              config.EventStore,
 		DefaultWorkers:          defaultWorkers,
 		DefaultMaxRetries:       defaultMaxRetries,
 		DefaultChannelNames:     defaultChannelNames,
 		DefaultResyncPeriodFunc: defaultResyncPeriodFunc,
+		DefaultOwnerDepth:       defaultOwnerDepth,
 		ChannelQueue:            channelQueue,
 		ChannelInformer:         channelInformer,
-		ChannelLister:           channelLister,
+		ChannelLister:           channelListers,
 		ChannelMap:              channelMap,
 		WatcherQueue:            watcherQueue,
 		WatcherInformer:         watcherInformer,
-		WatcherLister:           watcherLister,
-		WatcherMap:              make(map[string]*Informer),
+		WatcherLister:           watcherListers,
+		WatcherMap:              make(map[string][]*Informer),
 		ClusterWatcherQueue:     clusterWatcherQueue,
 		ClusterWatcherInformer:  clusterWatcherInformer,
-		ClusterWatcherLister:    clusterWatcherLister,
-		ClusterWatcherMap:       make(map[string]*Informer),
+		ClusterWatcherLister:    clusterWatcherListers,
+		FileSource:              fileSource,
+		ClusterWatcherMap:       make(map[string][]*Informer),
+		TimerScheduler:          NewTimerScheduler(),
+		crdStopCh:               make(chan struct{}),
+		crdRequeue:              newDebouncer(crdSettleDelay),
 		ResourceBuilder:         resourceBuilder,
+		KubeClient:              kubeClient,
 		DynamicClient:           dynamicClient,
+		MetadataClient:          metadataClient,
+		AuditCache:              config.AuditCache,
+		OwnerResolver:           newOwnerResolver(resourceBuilder, metadataClient),
 	}, nil
 }
@@ -0,0 +1,128 @@
+package informers
+
+import (
+	"github.com/pkg/errors"
+	spg "github.com/spongeprojects/client-go/api/spongeprojects.com/v1alpha1"
+	"github.com/spongeprojects/kubebigbrother/pkg/event"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"strings"
+)
+
+// kubeEventWatch watches Kubernetes Events in events mode,
+// Events are filtered and noticed as OCCURRED events.
+type kubeEventWatch struct {
+	informerID    string
+	types         []string
+	reasons       []string
+	kinds         []string
+	namespaces    []string
+	minCount      int64
+	noticeRepeats bool
+}
+
+func newKubeEventWatch(informerID string,
+	gvr schema.GroupVersionResource, c *spg.WatcherEvents) (*kubeEventWatch, error) {
+	if gvr.Resource != "events" || (gvr.Group != "" && gvr.Group != "events.k8s.io") {
+		return nil, errors.Errorf("events mode requires resource events, got: %s", gvr.GroupResource())
+	}
+	minCount := c.MinCount
+	if minCount < 1 {
+		minCount = 1
+	}
+	return &kubeEventWatch{
+		informerID:    informerID,
+		types:         c.Types,
+		reasons:       c.Reasons,
+		kinds:         c.InvolvedObjectKinds,
+		namespaces:    c.InvolvedObjectNamespaces,
+		minCount:      minCount,
+		noticeRepeats: c.NoticeRepeats,
+	}, nil
+}
+
+// match checks whether the Event passes filters, empty filters match everything
+func (w *kubeEventWatch) match(e *event.KubeEvent) bool {
+	return matchAny(w.types, e.Type, strings.EqualFold) &&
+		matchAny(w.reasons, e.Reason, func(a, b string) bool { return a == b }) &&
+		matchAny(w.kinds, e.InvolvedObject.Kind, strings.EqualFold) &&
+		matchAny(w.namespaces, e.InvolvedObject.Namespace, func(a, b string) bool { return a == b })
+}
+
+func matchAny(filters []string, value string, equal func(a, b string) bool) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	for _, filter := range filters {
+		if equal(filter, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// shouldNotice checks whether the count change of an Event should be noticed,
+// the Event is noticed once when its count reaches minCount,
+// and every time it occurs again afterwards if noticeRepeats is set.
+func (w *kubeEventWatch) shouldNotice(oldCount, count int64) bool {
+	if count < w.minCount || count <= oldCount {
+		return false
+	}
+	if oldCount < w.minCount {
+		return true
+	}
+	return w.noticeRepeats
+}
+
+// handlerFuncs builds handlers to emit OCCURRED events,
+// currentlyOccurred is used to continue from the state before the controller restart,
+// Events unchanged since they were noticed are skipped in the initial list.
+func (w *kubeEventWatch) handlerFuncs(currentlyOccurred *stateIndex,
+	emit func(e *event.Event, notify bool)) cache.ResourceEventHandlerFuncs {
+	handle := func(st *unstructured.Unstructured, oldCount int64) {
+		kubeEvent, ok := event.ParseKubeEvent(st)
+		if !ok {
+			return
+		}
+		if !w.match(kubeEvent) || !w.shouldNotice(oldCount, kubeEvent.Count) {
+			return
+		}
+		emit(event.NewOccurred(st, kubeEvent), true)
+	}
+
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			st, ok := obj.(*unstructured.Unstructured)
+			if !ok {
+				return
+			}
+			var oldCount int64
+			if currentlyOccurred != nil {
+				if recorded, ok := currentlyOccurred.popRecord(st); ok {
+					if recorded.ResourceVersion == st.GetResourceVersion() {
+						klog.V(5).Infof("[%s] event is noticed already, skip: %s",
+							w.informerID, stateIndexKey(st.GetNamespace(), st.GetName()))
+						return
+					}
+					// it has occurred again when we were away, count is unknown
+					oldCount = w.minCount
+				}
+			}
+			handle(st, oldCount)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldSt, ok1 := oldObj.(*unstructured.Unstructured)
+			st, ok2 := newObj.(*unstructured.Unstructured)
+			if !ok1 || !ok2 || isResync(st, oldSt) {
+				return
+			}
+			var oldCount int64
+			if oldKubeEvent, ok := event.ParseKubeEvent(oldSt); ok {
+				oldCount = oldKubeEvent.Count
+			}
+			handle(st, oldCount)
+		},
+	}
+}
@@ -0,0 +1,72 @@
+package informers
+
+import (
+	spg "github.com/spongeprojects/client-go/api/spongeprojects.com/v1alpha1"
+	"github.com/spongeprojects/kubebigbrother/pkg/event"
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"testing"
+)
+
+var eventsGVR = schema.GroupVersionResource{Version: "v1", Resource: "events"}
+
+func newKubeEvent(resourceVersion, eventType, reason string, count int64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Event",
+		"metadata": map[string]interface{}{
+			"namespace":       "demo",
+			"name":            "canary.16a8c7d5e6f7",
+			"uid":             "e1",
+			"resourceVersion": resourceVersion,
+		},
+		"involvedObject": map[string]interface{}{
+			"kind":      "Pod",
+			"namespace": "demo",
+			"name":      "canary",
+		},
+		"type":    eventType,
+		"reason":  reason,
+		"message": "Back-off restarting failed container",
+		"count":   count,
+	}}
+}
+
+func TestKubeEventWatch(t *testing.T) {
+	assertions := require.New(t)
+
+	_, err := newKubeEventWatch("test",
+		schema.GroupVersionResource{Version: "v1", Resource: "pods"}, &spg.WatcherEvents{})
+	assertions.NotNil(err)
+
+	w, err := newKubeEventWatch("test", eventsGVR, &spg.WatcherEvents{
+		Types:    []string{"warning"},
+		MinCount: 2,
+	})
+	assertions.Nil(err)
+
+	var counts []int64
+	handlers := w.handlerFuncs(nil, func(e *event.Event, notify bool) {
+		assertions.Equal(event.Type(event.TypeOccurred), e.Type)
+		counts = append(counts, e.KubeEvent.Count)
+	})
+	handlers.OnAdd(newKubeEvent("1", "Normal", "Pulled", 5))
+	handlers.OnAdd(newKubeEvent("1", "Warning", "BackOff", 1))
+	handlers.OnUpdate(newKubeEvent("1", "Warning", "BackOff", 1), newKubeEvent("2", "Warning", "BackOff", 2))
+	handlers.OnUpdate(newKubeEvent("2", "Warning", "BackOff", 2), newKubeEvent("3", "Warning", "BackOff", 3))
+	handlers.OnUpdate(newKubeEvent("3", "Warning", "BackOff", 3), newKubeEvent("3", "Warning", "BackOff", 3))
+	assertions.Equal([]int64{2}, counts)
+
+	w.noticeRepeats = true
+	counts = nil
+	handlers = w.handlerFuncs(newStateIndex([]models.Event{
+		{Namespace: "demo", Name: "canary.16a8c7d5e6f7", UID: "e1", ResourceVersion: "3"},
+	}), func(e *event.Event, notify bool) {
+		counts = append(counts, e.KubeEvent.Count)
+	})
+	handlers.OnAdd(newKubeEvent("3", "Warning", "BackOff", 3))
+	handlers.OnUpdate(newKubeEvent("3", "Warning", "BackOff", 3), newKubeEvent("4", "Warning", "BackOff", 4))
+	assertions.Equal([]int64{4}, counts)
+}
@@ -0,0 +1,104 @@
+package informers
+
+import (
+	spg "github.com/spongeprojects/client-go/api/spongeprojects.com/v1alpha1"
+	spgl "github.com/spongeprojects/client-go/client/listers/spongeprojects.com/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// listers are merged when channels and watchers are loaded from both CRDs and files,
+// Get returns the object from the first lister it is found in, CRDs take precedence.
+
+type mergedChannelLister []spgl.ChannelLister
+
+func (l mergedChannelLister) List(selector labels.Selector) (ret []*spg.Channel, err error) {
+	for _, lister := range l {
+		items, err := lister.List(selector)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, items...)
+	}
+	return ret, nil
+}
+
+func (l mergedChannelLister) Get(name string) (channel *spg.Channel, err error) {
+	err = apierrors.NewNotFound(spg.Resource("channel"), name)
+	for _, lister := range l {
+		channel, err = lister.Get(name)
+		if err == nil || !apierrors.IsNotFound(err) {
+			return
+		}
+	}
+	return
+}
+
+type mergedWatcherLister []spgl.WatcherLister
+
+func (l mergedWatcherLister) List(selector labels.Selector) (ret []*spg.Watcher, err error) {
+	for _, lister := range l {
+		items, err := lister.List(selector)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, items...)
+	}
+	return ret, nil
+}
+
+func (l mergedWatcherLister) Watchers(namespace string) spgl.WatcherNamespaceLister {
+	var namespaceListers mergedWatcherNamespaceLister
+	for _, lister := range l {
+		namespaceListers = append(namespaceListers, lister.Watchers(namespace))
+	}
+	return namespaceListers
+}
+
+type mergedWatcherNamespaceLister []spgl.WatcherNamespaceLister
+
+func (l mergedWatcherNamespaceLister) List(selector labels.Selector) (ret []*spg.Watcher, err error) {
+	for _, lister := range l {
+		items, err := lister.List(selector)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, items...)
+	}
+	return ret, nil
+}
+
+func (l mergedWatcherNamespaceLister) Get(name string) (watcher *spg.Watcher, err error) {
+	err = apierrors.NewNotFound(spg.Resource("watcher"), name)
+	for _, lister := range l {
+		watcher, err = lister.Get(name)
+		if err == nil || !apierrors.IsNotFound(err) {
+			return
+		}
+	}
+	return
+}
+
+type mergedClusterWatcherLister []spgl.ClusterWatcherLister
+
+func (l mergedClusterWatcherLister) List(selector labels.Selector) (ret []*spg.ClusterWatcher, err error) {
+	for _, lister := range l {
+		items, err := lister.List(selector)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, items...)
+	}
+	return ret, nil
+}
+
+func (l mergedClusterWatcherLister) Get(name string) (watcher *spg.ClusterWatcher, err error) {
+	err = apierrors.NewNotFound(spg.Resource("clusterwatcher"), name)
+	for _, lister := range l {
+		watcher, err = lister.Get(name)
+		if err == nil || !apierrors.IsNotFound(err) {
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,161 @@
+package informers
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/spongeprojects/kubebigbrother/pkg/event"
+	"github.com/spongeprojects/kubebigbrother/pkg/utils/resourcebuilder"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/client-go/metadata"
+	"k8s.io/klog/v2"
+	"time"
+)
+
+const (
+	// defaultOwnerDepth is enough for Pod -> ReplicaSet -> Deployment and Pod -> Job -> CronJob
+	ownerDepthDefault = 3
+
+	ownerCacheSize = 4096
+
+	// ownerCacheTTL bounds how stale a cached owner can be,
+	// owner references are rarely changed once set
+	ownerCacheTTL = 10 * time.Minute
+
+	// ownerErrorTTL is how long a failed lookup is cached, e.g. when the owner kind is forbidden,
+	// so that it's not repeated for every event of every child
+	ownerErrorTTL = time.Minute
+
+	// ownerGetTimeout bounds a lookup of an owner, as the informer handler is blocked
+	ownerGetTimeout = 2 * time.Second
+)
+
+// ownerResolver resolves owner chains through the metadata client,
+// owner references of owners are cached by UID, owners not found and failed lookups are cached too.
+type ownerResolver struct {
+	resourceBuilder resourcebuilder.Interface
+	metadataClient  metadata.Interface
+	cache           *utilcache.LRUExpireCache
+}
+
+func newOwnerResolver(
+	resourceBuilder resourcebuilder.Interface, metadataClient metadata.Interface) *ownerResolver {
+	return &ownerResolver{
+		resourceBuilder: resourceBuilder,
+		metadataClient:  metadataClient,
+		cache:           utilcache.NewLRUExpireCache(ownerCacheSize),
+	}
+}
+
+// resolve returns up to depth owners of obj, nearest first,
+// resolving stops at the first owner which cannot be found.
+func (r *ownerResolver) resolve(obj *unstructured.Unstructured, depth int) []event.Owner {
+	if obj == nil {
+		return nil
+	}
+	namespace := obj.GetNamespace()
+	refs := obj.GetOwnerReferences()
+
+	var owners []event.Owner
+	for len(owners) < depth {
+		ref := controllerOf(refs)
+		if ref == nil {
+			break
+		}
+		owners = append(owners, event.Owner{
+			APIVersion: ref.APIVersion,
+			Kind:       ref.Kind,
+			Name:       ref.Name,
+			UID:        string(ref.UID),
+		})
+		if len(owners) == depth {
+			break // owners of the last one are not needed
+		}
+
+		var err error
+		refs, err = r.ownerReferencesOf(namespace, ref)
+		if err != nil {
+			klog.V(2).Infof("[owners] resolve owner error: %s/%s: %s", ref.Kind, ref.Name, err)
+			break
+		}
+	}
+	return owners
+}
+
+// ownerLookup is a cached result of ownerReferencesOf
+type ownerLookup struct {
+	refs []metav1.OwnerReference
+	err  error
+}
+
+// ownerReferencesOf gets owner references of the owner, cached by UID of the owner
+func (r *ownerResolver) ownerReferencesOf(
+	namespace string, ref *metav1.OwnerReference) ([]metav1.OwnerReference, error) {
+	if cached, ok := r.cache.Get(ref.UID); ok {
+		lookup := cached.(ownerLookup)
+		return lookup.refs, lookup.err
+	}
+
+	refs, err := r.getOwnerReferences(namespace, ref)
+	if err != nil {
+		r.cache.Add(ref.UID, ownerLookup{err: err}, ownerErrorTTL)
+		return nil, err
+	}
+	r.cache.Add(ref.UID, ownerLookup{refs: refs}, ownerCacheTTL)
+	return refs, nil
+}
+
+// getOwnerReferences gets owner references of the owner through the metadata client,
+// owners are in the namespace of the object they own, unless they are cluster scoped.
+func (r *ownerResolver) getOwnerReferences(
+	namespace string, ref *metav1.OwnerReference) ([]metav1.OwnerReference, error) {
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid apiVersion: %s", ref.APIVersion)
+	}
+	mapping, err := r.resourceBuilder.RESTMappingFor(gv.WithKind(ref.Kind))
+	if err != nil {
+		return nil, errors.Wrap(err, "get rest mapping error")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ownerGetTimeout)
+	defer cancel()
+
+	var owner *metav1.PartialObjectMetadata
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		owner, err = r.metadataClient.Resource(mapping.Resource).Namespace(namespace).
+			Get(ctx, ref.Name, metav1.GetOptions{})
+	} else {
+		owner, err = r.metadataClient.Resource(mapping.Resource).
+			Get(ctx, ref.Name, metav1.GetOptions{})
+	}
+	var refs []metav1.OwnerReference
+	switch {
+	case apierrors.IsNotFound(err):
+		// deleted already, e.g. by cascading deletion
+	case err != nil:
+		return nil, errors.Wrap(err, "get owner error")
+	case owner.GetUID() != ref.UID:
+		// recreated with the same name, it's not the owner
+	default:
+		refs = owner.GetOwnerReferences()
+	}
+	return refs, nil
+}
+
+// controllerOf returns the controller reference, the first reference when there is no controller
+func controllerOf(refs []metav1.OwnerReference) *metav1.OwnerReference {
+	for i := range refs {
+		if refs[i].Controller != nil && *refs[i].Controller {
+			return &refs[i]
+		}
+	}
+	if len(refs) > 0 {
+		return &refs[0]
+	}
+	return nil
+}
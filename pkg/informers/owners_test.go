@@ -0,0 +1,131 @@
+package informers
+
+import (
+	"context"
+	"github.com/spongeprojects/kubebigbrother/pkg/event"
+	"github.com/spongeprojects/kubebigbrother/pkg/utils/resourcebuilder"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	metadatafake "k8s.io/client-go/metadata/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"testing"
+)
+
+// fakeResourceBuilder maps kinds with a static RESTMapper
+type fakeResourceBuilder struct {
+	resourcebuilder.Interface
+	mapper meta.RESTMapper
+}
+
+func (b *fakeResourceBuilder) RESTMappingFor(gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+	return b.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+}
+
+func newOwnerReference(apiVersion, kind, name, uid string) metav1.OwnerReference {
+	controller := true
+	return metav1.OwnerReference{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Name:       name,
+		UID:        types.UID(uid),
+		Controller: &controller,
+	}
+}
+
+func newPartialObjectMetadata(apiVersion, kind, namespace, name, uid string,
+	refs ...metav1.OwnerReference) *metav1.PartialObjectMetadata {
+	return &metav1.PartialObjectMetadata{
+		TypeMeta: metav1.TypeMeta{APIVersion: apiVersion, Kind: kind},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       namespace,
+			Name:            name,
+			UID:             types.UID(uid),
+			OwnerReferences: refs,
+		},
+	}
+}
+
+func TestOwnerResolver(t *testing.T) {
+	assertions := require.New(t)
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "ReplicaSet"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+
+	scheme := runtime.NewScheme()
+	assertions.Nil(metav1.AddMetaToScheme(scheme))
+	metadataClient := metadatafake.NewSimpleMetadataClient(scheme,
+		newPartialObjectMetadata("apps/v1", "ReplicaSet", "demo", "canary-5d4b7c", "rs",
+			newOwnerReference("apps/v1", "Deployment", "canary", "deploy")),
+		newPartialObjectMetadata("apps/v1", "Deployment", "demo", "canary", "deploy"),
+	)
+	resolver := newOwnerResolver(&fakeResourceBuilder{mapper: mapper}, metadataClient)
+
+	pod := &unstructured.Unstructured{}
+	pod.SetAPIVersion("v1")
+	pod.SetKind("Pod")
+	pod.SetNamespace("demo")
+	pod.SetName("canary-5d4b7c-x2k9p")
+	pod.SetOwnerReferences([]metav1.OwnerReference{
+		newOwnerReference("apps/v1", "ReplicaSet", "canary-5d4b7c", "rs"),
+	})
+
+	owners := resolver.resolve(pod, 3)
+	assertions.Equal([]event.Owner{
+		{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "canary-5d4b7c", UID: "rs"},
+		{APIVersion: "apps/v1", Kind: "Deployment", Name: "canary", UID: "deploy"},
+	}, owners)
+	assertions.Equal("ReplicaSet/canary-5d4b7c,Deployment/canary", event.OwnerChain(owners))
+
+	owners = resolver.resolve(pod, 1)
+	assertions.Len(owners, 1)
+
+	// owners are cached, the chain is still resolved after the ReplicaSet is deleted
+	assertions.Nil(metadataClient.
+		Resource(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "replicasets"}).
+		Namespace("demo").Delete(context.TODO(), "canary-5d4b7c", metav1.DeleteOptions{}))
+	assertions.Len(resolver.resolve(pod, 3), 2)
+
+	// owners recreated with the same name are not followed
+	pod.SetOwnerReferences([]metav1.OwnerReference{
+		newOwnerReference("apps/v1", "Deployment", "canary", "another"),
+	})
+	assertions.Len(resolver.resolve(pod, 3), 1)
+}
+
+func TestOwnerResolverError(t *testing.T) {
+	assertions := require.New(t)
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "ReplicaSet"}, meta.RESTScopeNamespace)
+
+	scheme := runtime.NewScheme()
+	assertions.Nil(metav1.AddMetaToScheme(scheme))
+	metadataClient := metadatafake.NewSimpleMetadataClient(scheme)
+	gets := 0
+	metadataClient.PrependReactor("get", "replicasets",
+		func(action clienttesting.Action) (bool, runtime.Object, error) {
+			gets++
+			return true, nil, apierrors.NewForbidden(
+				schema.GroupResource{Group: "apps", Resource: "replicasets"}, "canary-5d4b7c", nil)
+		})
+	resolver := newOwnerResolver(&fakeResourceBuilder{mapper: mapper}, metadataClient)
+
+	pod := &unstructured.Unstructured{}
+	pod.SetNamespace("demo")
+	pod.SetOwnerReferences([]metav1.OwnerReference{
+		newOwnerReference("apps/v1", "ReplicaSet", "canary-5d4b7c", "rs"),
+	})
+
+	// the nearest owner is known from the reference, failed lookups are cached
+	for i := 0; i < 3; i++ {
+		assertions.Len(resolver.resolve(pod, 3), 1)
+	}
+	assertions.Equal(1, gets)
+}
@@ -0,0 +1,53 @@
+package informers
+
+import (
+	"github.com/pkg/errors"
+	spg "github.com/spongeprojects/client-go/api/spongeprojects.com/v1alpha1"
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	"github.com/spongeprojects/kubebigbrother/pkg/stores/event_store"
+	"k8s.io/client-go/tools/cache"
+	"time"
+)
+
+// retentionPolicyOf converts retention of a watcher, zero values fall back to the global policy
+func retentionPolicyOf(r *spg.WatcherRetention) (event_store.RetentionPolicy, error) {
+	var policy event_store.RetentionPolicy
+	if r == nil {
+		return policy, nil
+	}
+	if r.MaxAge != "" {
+		maxAge, err := time.ParseDuration(r.MaxAge)
+		if err != nil {
+			return policy, errors.Wrapf(err, "invalid max age: %s", r.MaxAge)
+		}
+		policy.MaxAge = maxAge
+	}
+	policy.MaxEvents = r.MaxEvents
+	policy.MaxVersions = r.MaxVersions
+	return policy, nil
+}
+
+// RetentionPolicies returns retention policies of running watchers by informer name,
+// they override the global retention policy.
+func (s *InformerSet) RetentionPolicies() map[string]event_store.RetentionPolicy {
+	s.informersMu.Lock()
+	defer s.informersMu.Unlock()
+
+	policies := make(map[string]event_store.RetentionPolicy)
+	add := func(informerName string, informers []*Informer) {
+		if len(informers) == 0 || informers[0].Spec.Retention == nil {
+			return
+		}
+		// validated when the informer is set up
+		policy, _ := retentionPolicyOf(informers[0].Spec.Retention)
+		policies[informerName] = policy
+	}
+	for key, informers := range s.WatcherMap {
+		namespace, name, _ := cache.SplitMetaNamespaceKey(key)
+		add(models.WatcherInformerName(namespace, name), informers)
+	}
+	for name, informers := range s.ClusterWatcherMap {
+		add(models.ClusterWatcherInformerName(name), informers)
+	}
+	return policies
+}
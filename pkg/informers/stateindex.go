@@ -0,0 +1,94 @@
+package informers
+
+import (
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+	"sync"
+)
+
+// stateIndex is an in-memory index of resources currently added,
+// it is preloaded in bulk from the event store before the informer starts,
+// so that the ADDED events emitted by the initial list can be deduplicated
+// without querying the event store for every single resource.
+type stateIndex struct {
+	mu    sync.Mutex
+	items map[string]models.Event
+}
+
+func newStateIndex(events []models.Event) *stateIndex {
+	items := make(map[string]models.Event, len(events))
+	for _, e := range events {
+		items[stateIndexKey(e.Namespace, e.Name)] = e
+	}
+	return &stateIndex{
+		items: items,
+	}
+}
+
+func stateIndexKey(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "/" + name
+}
+
+// pop checks whether the resource is currently added and removes it from the index,
+// every resource is expected to be checked once in the initial list,
+// ADDED events received afterwards are always real ones.
+func (i *stateIndex) pop(obj *unstructured.Unstructured) (isCurrentlyAdded bool) {
+	_, ok := i.popRecord(obj)
+	return ok
+}
+
+// popRecord removes the resource from the index and returns the event recorded for it,
+// events recorded by old versions have no UID, they are regarded as the same resource,
+// a different UID means the resource was recreated when we were away.
+func (i *stateIndex) popRecord(obj *unstructured.Unstructured) (models.Event, bool) {
+	key := stateIndexKey(obj.GetNamespace(), obj.GetName())
+
+	i.mu.Lock()
+	e, ok := i.items[key]
+	delete(i.items, key)
+	i.mu.Unlock()
+
+	if !ok || (e.UID != "" && e.UID != string(obj.GetUID())) {
+		return models.Event{}, false
+	}
+	return e, true
+}
+
+// forget removes the resource from the index
+func (i *stateIndex) forget(obj *unstructured.Unstructured) {
+	key := stateIndexKey(obj.GetNamespace(), obj.GetName())
+
+	i.mu.Lock()
+	delete(i.items, key)
+	i.mu.Unlock()
+}
+
+// retain removes resources not in the store of the synced informer, e.g. deleted when we were away,
+// they would never be popped, resources left are popped when their ADDED events are handled,
+// so the index is empty once the initial list is handled.
+func (i *stateIndex) retain(store cache.Store) (removed int) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for key := range i.items {
+		if _, exist, err := store.GetByKey(key); err == nil && !exist {
+			delete(i.items, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// nonNilStateIndexes returns indexes which are not nil
+func nonNilStateIndexes(indexes ...*stateIndex) []*stateIndex {
+	var result []*stateIndex
+	for _, index := range indexes {
+		if index != nil {
+			result = append(result, index)
+		}
+	}
+	return result
+}
@@ -0,0 +1,68 @@
+package informers
+
+import (
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"testing"
+)
+
+func newConfigMap(namespace, name, uid string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+	}}
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetUID(types.UID(uid))
+	return obj
+}
+
+func TestStateIndex(t *testing.T) {
+	assertions := require.New(t)
+
+	index := newStateIndex([]models.Event{
+		{EventType: "ADDED", Namespace: "demo", Name: "a", UID: "u1"},
+		{EventType: "ADDED", Namespace: "demo", Name: "b", UID: "u2"},
+		{EventType: "ADDED", Namespace: "demo", Name: "c", UID: "u3"},
+		// recorded by old versions without UID
+		{EventType: "ADDED", Name: "cluster-scoped"},
+	})
+
+	assertions.True(index.pop(newConfigMap("demo", "a", "u1")))
+	// popped once, ADDED events received afterwards are real ones
+	assertions.False(index.pop(newConfigMap("demo", "a", "u1")))
+	// recreated when we were away
+	assertions.False(index.pop(newConfigMap("demo", "b", "u4")))
+	assertions.False(index.pop(newConfigMap("demo", "b", "u2")))
+	assertions.True(index.pop(newConfigMap("", "cluster-scoped", "u5")))
+
+	index.forget(newConfigMap("demo", "c", "u3"))
+	assertions.False(index.pop(newConfigMap("demo", "c", "u3")))
+	assertions.Empty(index.items)
+}
+
+func TestStateIndexRetain(t *testing.T) {
+	assertions := require.New(t)
+
+	index := newStateIndex([]models.Event{
+		{EventType: "ADDED", Namespace: "demo", Name: "a", UID: "u1"},
+		{EventType: "ADDED", Namespace: "demo", Name: "gone", UID: "u2"},
+		{EventType: "ADDED", Name: "cluster-scoped", UID: "u3"},
+	})
+
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	assertions.Nil(store.Add(newConfigMap("demo", "a", "u1")))
+	assertions.Nil(store.Add(newConfigMap("", "cluster-scoped", "u3")))
+
+	// deleted when we were away, never popped
+	assertions.Equal(1, index.retain(store))
+	assertions.Len(index.items, 2)
+
+	// ADDED events of the initial list are still to be handled
+	assertions.True(index.pop(newConfigMap("demo", "a", "u1")))
+	assertions.True(index.pop(newConfigMap("", "cluster-scoped", "u3")))
+	assertions.Empty(index.items)
+}
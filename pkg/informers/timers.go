@@ -0,0 +1,202 @@
+package informers
+
+import (
+	"github.com/google/cel-go/cel"
+	"github.com/pkg/errors"
+	spg "github.com/spongeprojects/client-go/api/spongeprojects.com/v1alpha1"
+	"github.com/spongeprojects/kubebigbrother/pkg/event"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TimerScheduler schedules timers keyed by string,
+// timers are tracked in memory, they start over when the controller restart.
+type TimerScheduler struct {
+	mu     sync.Mutex
+	timers map[string]*time.Timer
+}
+
+// NewTimerScheduler creates TimerScheduler
+func NewTimerScheduler() *TimerScheduler {
+	return &TimerScheduler{
+		timers: make(map[string]*time.Timer),
+	}
+}
+
+// Schedule calls f after d, does nothing if the key is already scheduled
+func (s *TimerScheduler) Schedule(key string, d time.Duration, f func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.timers[key]; ok {
+		return
+	}
+	s.timers[key] = time.AfterFunc(d, func() {
+		s.mu.Lock()
+		_, ok := s.timers[key]
+		delete(s.timers, key)
+		s.mu.Unlock()
+		if ok { // cancelled otherwise
+			f()
+		}
+	})
+}
+
+// Cancel cancels the timer of the key, if any
+func (s *TimerScheduler) Cancel(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if timer, ok := s.timers[key]; ok {
+		timer.Stop()
+		delete(s.timers, key)
+	}
+}
+
+// CancelPrefix cancels all timers whose key has the prefix
+func (s *TimerScheduler) CancelPrefix(prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, timer := range s.timers {
+		if strings.HasPrefix(key, prefix) {
+			timer.Stop()
+			delete(s.timers, key)
+		}
+	}
+}
+
+// Stop cancels all timers
+func (s *TimerScheduler) Stop() {
+	s.CancelPrefix("")
+}
+
+// timerWatch fires when the expression holds continuously for the duration,
+// once for every time the expression becomes true.
+type timerWatch struct {
+	informerID string
+	name       string
+	expression string
+	program    cel.Program
+	duration   time.Duration
+
+	// fired are keys of timers fired, they are re-armed when the expression becomes false
+	mu    sync.Mutex
+	fired map[string]bool
+}
+
+func newTimerWatch(informerID string, c spg.WatcherTimer) (*timerWatch, error) {
+	if c.Name == "" {
+		return nil, errors.New("name of timer cannot be empty")
+	}
+	duration, err := time.ParseDuration(c.Duration)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid duration of timer %s: %s", c.Name, c.Duration)
+	}
+	program, err := compileExpression(c.Expression)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid expression of timer %s: %s", c.Name, c.Expression)
+	}
+	return &timerWatch{
+		informerID: informerID,
+		name:       c.Name,
+		expression: c.Expression,
+		program:    program,
+		duration:   duration,
+		fired:      make(map[string]bool),
+	}, nil
+}
+
+// timerKeyPrefix is the key prefix of all timers of an informer
+func timerKeyPrefix(informerID string) string {
+	return informerID + "/"
+}
+
+// key is the key of the timer for obj,
+// UID is used so that a recreated object never inherits the timer.
+func (w *timerWatch) key(obj *unstructured.Unstructured) string {
+	return timerKeyPrefix(w.informerID) + w.name + "/" + string(obj.GetUID())
+}
+
+// setFired marks whether the timer of the key has fired, returns whether it had fired
+func (w *timerWatch) setFired(key string, fired bool) (hadFired bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	hadFired = w.fired[key]
+	if fired {
+		w.fired[key] = true
+	} else {
+		delete(w.fired, key)
+	}
+	return hadFired
+}
+
+// hasFired returns whether the timer of the key has fired and is not re-armed yet
+func (w *timerWatch) hasFired(key string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.fired[key]
+}
+
+// handlerFuncs builds handlers to schedule timers when the expression becomes true,
+// and to cancel them when the object recovers or is deleted,
+// the latest object in store is used when the timer fires.
+// A timer fires once while the object is stuck, it's re-armed when the expression becomes false.
+func (w *timerWatch) handlerFuncs(scheduler *TimerScheduler, store cache.Store,
+	emit func(e *event.Event, notify bool)) cache.ResourceEventHandlerFuncs {
+	check := func(obj *unstructured.Unstructured) {
+		key := w.key(obj)
+		if !evalExpression(w.informerID, w.program, obj) {
+			scheduler.Cancel(key)
+			w.setFired(key, false)
+			return
+		}
+		if w.hasFired(key) {
+			return // still stuck, noticed already
+		}
+		scheduler.Schedule(key, w.duration, func() {
+			latest, exist, err := store.Get(obj)
+			if err != nil || !exist {
+				return
+			}
+			st, ok := latest.(*unstructured.Unstructured)
+			if !ok || st.GetUID() != obj.GetUID() {
+				return
+			}
+			if !evalExpression(w.informerID, w.program, st) {
+				return
+			}
+			if w.setFired(key, true) {
+				return
+			}
+			klog.V(5).Infof("[%s] timer fired: %s: %s", w.informerID, w.name, key)
+			emit(event.NewStuck(st, w.name, w.expression, w.duration), true)
+		})
+	}
+
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if st, ok := obj.(*unstructured.Unstructured); ok {
+				check(st)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldSt, ok1 := oldObj.(*unstructured.Unstructured)
+			st, ok2 := newObj.(*unstructured.Unstructured)
+			if !ok1 || !ok2 || isResync(st, oldSt) {
+				return
+			}
+			check(st)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if st, ok := obj.(*unstructured.Unstructured); ok {
+				scheduler.Cancel(w.key(st))
+				w.setFired(w.key(st), false)
+			}
+		},
+	}
+}
@@ -0,0 +1,82 @@
+package informers
+
+import (
+	spg "github.com/spongeprojects/client-go/api/spongeprojects.com/v1alpha1"
+	"github.com/spongeprojects/kubebigbrother/pkg/event"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTimerScheduler(t *testing.T) {
+	assertions := require.New(t)
+
+	scheduler := NewTimerScheduler()
+	defer scheduler.Stop()
+
+	var fired int32
+	f := func() { atomic.AddInt32(&fired, 1) }
+
+	scheduler.Schedule("a/1", 10*time.Millisecond, f)
+	scheduler.Schedule("a/1", 10*time.Millisecond, f) // already scheduled
+	scheduler.Schedule("a/2", 10*time.Millisecond, f)
+	scheduler.Schedule("b/1", 10*time.Millisecond, f)
+	scheduler.Cancel("a/2")
+	scheduler.CancelPrefix("b/")
+
+	time.Sleep(50 * time.Millisecond)
+	assertions.Equal(int32(1), atomic.LoadInt32(&fired))
+}
+
+func TestTimerWatch(t *testing.T) {
+	assertions := require.New(t)
+
+	scheduler := NewTimerScheduler()
+	defer scheduler.Stop()
+
+	w, err := newTimerWatch("test", spg.WatcherTimer{Name: "unavailable",
+		Expression: "object.status.readyReplicas < object.spec.replicas", Duration: "10ms"})
+	assertions.Nil(err)
+
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	var fired int32
+	handlers := w.handlerFuncs(scheduler, store, func(e *event.Event, notify bool) {
+		assertions.Equal(event.Type(event.TypeStuck), e.Type)
+		atomic.AddInt32(&fired, 1)
+	})
+	// update updates the cache before handlers are called, as informers do
+	update := func(oldObj, obj *unstructured.Unstructured) {
+		assertions.Nil(store.Update(obj))
+		handlers.OnUpdate(oldObj, obj)
+	}
+
+	stuck := newDeployment("1", 2, 1)
+	assertions.Nil(store.Add(stuck))
+	handlers.OnAdd(stuck)
+	time.Sleep(50 * time.Millisecond)
+	assertions.Equal(int32(1), atomic.LoadInt32(&fired))
+
+	// still stuck, fired once only, whether updated or resynced
+	stillStuck := newDeployment("2", 3, 1)
+	update(stuck, stillStuck)
+	update(stillStuck, stillStuck)
+	time.Sleep(50 * time.Millisecond)
+	assertions.Equal(int32(1), atomic.LoadInt32(&fired))
+
+	// recovered, the timer is re-armed, and cancelled when recovered before it fires
+	recovered := newDeployment("3", 3, 3)
+	update(stillStuck, recovered)
+	stuckAgain := newDeployment("4", 3, 2)
+	update(recovered, stuckAgain)
+	update(stuckAgain, newDeployment("5", 3, 3))
+	time.Sleep(50 * time.Millisecond)
+	assertions.Equal(int32(1), atomic.LoadInt32(&fired))
+
+	// stuck again after recovered, fired again
+	update(recovered, stuckAgain)
+	time.Sleep(50 * time.Millisecond)
+	assertions.Equal(int32(2), atomic.LoadInt32(&fired))
+}
@@ -0,0 +1,167 @@
+package informers
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	spg "github.com/spongeprojects/client-go/api/spongeprojects.com/v1alpha1"
+	"github.com/spongeprojects/kubebigbrother/pkg/utils/fieldpath"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"time"
+)
+
+// transformFunc transforms objects in place before they are cached
+type transformFunc func(obj *unstructured.Unstructured)
+
+// newTransform builds transformFunc to strip fields, nil if nothing to strip,
+// when managedFields are stripped, the manager and time of the latest entry are kept,
+// so that the actor falls back to the field manager without audit records.
+func newTransform(stripManagedFields bool, stripFields []string) (transformFunc, error) {
+	patterns, err := fieldpath.ParseAll(stripFields)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid stripFields")
+	}
+	if !stripManagedFields && len(patterns) == 0 {
+		return nil, nil
+	}
+	return func(obj *unstructured.Unstructured) {
+		latest := latestManagedFieldsEntry(obj)
+		if stripManagedFields {
+			obj.SetManagedFields(nil)
+		}
+		for _, p := range patterns {
+			p.Remove(obj.Object)
+		}
+		if latest != nil && len(obj.GetManagedFields()) == 0 {
+			obj.SetManagedFields([]metav1.ManagedFieldsEntry{{
+				Manager:    latest.Manager,
+				Operation:  latest.Operation,
+				APIVersion: latest.APIVersion,
+				Time:       latest.Time,
+			}})
+		}
+	}, nil
+}
+
+// validateMetadataOnly returns error if the spec needs more than metadata of objects with metadataOnly,
+// only ADDED and DELETED are noticed from metadata, other options would never match.
+func validateMetadataOnly(c spg.WatcherSpec) error {
+	if !c.MetadataOnly {
+		return nil
+	}
+	switch {
+	case c.Events != nil:
+		return errors.New("metadataOnly is not allowed in events mode")
+	case c.NoticeWhenUpdated:
+		return errors.New("metadataOnly is not allowed with noticeWhenUpdated")
+	case len(c.UpdateOn) > 0 || len(c.IgnoreOn) > 0:
+		return errors.New("metadataOnly is not allowed with updateOn or ignoreOn")
+	case c.TriggerExpression != "":
+		return errors.New("metadataOnly is not allowed with triggerExpression")
+	case len(c.Timers) > 0:
+		return errors.New("metadataOnly is not allowed with timers")
+	}
+	return nil
+}
+
+// newResourceInformer creates an informer of gvr in namespace, all namespaces if empty,
+// objects are selected by labelSelector, all objects if empty,
+// objects are transformed before they reach the cache, so that fields stripped never take memory.
+// metadata only informers cache objects with metadata only, converted to unstructured of kind.
+func (s *InformerSet) newResourceInformer(gvr schema.GroupVersionResource, namespace, labelSelector string,
+	resyncPeriod time.Duration, metadataOnly bool, transform transformFunc) (cache.SharedIndexInformer, error) {
+	if transform == nil {
+		transform = func(obj *unstructured.Unstructured) {}
+	}
+	tweak := func(options *metav1.ListOptions) {
+		options.LabelSelector = labelSelector
+	}
+
+	var lw *cache.ListWatch
+	if metadataOnly {
+		kind, err := s.ResourceBuilder.KindFor(gvr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "get kind error, resource: %s", gvr)
+		}
+		client := s.MetadataClient.Resource(gvr).Namespace(namespace)
+		lw = &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				tweak(&options)
+				list, err := client.List(context.TODO(), options)
+				if err != nil {
+					return nil, err
+				}
+				st := &unstructured.UnstructuredList{}
+				st.SetResourceVersion(list.ResourceVersion)
+				st.SetContinue(list.Continue)
+				st.Items = make([]unstructured.Unstructured, 0, len(list.Items))
+				for i := range list.Items {
+					item := metadataToUnstructured(&list.Items[i], kind)
+					transform(item)
+					st.Items = append(st.Items, *item)
+				}
+				return st, nil
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				tweak(&options)
+				w, err := client.Watch(context.TODO(), options)
+				if err != nil {
+					return nil, err
+				}
+				return watch.Filter(w, func(in watch.Event) (watch.Event, bool) {
+					if m, ok := in.Object.(*metav1.PartialObjectMetadata); ok {
+						item := metadataToUnstructured(m, kind)
+						transform(item)
+						in.Object = item
+					}
+					return in, true
+				}), nil
+			},
+		}
+	} else {
+		client := s.DynamicClient.Resource(gvr).Namespace(namespace)
+		lw = &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				tweak(&options)
+				list, err := client.List(context.TODO(), options)
+				if err != nil {
+					return nil, err
+				}
+				for i := range list.Items {
+					transform(&list.Items[i])
+				}
+				return list, nil
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				tweak(&options)
+				w, err := client.Watch(context.TODO(), options)
+				if err != nil {
+					return nil, err
+				}
+				return watch.Filter(w, func(in watch.Event) (watch.Event, bool) {
+					if st, ok := in.Object.(*unstructured.Unstructured); ok && in.Type != watch.Error {
+						transform(st)
+					}
+					return in, true
+				}), nil
+			},
+		}
+	}
+
+	return cache.NewSharedIndexInformer(lw, &unstructured.Unstructured{}, resyncPeriod,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}), nil
+}
+
+// metadataToUnstructured converts metadata of an object to unstructured of kind
+func metadataToUnstructured(m *metav1.PartialObjectMetadata, kind schema.GroupVersionKind) *unstructured.Unstructured {
+	st := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	st.SetGroupVersionKind(kind)
+	if metadata, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&m.ObjectMeta); err == nil {
+		st.Object["metadata"] = metadata
+	}
+	return st
+}
@@ -0,0 +1,102 @@
+package informers
+
+import (
+	spg "github.com/spongeprojects/client-go/api/spongeprojects.com/v1alpha1"
+	"github.com/spongeprojects/kubebigbrother/pkg/event"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"testing"
+	"time"
+)
+
+func TestTransform(t *testing.T) {
+	assertions := require.New(t)
+
+	transform, err := newTransform(false, nil)
+	assertions.Nil(err)
+	assertions.Nil(transform)
+
+	_, err = newTransform(false, []string{".spec..replicas"})
+	assertions.NotNil(err)
+
+	transform, err = newTransform(true, []string{".status"})
+	assertions.Nil(err)
+
+	obj := newDeployment("1", 1, 1)
+	now := metav1.NewTime(time.Now())
+	obj.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{Manager: "kube-controller-manager", Operation: metav1.ManagedFieldsOperationUpdate,
+			FieldsType: "FieldsV1", FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:status":{}}`)}},
+		{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationApply, Time: &now,
+			FieldsType: "FieldsV1", FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{}}`)}},
+	})
+	transform(obj)
+	assertions.NotContains(obj.Object, "status")
+	assertions.Contains(obj.Object, "spec")
+	// the latest manager is kept for the actor
+	managedFields := obj.GetManagedFields()
+	assertions.Len(managedFields, 1)
+	assertions.Equal("kubectl", managedFields[0].Manager)
+	assertions.Nil(managedFields[0].FieldsV1)
+	assertions.Equal(&event.Actor{Manager: "kubectl"},
+		(&InformerSet{}).actorOf(event.NewUpdated(obj, nil), deploymentsGVR))
+
+	// managedFields stripped by stripFields are handled the same
+	transform, err = newTransform(false, []string{".metadata.managedFields"})
+	assertions.Nil(err)
+	obj = newDeployment("1", 1, 1)
+	obj.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "kubectl", FieldsType: "FieldsV1",
+		FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{}}`)}}})
+	transform(obj)
+	assertions.Equal("kubectl", latestManager(obj))
+	assertions.Nil(obj.GetManagedFields()[0].FieldsV1)
+
+	// objects without managedFields are unchanged
+	obj = newDeployment("1", 1, 1)
+	transform(obj)
+	assertions.Nil(obj.GetManagedFields())
+}
+
+func TestMetadataToUnstructured(t *testing.T) {
+	assertions := require.New(t)
+
+	st := metadataToUnstructured(&metav1.PartialObjectMetadata{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "demo",
+			Name:            "canary",
+			ResourceVersion: "1",
+		},
+	}, schema.GroupVersionKind{Version: "v1", Kind: "Secret"})
+	assertions.Equal("v1", st.GetAPIVersion())
+	assertions.Equal("Secret", st.GetKind())
+	assertions.Equal("demo", st.GetNamespace())
+	assertions.Equal("canary", st.GetName())
+	assertions.Equal("1", st.GetResourceVersion())
+}
+
+func TestValidateMetadataOnly(t *testing.T) {
+	assertions := require.New(t)
+
+	assertions.Nil(validateMetadataOnly(spg.WatcherSpec{
+		MetadataOnly: true, NoticeWhenAdded: true, NoticeWhenDeleted: true}))
+	// options are allowed without metadataOnly
+	assertions.Nil(validateMetadataOnly(spg.WatcherSpec{NoticeWhenUpdated: true, UpdateOn: []string{".spec"}}))
+
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	for _, spec := range []spg.WatcherSpec{
+		{Events: &spg.WatcherEvents{}},
+		{NoticeWhenUpdated: true},
+		{NoticeWhenAdded: true, UpdateOn: []string{".status.phase"}},
+		{NoticeWhenAdded: true, IgnoreOn: []string{".status"}},
+		{TriggerExpression: `object.status.phase == "Failed"`},
+		{Timers: []spg.WatcherTimer{{Name: "pending", Expression: `object.status.phase == "Pending"`,
+			Duration: "10m"}}},
+	} {
+		spec.Resource = "pods"
+		spec.MetadataOnly = true
+		_, err := (&InformerSet{}).setupInformer("", "w1", gvr, spec)
+		assertions.NotNil(err, "%+v", spec)
+		assertions.Contains(err.Error(), "metadataOnly is not allowed")
+	}
+}
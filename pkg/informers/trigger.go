@@ -0,0 +1,125 @@
+package informers
+
+import (
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"github.com/pkg/errors"
+	"github.com/spongeprojects/kubebigbrother/pkg/event"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// trigger evaluates a CEL expression on objects,
+// events are emitted on transitions of the result.
+type trigger struct {
+	informerName       string
+	expression         string
+	program            cel.Program
+	noticeWhenResolved bool
+}
+
+func newTrigger(informerName, expression string, noticeWhenResolved bool) (*trigger, error) {
+	program, err := compileExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	return &trigger{
+		informerName:       informerName,
+		expression:         expression,
+		program:            program,
+		noticeWhenResolved: noticeWhenResolved,
+	}, nil
+}
+
+// compileExpression compiles a CEL expression returning bool,
+// the object is accessible as "object" in the expression.
+func compileExpression(expression string) (cel.Program, error) {
+	env, err := cel.NewEnv(cel.Declarations(
+		decls.NewVar("object", decls.Dyn),
+	))
+	if err != nil {
+		return nil, errors.Wrap(err, "create CEL environment error")
+	}
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, errors.Wrap(issues.Err(), "compile expression error")
+	}
+	if t := ast.ResultType(); t.GetPrimitive() != decls.Bool.GetPrimitive() && t.GetDyn() == nil {
+		return nil, errors.Errorf("expression should return bool, got: %s", t)
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, errors.Wrap(err, "build CEL program error")
+	}
+	return program, nil
+}
+
+// evalExpression evaluates a compiled expression on obj,
+// errors, e.g. field not exist, are regarded as false.
+func evalExpression(informerName string,
+	program cel.Program, obj *unstructured.Unstructured) bool {
+	out, _, err := program.Eval(map[string]interface{}{
+		"object": obj.Object,
+	})
+	if err != nil {
+		klog.V(5).Infof("[%s] evaluate expression error, regarded as false: %s: %s",
+			informerName, obj.GetName(), err)
+		return false
+	}
+	result, ok := out.Value().(bool)
+	return ok && result
+}
+
+// eval evaluates the expression on obj
+func (t *trigger) eval(obj *unstructured.Unstructured) bool {
+	return evalExpression(t.informerName, t.program, obj)
+}
+
+// handlerFuncs builds handlers to emit TRIGGERED and RESOLVED events,
+// currentlyTriggered is used to continue from the state before the controller restart,
+// RESOLVED events are always recorded to track the state, notify decides whether to notice.
+func (t *trigger) handlerFuncs(currentlyTriggered *stateIndex,
+	emit func(e *event.Event, notify bool)) cache.ResourceEventHandlerFuncs {
+	transit := func(obj *unstructured.Unstructured, was, is bool) {
+		switch {
+		case !was && is:
+			emit(event.NewTriggered(obj, t.expression), true)
+		case was && !is:
+			emit(event.NewResolved(obj, t.expression), t.noticeWhenResolved)
+		}
+	}
+
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			st, ok := obj.(*unstructured.Unstructured)
+			if !ok {
+				return
+			}
+			was := currentlyTriggered != nil && currentlyTriggered.pop(st)
+			transit(st, was, t.eval(st))
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldSt, ok1 := oldObj.(*unstructured.Unstructured)
+			st, ok2 := newObj.(*unstructured.Unstructured)
+			if !ok1 || !ok2 || isResync(st, oldSt) {
+				return
+			}
+			transit(st, t.eval(oldSt), t.eval(st))
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			st, ok := obj.(*unstructured.Unstructured)
+			if !ok {
+				return
+			}
+			if currentlyTriggered != nil {
+				currentlyTriggered.forget(st)
+			}
+			// the object is gone, so is the problem
+			transit(st, t.eval(st), false)
+		},
+	}
+}
@@ -0,0 +1,41 @@
+package informers
+
+import (
+	"github.com/spongeprojects/kubebigbrother/pkg/event"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestTrigger(t *testing.T) {
+	assertions := require.New(t)
+
+	_, err := newTrigger("test", "object.spec.replicas +", false)
+	assertions.NotNil(err)
+
+	_, err = newTrigger("test", "1 + 1", false)
+	assertions.NotNil(err)
+
+	trigger, err := newTrigger("test",
+		"object.status.readyReplicas < object.spec.replicas", false)
+	assertions.Nil(err)
+
+	healthy := newDeployment("1", 1, 1)
+	unhealthy := newDeployment("2", 2, 1)
+	assertions.False(trigger.eval(healthy))
+	assertions.True(trigger.eval(unhealthy))
+
+	var emitted []event.Type
+	var notified []event.Type
+	handlers := trigger.handlerFuncs(nil, func(e *event.Event, notify bool) {
+		emitted = append(emitted, e.Type)
+		if notify {
+			notified = append(notified, e.Type)
+		}
+	})
+	handlers.OnAdd(healthy)
+	handlers.OnUpdate(healthy, unhealthy)
+	handlers.OnUpdate(unhealthy, unhealthy)
+	handlers.OnUpdate(unhealthy, healthy)
+	assertions.Equal([]event.Type{event.TypeTriggered, event.TypeResolved}, emitted)
+	assertions.Equal([]event.Type{event.TypeTriggered}, notified)
+}
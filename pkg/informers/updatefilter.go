@@ -0,0 +1,62 @@
+package informers
+
+import (
+	"github.com/spongeprojects/kubebigbrother/pkg/utils/fieldpath"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// DefaultIgnoreFields are fields ignored by default when SkipNoiseUpdates is set,
+// changes on these fields are made by the system, not by users.
+var DefaultIgnoreFields = []string{
+	".metadata.managedFields",
+	".metadata.resourceVersion",
+	".metadata.generation",
+	".status",
+}
+
+// isResync checks whether an update is generated by resync,
+// resourceVersion is unchanged when nothing happened.
+func isResync(obj, oldObj *unstructured.Unstructured) bool {
+	return obj.GetResourceVersion() != "" &&
+		obj.GetResourceVersion() == oldObj.GetResourceVersion()
+}
+
+// updateFilter decides whether an update should be noticed
+type updateFilter struct {
+	// updateOn matches fields to watch, all fields are watched when empty
+	updateOn []*fieldpath.Pattern
+
+	// ignore matches fields to ignore, takes precedence over updateOn
+	ignore []*fieldpath.Pattern
+}
+
+func newUpdateFilter(updateOn, ignore []string) (*updateFilter, error) {
+	updateOnPatterns, err := fieldpath.ParseAll(updateOn)
+	if err != nil {
+		return nil, err
+	}
+	ignorePatterns, err := fieldpath.ParseAll(ignore)
+	if err != nil {
+		return nil, err
+	}
+	return &updateFilter{
+		updateOn: updateOnPatterns,
+		ignore:   ignorePatterns,
+	}, nil
+}
+
+// matchedPaths returns paths of changed fields which should be noticed,
+// nothing should be noticed when it's empty.
+func (f *updateFilter) matchedPaths(obj, oldObj *unstructured.Unstructured) []fieldpath.Path {
+	var matched []fieldpath.Path
+	for _, path := range fieldpath.Diff(oldObj.Object, obj.Object) {
+		if fieldpath.MatchAny(f.ignore, path) {
+			continue
+		}
+		if len(f.updateOn) > 0 && !fieldpath.MatchAny(f.updateOn, path) {
+			continue
+		}
+		matched = append(matched, path)
+	}
+	return matched
+}
@@ -0,0 +1,53 @@
+package informers
+
+import (
+	"github.com/spongeprojects/kubebigbrother/pkg/utils/fieldpath"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+)
+
+func newDeployment(resourceVersion string, replicas int64, readyReplicas int64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"namespace":       "demo",
+			"name":            "canary",
+			"resourceVersion": resourceVersion,
+		},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+		},
+		"status": map[string]interface{}{
+			"readyReplicas": readyReplicas,
+		},
+	}}
+}
+
+func TestIsResync(t *testing.T) {
+	assertions := require.New(t)
+
+	assertions.True(isResync(newDeployment("1", 1, 1), newDeployment("1", 1, 1)))
+	assertions.False(isResync(newDeployment("2", 1, 1), newDeployment("1", 1, 1)))
+}
+
+func TestUpdateFilter(t *testing.T) {
+	assertions := require.New(t)
+
+	statusOnly := newDeployment("2", 1, 0)
+	specChanged := newDeployment("2", 2, 1)
+	old := newDeployment("1", 1, 1)
+
+	f, err := newUpdateFilter(nil, DefaultIgnoreFields)
+	assertions.Nil(err)
+	assertions.Empty(f.matchedPaths(statusOnly, old))
+	assertions.Equal([]string{".spec.replicas"},
+		fieldpath.Strings(f.matchedPaths(specChanged, old)))
+
+	f, err = newUpdateFilter([]string{".status"}, []string{".metadata"})
+	assertions.Nil(err)
+	assertions.Equal([]string{".status.readyReplicas"},
+		fieldpath.Strings(f.matchedPaths(statusOnly, old)))
+	assertions.Empty(f.matchedPaths(specChanged, old))
+}
@@ -5,10 +5,8 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spongeprojects/kubebigbrother/pkg/models"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
-	"time"
 )
 
 func (s *InformerSet) RunWatcherWorker() {
@@ -46,29 +44,32 @@ func (s *InformerSet) processWatcher(key string) error {
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			klog.V(2).Infof("[watcher] watcher deleted: %s", key)
-			if informer, ok := s.WatcherMap[key]; ok {
+			s.informersMu.Lock()
+			for _, informer := range s.WatcherMap[key] {
 				informer.ShutDown()
 			}
 			delete(s.WatcherMap, key)
+			s.informersMu.Unlock()
 			return nil
 		}
 		return errors.Wrap(err, "get watcher error")
 	}
 
-	informer, err := s.setupInformer(
-		namespace, models.WatcherInformerName(namespace, name), watcher.Spec)
+	s.informersMu.Lock()
+	current := s.WatcherMap[key]
+	s.informersMu.Unlock()
+
+	running, err := s.reconcileInformers(
+		namespace, models.WatcherInformerName(namespace, name), watcher.Spec, current)
+
+	s.informersMu.Lock()
+	s.WatcherMap[key] = running
+	s.informersMu.Unlock()
+
 	if err != nil {
 		return errors.Wrap(err, "create informer error")
 	}
-	klog.V(2).Infof("[watcher] watcher added: %s", key)
-	go informer.Informer.Run(informer.StopCh)
-	cache.WaitForCacheSync(informer.StopCh, informer.Informer.HasSynced)
-
-	for i := 0; i < informer.Workers; i++ {
-		go wait.Until(informer.RunWorker, time.Second, informer.StopCh)
-	}
-
-	s.WatcherMap[key] = informer
+	klog.V(2).Infof("[watcher] watcher reconciled: %s, %d informers running", key, len(running))
 	return nil
 }
 
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+	"io/ioutil"
+)
+
+// Codecs of stored objects, the codec is recorded in every event, so events of all codecs can coexist
+const (
+	// CodecNone stores objects as raw JSON
+	CodecNone = ""
+
+	// CodecGzip stores objects compressed with gzip
+	CodecGzip = "gzip"
+
+	// CodecZstd stores objects compressed with zstd
+	CodecZstd = "zstd"
+)
+
+// zstd encoder and decoder are safe for concurrent use of EncodeAll and DecodeAll,
+// errors are only returned for invalid options.
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+// ValidateCodec returns error if codec is unknown
+func ValidateCodec(codec string) error {
+	switch codec {
+	case CodecNone, CodecGzip, CodecZstd:
+		return nil
+	default:
+		return errors.Errorf("unknown codec: %s", codec)
+	}
+}
+
+// Encode encodes Obj and OldObj with codec, decoded first when encoded with another codec
+func (e *Event) Encode(codec string) error {
+	if codec == e.Codec {
+		return nil
+	}
+	if err := e.Decode(); err != nil {
+		return err
+	}
+	if codec == CodecNone {
+		return nil
+	}
+	obj, err := encode(codec, e.Obj)
+	if err != nil {
+		return errors.Wrap(err, "encode obj error")
+	}
+	oldObj, err := encode(codec, e.OldObj)
+	if err != nil {
+		return errors.Wrap(err, "encode old obj error")
+	}
+	e.Obj = obj
+	e.OldObj = oldObj
+	e.Codec = codec
+	return nil
+}
+
+// Decode decodes Obj and OldObj to raw JSON
+func (e *Event) Decode() error {
+	if e.Codec == CodecNone {
+		return nil
+	}
+	obj, err := decode(e.Codec, e.Obj)
+	if err != nil {
+		return errors.Wrapf(err, "decode obj of event %d error", e.ID)
+	}
+	oldObj, err := decode(e.Codec, e.OldObj)
+	if err != nil {
+		return errors.Wrapf(err, "decode old obj of event %d error", e.ID)
+	}
+	e.Obj = obj
+	e.OldObj = oldObj
+	e.Codec = CodecNone
+	return nil
+}
+
+func encode(codec string, b []byte) ([]byte, error) {
+	if b == nil {
+		return nil, nil
+	}
+	switch codec {
+	case CodecGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(b); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case CodecZstd:
+		return zstdEncoder.EncodeAll(b, nil), nil
+	default:
+		return nil, errors.Errorf("unknown codec: %s", codec)
+	}
+}
+
+func decode(codec string, b []byte) ([]byte, error) {
+	if b == nil {
+		return nil, nil
+	}
+	switch codec {
+	case CodecNone:
+		return b, nil
+	case CodecGzip:
+		r, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return ioutil.ReadAll(r)
+	case CodecZstd:
+		return zstdDecoder.DecodeAll(b, nil)
+	default:
+		return nil, errors.Errorf("unknown codec: %s", codec)
+	}
+}
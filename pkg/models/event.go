@@ -3,17 +3,37 @@ package models
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sort"
+	"strings"
 	"time"
 )
 
+// Types of events, as event.Type, defined here to be used by stores
+const (
+	EventTypeAdded   = "ADDED"
+	EventTypeDeleted = "DELETED"
+	EventTypeUpdated = "UPDATED"
+
+	EventTypeTriggered = "TRIGGERED"
+	EventTypeResolved  = "RESOLVED"
+	EventTypeStuck     = "STUCK"
+
+	EventTypeOccurred = "OCCURRED"
+)
+
 // Event is a copy of Kubernetes event, to persistent event history,
 // and to avoid improper ADDED events when the controller restart.
 type Event struct {
 	ID         uint      `gorm:"primarykey" json:"id"`
 	CreateTime time.Time `gorm:"autoCreateTime" json:"create_time"`
 
-	// InformerName is unique value represents the informer config,
+	// Cluster is the name of the cluster where the event happened,
+	// empty when not watching multiple clusters.
+	Cluster string `json:"cluster"`
+
+	// InformerName is unique value represents the informer config in a cluster,
 	// every Event belongs to an informer.
 	InformerName string `json:"informer_name"`
 
@@ -24,24 +44,108 @@ type Event struct {
 	Kind      string `json:"kind"`
 	Namespace string `json:"namespace"`
 	Name      string `json:"name"`
-	Obj       []byte `json:"obj,omitempty"`
-	OldObj    []byte `json:"old_obj,omitempty"`
+
+	// UID and ResourceVersion are copied from metadata of the resource
+	UID             string `json:"uid"`
+	ResourceVersion string `json:"resource_version"`
+
+	Obj    []byte `json:"obj,omitempty"`
+	OldObj []byte `json:"old_obj,omitempty"`
+
+	// BaseID is the event objects are stored as merge patches against, 0 when stored in full,
+	// Obj is patched from Obj of the base, OldObj is patched from Obj,
+	// they are always resolved to full objects when read from the store.
+	BaseID uint `json:"-"`
+
+	// PatchDepth is the number of patches from the nearest full snapshot
+	PatchDepth int `json:"-"`
+
+	// Codec is how Obj and OldObj are encoded, e.g. gzip, raw JSON when empty,
+	// so that rows encoded differently can coexist.
+	Codec string `json:"-"`
+
+	// FinalStateUnknown is true when a DELETED event was recovered from
+	// a tombstone, Obj is the last known state and may be stale.
+	FinalStateUnknown bool `json:"final_state_unknown,omitempty"`
+
+	// Involved fields are only set for OCCURRED events, recorded in events mode,
+	// they identify the object the Kubernetes Event is about.
+	InvolvedKind      string `json:"involved_kind,omitempty"`
+	InvolvedNamespace string `json:"involved_namespace,omitempty"`
+	InvolvedName      string `json:"involved_name,omitempty"`
+	InvolvedUID       string `json:"involved_uid,omitempty"`
+
+	// Attribution is who made the change
+	Attribution `gorm:"embedded"`
+
+	// OwnerChain is the controller owner chain of the resource, nearest first,
+	// e.g. ReplicaSet/foo-5d4b7c,Deployment/foo
+	OwnerChain string `json:"owner_chain,omitempty"`
+
+	// Labels are labels of the object in the form of FormatLabels, to be matched by label selectors
+	Labels string `json:"-"`
+
+	// Highlights are lines of the object matching a content search, not stored
+	Highlights []string `gorm:"-" json:"highlights,omitempty"`
+}
+
+// Attribution is who made the change, user fields are from audit logs,
+// lists are comma separated
+type Attribution struct {
+	Username   string `json:"username,omitempty"`
+	UserGroups string `json:"user_groups,omitempty"`
+	UserAgent  string `json:"user_agent,omitempty"`
+	SourceIPs  string `json:"source_ips,omitempty"`
+
+	// Manager is the field manager of the latest change in metadata.managedFields,
+	// used when no audit record is matched
+	Manager string `json:"manager,omitempty"`
+}
+
+// ErrUnresolved is returned for objects of events stored as patches (BaseID is set),
+// they are resolved when events are read through the event store, not from the table directly.
+var ErrUnresolved = errors.New("objects of the event are stored as patches and not resolved")
+
+// GetObj returns the object of the event, nil when there is none, ErrUnresolved when stored as patches
+func (e *Event) GetObj() (*unstructured.Unstructured, error) {
+	return e.unmarshalObj(e.Obj)
+}
+
+// GetOldObj returns the old object of the event, nil when there is none, ErrUnresolved when stored as patches
+func (e *Event) GetOldObj() (*unstructured.Unstructured, error) {
+	return e.unmarshalObj(e.OldObj)
 }
 
-func (e *Event) GetObj() (obj *unstructured.Unstructured) {
-	if e.Obj == nil {
-		return nil
+func (e *Event) unmarshalObj(b []byte) (*unstructured.Unstructured, error) {
+	if b == nil {
+		return nil, nil
+	}
+	if e.BaseID != 0 {
+		return nil, ErrUnresolved
 	}
-	_ = json.Unmarshal(e.Obj, &obj)
-	return obj
+	b, err := decode(e.Codec, b)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decode object of event %d error", e.ID)
+	}
+	var obj *unstructured.Unstructured
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return nil, errors.Wrapf(err, "unmarshal object of event %d error", e.ID)
+	}
+	return obj, nil
 }
 
-func (e *Event) GetOldObj() (obj *unstructured.Unstructured) {
-	if e.OldObj == nil {
-		return nil
+// FormatLabels formats labels to be stored, sorted and wrapped in commas, e.g. ",app=foo,tier=web,",
+// so that a label can be matched by LIKE '%,app=foo,%', empty when there are no labels.
+func FormatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
 	}
-	_ = json.Unmarshal(e.OldObj, &obj)
-	return obj
+	sort.Strings(pairs)
+	return "," + strings.Join(pairs, ",") + ","
 }
 
 func WatcherInformerName(namespace, name string) string {
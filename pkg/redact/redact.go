@@ -0,0 +1,159 @@
+package redact
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/spongeprojects/kubebigbrother/pkg/utils/fieldpath"
+	"io/ioutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"os"
+	"strings"
+	"sync"
+)
+
+// AnnotationRedact opts an object out of redaction when set to "false"
+const AnnotationRedact = "kubebigbrother.spongeprojects.com/redact"
+
+// hashPrefix marks redacted values
+const hashPrefix = "redacted:hmac-sha256:"
+
+// keySize is the size of keys generated
+const keySize = 32
+
+var (
+	keyMu sync.RWMutex
+
+	// key is the key of HMAC of redacted values, unique to the installation,
+	// so that values can't be recovered from hashes by brute force, or matched across installations,
+	// it's random until set, hashes are not comparable across restarts then.
+	key = generateKey()
+)
+
+// SecretFields are fields of Secrets redacted by default,
+// last applied configuration is included since it contains data as well.
+var SecretFields = []string{
+	".data.*",
+	".stringData.*",
+	".metadata.annotations['kubectl.kubernetes.io/last-applied-configuration']",
+}
+
+var secretPatterns []*fieldpath.Pattern
+
+func generateKey() []byte {
+	k := make([]byte, keySize)
+	if _, err := rand.Read(k); err != nil {
+		panic(err)
+	}
+	return k
+}
+
+// SetKey sets the key of HMAC of redacted values
+func SetKey(k []byte) {
+	keyMu.Lock()
+	defer keyMu.Unlock()
+	key = k
+}
+
+// LoadKey loads the key from the file at path, a random key is generated and saved to it if not exists
+func LoadKey(path string) ([]byte, error) {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		k := []byte(hex.EncodeToString(generateKey()))
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			return LoadKey(path) // generated by another process
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "create redact key file error")
+		}
+		defer f.Close()
+		if _, err := f.Write(k); err != nil {
+			return nil, errors.Wrap(err, "write redact key file error")
+		}
+		return k, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "read redact key file error")
+	}
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 {
+		return nil, errors.Errorf("redact key file is empty: %s", path)
+	}
+	return b, nil
+}
+
+func init() {
+	var err error
+	secretPatterns, err = fieldpath.ParseAll(SecretFields)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Redactor replaces values of sensitive fields with their hashes,
+// so that changes are still visible without revealing values.
+type Redactor struct {
+	patterns []*fieldpath.Pattern
+}
+
+// New creates Redactor for fields in JSONPath, fields of Secrets are always redacted
+func New(fields []string) (*Redactor, error) {
+	patterns, err := fieldpath.ParseAll(fields)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid redact fields")
+	}
+	return &Redactor{
+		patterns: patterns,
+	}, nil
+}
+
+// Redact returns a redacted copy of obj, obj itself is never modified,
+// obj is returned as it is if nothing to redact.
+func (r *Redactor) Redact(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	if obj == nil || obj.GetAnnotations()[AnnotationRedact] == "false" {
+		return obj
+	}
+	patterns := r.patterns
+	if obj.GroupVersionKind().Group == "" && obj.GetKind() == "Secret" {
+		patterns = append(append([]*fieldpath.Pattern{}, secretPatterns...), patterns...)
+	}
+	if len(patterns) == 0 {
+		return obj
+	}
+
+	redacted := obj.DeepCopy()
+	for _, p := range patterns {
+		p.Replace(redacted.Object, Hash)
+	}
+	return redacted
+}
+
+// Default redacts fields of Secrets only
+var Default = &Redactor{}
+
+// Hash hashes v as a redacted value with HMAC of the key, the same value always has the same hash
+// in an installation, values redacted already are kept as they are.
+func Hash(v interface{}) interface{} {
+	var b []byte
+	switch vv := v.(type) {
+	case nil:
+		return nil
+	case string:
+		if strings.HasPrefix(vv, hashPrefix) {
+			return vv
+		}
+		b = []byte(vv)
+	default:
+		b, _ = json.Marshal(v)
+	}
+	keyMu.RLock()
+	mac := hmac.New(sha256.New, key)
+	keyMu.RUnlock()
+	mac.Write(b)
+	return hashPrefix + hex.EncodeToString(mac.Sum(nil)[:8])
+}
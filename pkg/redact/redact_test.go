@@ -0,0 +1,106 @@
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newSecret(password string, annotations map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"namespace":   "demo",
+			"name":        "canary",
+			"annotations": annotations,
+		},
+		"data": map[string]interface{}{
+			"username": "YWRtaW4=",
+			"password": password,
+		},
+	}}
+}
+
+func TestRedact(t *testing.T) {
+	assertions := require.New(t)
+
+	r, err := New(nil)
+	assertions.Nil(err)
+
+	secret := newSecret("cGFzc3dvcmQ=", map[string]interface{}{
+		"kubectl.kubernetes.io/last-applied-configuration": `{"data":{"password":"cGFzc3dvcmQ="}}`,
+	})
+	redacted := r.Redact(secret)
+	assertions.Equal("cGFzc3dvcmQ=", secret.Object["data"].(map[string]interface{})["password"],
+		"original object should not be modified")
+
+	data := redacted.Object["data"].(map[string]interface{})
+	assertions.Equal(Hash("cGFzc3dvcmQ="), data["password"])
+	assertions.Equal(Hash("YWRtaW4="), data["username"])
+	assertions.NotContains(redacted.GetAnnotations()["kubectl.kubernetes.io/last-applied-configuration"], "cGFzc3dvcmQ=")
+
+	assertions.Equal(redacted.Object, r.Redact(redacted).Object, "redaction should be idempotent")
+
+	changed := r.Redact(newSecret("bmV3", nil))
+	assertions.NotEqual(data["password"], changed.Object["data"].(map[string]interface{})["password"])
+	assertions.Equal(data["username"], changed.Object["data"].(map[string]interface{})["username"])
+
+	optedOut := newSecret("cGFzc3dvcmQ=", map[string]interface{}{AnnotationRedact: "false"})
+	assertions.Same(optedOut, r.Redact(optedOut))
+
+	r, err = New([]string{".spec.token"})
+	assertions.Nil(err)
+	cm := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Credential",
+		"spec":       map[string]interface{}{"token": "abc", "user": "admin"},
+	}}
+	assertions.Equal(map[string]interface{}{"token": Hash("abc"), "user": "admin"},
+		r.Redact(cm).Object["spec"])
+
+	_, err = New([]string{".spec..token"})
+	assertions.NotNil(err)
+}
+
+func TestHashKey(t *testing.T) {
+	assertions := require.New(t)
+
+	original := key
+	defer SetKey(original)
+
+	SetKey([]byte("installation-a"))
+	a := Hash("password")
+	assertions.Equal(a, Hash("password"))
+	assertions.Equal(a, Hash(a), "values redacted already are kept")
+	sum := sha256.Sum256([]byte("password"))
+	assertions.NotContains(a, hex.EncodeToString(sum[:8]), "values are not hashed without the key")
+
+	SetKey([]byte("installation-b"))
+	assertions.NotEqual(a, Hash("password"))
+}
+
+func TestLoadKey(t *testing.T) {
+	assertions := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "redact.key")
+	generated, err := LoadKey(path)
+	assertions.Nil(err)
+	assertions.Len(generated, keySize*2)
+	info, err := os.Stat(path)
+	assertions.Nil(err)
+	assertions.Equal(os.FileMode(0600), info.Mode().Perm())
+
+	loaded, err := LoadKey(path)
+	assertions.Nil(err)
+	assertions.Equal(generated, loaded)
+
+	assertions.Nil(ioutil.WriteFile(path, []byte(" \n"), 0600))
+	_, err = LoadKey(path)
+	assertions.NotNil(err)
+}
@@ -0,0 +1,620 @@
+package event_store
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"github.com/pkg/errors"
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	bolt "go.etcd.io/bbolt"
+	"gorm.io/gorm"
+	"k8s.io/klog/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// DialectBolt is the dialect of the embedded bolt store, database args is the path of the file
+const DialectBolt = "bolt"
+
+// boltLockTimeout is how long to wait for the file locked by another process, e.g. the controller
+const boltLockTimeout = 10 * time.Second
+
+// Buckets of the bolt store, keys of indexes are fields terminated by 0 followed by the big endian event ID,
+// so that events of an index entry are sorted by ID.
+var (
+	// bucketEvents stores gob encoded events by ID
+	bucketEvents = []byte("events")
+
+	// bucketClusters stores names of clusters with events, terminated by 0 as the default cluster is empty
+	bucketClusters = []byte("clusters")
+
+	// bucketObjects indexes events by cluster, informer, group, version, resource, namespace and name,
+	// values are event types, e.g. ListCurrentlyAdded and MaxVersions of retention
+	bucketObjects = []byte("idx_object")
+
+	// bucketInformers indexes events by cluster and informer, values are create times, e.g. List and retention
+	bucketInformers = []byte("idx_informer")
+
+	// bucketNames indexes events by cluster, namespace and name, e.g. attribution, history and snapshots
+	bucketNames = []byte("idx_name")
+
+	// bucketInvolved indexes OCCURRED events by cluster, involved namespace and involved name, e.g. ListRelated
+	bucketInvolved = []byte("idx_involved")
+)
+
+var boltBuckets = [][]byte{
+	bucketEvents, bucketClusters, bucketObjects, bucketInformers, bucketNames, bucketInvolved,
+}
+
+// BoltStore stores events in an embedded bolt file, for deployments without a SQL database.
+// The file is open for the life of the store, bolt locks it while it's open, exclusively,
+// or shared by processes reading when opened read-only, see Options.ReadOnly.
+// Objects are always stored in full, contents of objects are searched without an index.
+type BoltStore struct {
+	Path    string
+	Options Options
+
+	db *bolt.DB
+}
+
+// NewBolt creates BoltStore with the file at path, which is created if not exists unless read-only
+func NewBolt(path string, options Options) (Interface, error) {
+	if path == "" {
+		return nil, errors.New("path of the bolt file is required")
+	}
+	if err := options.complete(); err != nil {
+		return nil, err
+	}
+	if options.StorageMode != StorageModeFull {
+		return nil, errors.Errorf("storage mode %s is not supported by bolt", options.StorageMode)
+	}
+	if !options.ReadOnly {
+		_ = os.MkdirAll(filepath.Dir(path), 0755)
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: boltLockTimeout, ReadOnly: options.ReadOnly})
+	if err != nil {
+		return nil, errors.Wrapf(err, "open %s error", path)
+	}
+	s := &BoltStore{Path: path, Options: options, db: db}
+	if options.ReadOnly {
+		return s, nil
+	}
+	if err := s.update(func(tx *bolt.Tx) error {
+		for _, name := range boltBuckets {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		_ = db.Close()
+		return nil, errors.Wrap(err, "create buckets error")
+	}
+	return s, nil
+}
+
+// Close closes the file, the store can't be used after
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// view runs fn in a read-only transaction
+func (s *BoltStore) view(fn func(tx *bolt.Tx) error) error {
+	return s.db.View(fn)
+}
+
+// update runs fn in a read-write transaction, changes are rolled back if fn returns error
+func (s *BoltStore) update(fn func(tx *bolt.Tx) error) error {
+	return s.db.Update(fn)
+}
+
+// indexPrefix builds the prefix of index keys of fields
+func indexPrefix(fields ...string) []byte {
+	var key []byte
+	for _, f := range fields {
+		key = append(key, f...)
+		key = append(key, 0)
+	}
+	return key
+}
+
+// indexKey builds the index key of fields of the event
+func indexKey(id uint, fields ...string) []byte {
+	return append(indexPrefix(fields...), itob(id)...)
+}
+
+// indexFields returns fields of an index key
+func indexFields(key []byte) []string {
+	parts := bytes.Split(key[:len(key)-8], []byte{0})
+	fields := make([]string, 0, len(parts)-1)
+	for _, p := range parts[:len(parts)-1] {
+		fields = append(fields, string(p))
+	}
+	return fields
+}
+
+func itob(id uint) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(id))
+	return b
+}
+
+// idOf returns the event ID of a key of events or indexes
+func idOf(key []byte) uint {
+	return uint(binary.BigEndian.Uint64(key[len(key)-8:]))
+}
+
+func timeBytes(t time.Time) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(t.UnixNano()))
+	return b
+}
+
+func timeOf(b []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(b)))
+}
+
+// boltIndex is an entry of an index of an event
+type boltIndex struct {
+	bucket []byte
+	key    []byte
+	value  []byte
+}
+
+func indexesOf(e *models.Event) []boltIndex {
+	indexes := []boltIndex{
+		{bucketObjects, indexKey(e.ID, e.Cluster, e.InformerName,
+			e.Group, e.Version, e.Resource, e.Namespace, e.Name), []byte(e.EventType)},
+		{bucketInformers, indexKey(e.ID, e.Cluster, e.InformerName), timeBytes(e.CreateTime)},
+		{bucketNames, indexKey(e.ID, e.Cluster, e.Namespace, e.Name), []byte{}},
+	}
+	if e.EventType == models.EventTypeOccurred {
+		indexes = append(indexes, boltIndex{bucketInvolved,
+			indexKey(e.ID, e.Cluster, e.InvolvedNamespace, e.InvolvedName), []byte{}})
+	}
+	return indexes
+}
+
+func encodeEvent(e *models.Event) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(e); err != nil {
+		return nil, errors.Wrapf(err, "encode event %d error", e.ID)
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeEvent(b []byte) (*models.Event, error) {
+	var e models.Event
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&e); err != nil {
+		return nil, errors.Wrap(err, "decode event error")
+	}
+	return &e, nil
+}
+
+// getEvent gets the stored event, nil if not found
+func getEvent(tx *bolt.Tx, id uint) (*models.Event, error) {
+	b := tx.Bucket(bucketEvents).Get(itob(id))
+	if b == nil {
+		return nil, nil
+	}
+	return decodeEvent(b)
+}
+
+// putEvent stores the event with its indexes, indexes of the stored event are replaced
+func putEvent(tx *bolt.Tx, e *models.Event) error {
+	old, err := getEvent(tx, e.ID)
+	if err != nil {
+		return err
+	}
+	if old != nil {
+		if err := deleteIndexes(tx, old); err != nil {
+			return err
+		}
+	}
+	b, err := encodeEvent(e)
+	if err != nil {
+		return err
+	}
+	if err := tx.Bucket(bucketEvents).Put(itob(e.ID), b); err != nil {
+		return err
+	}
+	if err := tx.Bucket(bucketClusters).Put(indexPrefix(e.Cluster), []byte{}); err != nil {
+		return err
+	}
+	for _, index := range indexesOf(e) {
+		if err := tx.Bucket(index.bucket).Put(index.key, index.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deleteIndexes(tx *bolt.Tx, e *models.Event) error {
+	for _, index := range indexesOf(e) {
+		if err := tx.Bucket(index.bucket).Delete(index.key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteEvent deletes the stored event with its indexes
+func deleteEvent(tx *bolt.Tx, e *models.Event) error {
+	if err := deleteIndexes(tx, e); err != nil {
+		return err
+	}
+	return tx.Bucket(bucketEvents).Delete(itob(e.ID))
+}
+
+// scan iterates keys with prefix, latest first when reverse, from the key after, exclusively, when set,
+// until fn returns false.
+func scan(b *bolt.Bucket, prefix, after []byte, reverse bool, fn func(k, v []byte) (bool, error)) error {
+	c := b.Cursor()
+	var k, v []byte
+	if !reverse {
+		if after == nil {
+			k, v = c.Seek(prefix)
+		} else if k, v = c.Seek(after); bytes.Equal(k, after) {
+			k, v = c.Next()
+		}
+	} else {
+		bound := after
+		if bound == nil {
+			bound = prefixEnd(prefix)
+		}
+		if bound == nil {
+			k, v = c.Last()
+		} else if k, v = c.Seek(bound); k == nil {
+			k, v = c.Last()
+		} else {
+			k, v = c.Prev()
+		}
+	}
+	for k != nil && bytes.HasPrefix(k, prefix) {
+		more, err := fn(k, v)
+		if err != nil || !more {
+			return err
+		}
+		if reverse {
+			k, v = c.Prev()
+		} else {
+			k, v = c.Next()
+		}
+	}
+	return nil
+}
+
+// prefixEnd returns the first key after keys with prefix, nil if there is none
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte(nil), prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+// scanEvents iterates events of index entries with prefix, see scan
+func scanEvents(tx *bolt.Tx, bucket, prefix, after []byte, reverse bool,
+	fn func(e *models.Event) (bool, error)) error {
+	return scan(tx.Bucket(bucket), prefix, after, reverse, func(k, v []byte) (bool, error) {
+		var e *models.Event
+		var err error
+		if bytes.Equal(bucket, bucketEvents) {
+			e, err = decodeEvent(v)
+		} else {
+			e, err = getEvent(tx, idOf(k))
+		}
+		if err != nil {
+			return false, err
+		}
+		if e == nil {
+			return false, errors.Errorf("event %d of index %s not found", idOf(k), bucket)
+		}
+		return fn(e)
+	})
+}
+
+// clustersOf returns names of clusters with events
+func clustersOf(tx *bolt.Tx) []string {
+	var clusters []string
+	_ = tx.Bucket(bucketClusters).ForEach(func(k, v []byte) error {
+		clusters = append(clusters, string(k[:len(k)-1]))
+		return nil
+	})
+	return clusters
+}
+
+func (s *BoltStore) Find(id uint) (event *models.Event, err error) {
+	err = s.view(func(tx *bolt.Tx) error {
+		event, err = getEvent(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return event, event.Decode()
+}
+
+func (s *BoltStore) Save(event *models.Event) error {
+	stored := *event
+	if err := stored.Encode(s.Options.Compression); err != nil {
+		return err
+	}
+	stored.Highlights = nil
+	if stored.CreateTime.IsZero() {
+		stored.CreateTime = time.Now()
+	}
+	if err := s.update(func(tx *bolt.Tx) error {
+		if stored.ID == 0 {
+			id, err := tx.Bucket(bucketEvents).NextSequence()
+			if err != nil {
+				return err
+			}
+			stored.ID = uint(id)
+		}
+		return putEvent(tx, &stored)
+	}); err != nil {
+		return err
+	}
+	event.ID = stored.ID
+	event.CreateTime = stored.CreateTime
+	return nil
+}
+
+func (s *BoltStore) SaveSilently(event *models.Event) {
+	if err := s.Save(event); err != nil {
+		klog.Warning(errors.Wrap(err, "save event error"))
+	}
+}
+
+func (s *BoltStore) ListCurrentlyAdded(cluster, informerName,
+	group, version, resource string) (events []models.Event, err error) {
+	return s.listLatest(cluster, informerName, group, version, resource,
+		[]string{models.EventTypeAdded, models.EventTypeDeleted}, models.EventTypeAdded)
+}
+
+func (s *BoltStore) ListCurrentlyTriggered(cluster, informerName,
+	group, version, resource string) (events []models.Event, err error) {
+	return s.listLatest(cluster, informerName, group, version, resource,
+		[]string{models.EventTypeTriggered, models.EventTypeResolved}, models.EventTypeTriggered)
+}
+
+func (s *BoltStore) ListCurrentlyOccurred(cluster, informerName,
+	group, version, resource string) (events []models.Event, err error) {
+	return s.listLatest(cluster, informerName, group, version, resource,
+		[]string{models.EventTypeOccurred}, models.EventTypeOccurred)
+}
+
+// listLatest finds the latest event among eventTypes for every resource,
+// returns those whose type is currentType, objects are omitted.
+func (s *BoltStore) listLatest(cluster, informerName, group, version, resource string,
+	eventTypes []string, currentType string) (events []models.Event, err error) {
+	prefix := indexPrefix(cluster, informerName, group, version, resource)
+	err = s.view(func(tx *bolt.Tx) error {
+		// entries of an object are adjacent, sorted by ID
+		latest := make(map[string]uint)
+		var objects []string
+		if err := scan(tx.Bucket(bucketObjects), prefix, nil, false, func(k, v []byte) (bool, error) {
+			if !containsString(eventTypes, string(v)) {
+				return true, nil
+			}
+			object := string(k[:len(k)-8])
+			if _, ok := latest[object]; !ok {
+				objects = append(objects, object)
+			}
+			latest[object] = idOf(k)
+			return true, nil
+		}); err != nil {
+			return err
+		}
+		for _, object := range objects {
+			e, err := getEvent(tx, latest[object])
+			if err != nil {
+				return err
+			}
+			if e != nil && e.EventType == currentType {
+				events = append(events, omitObjects(*e))
+			}
+		}
+		return nil
+	})
+	return events, err
+}
+
+// ListRelated links Kubernetes Events and events of the objects they are about, see Store.ListRelated
+func (s *BoltStore) ListRelated(event *models.Event) (events []models.Event, err error) {
+	const limit = 50
+	err = s.view(func(tx *bolt.Tx) error {
+		if event.EventType == models.EventTypeOccurred {
+			prefix := indexPrefix(event.Cluster, event.InvolvedNamespace, event.InvolvedName)
+			return scanEvents(tx, bucketNames, prefix, nil, true, func(e *models.Event) (bool, error) {
+				if e.EventType != models.EventTypeOccurred && e.Kind == event.InvolvedKind {
+					events = append(events, omitObjects(*e))
+				}
+				return len(events) < limit, nil
+			})
+		}
+		prefix := indexPrefix(event.Cluster, event.Namespace, event.Name)
+		return scanEvents(tx, bucketInvolved, prefix, nil, true, func(e *models.Event) (bool, error) {
+			if e.InvolvedKind == event.Kind {
+				events = append(events, omitObjects(*e))
+			}
+			return len(events) < limit, nil
+		})
+	})
+	return events, err
+}
+
+// Attribute records who made the change on events matching an audit record, see Store.Attribute
+func (s *BoltStore) Attribute(match AuditMatch, attribution models.Attribution) (attributed int64, err error) {
+	err = s.update(func(tx *bolt.Tx) error {
+		var unattributed []*models.Event
+		prefix := indexPrefix(match.Cluster, match.Namespace, match.Name)
+		if err := scanEvents(tx, bucketNames, prefix, nil, false, func(e *models.Event) (bool, error) {
+			if e.Group == match.Group && e.Resource == match.Resource &&
+				containsString(match.EventTypes, e.EventType) && e.Username == "" {
+				unattributed = append(unattributed, e)
+			}
+			return true, nil
+		}); err != nil {
+			return err
+		}
+
+		resourceVersion := match.ResourceVersion
+		if resourceVersion == "" {
+			from, to := match.Time.Add(-auditClockSkew), match.Time.Add(auditWindow)
+			var first *models.Event
+			for _, e := range unattributed {
+				if e.CreateTime.Before(from) || e.CreateTime.After(to) {
+					continue
+				}
+				if first == nil || e.CreateTime.Before(first.CreateTime) {
+					first = e
+				}
+			}
+			if first == nil {
+				return nil
+			}
+			// events of the same change share the resourceVersion, e.g. UPDATED and TRIGGERED
+			resourceVersion = first.ResourceVersion
+		}
+
+		for _, e := range unattributed {
+			if e.ResourceVersion != resourceVersion {
+				continue
+			}
+			e.Username = attribution.Username
+			e.UserGroups = attribution.UserGroups
+			e.UserAgent = attribution.UserAgent
+			e.SourceIPs = attribution.SourceIPs
+			if err := putEvent(tx, e); err != nil {
+				return err
+			}
+			attributed++
+		}
+		return nil
+	})
+	return attributed, err
+}
+
+// History lists versions of an object, oldest first, see Store.History
+func (s *BoltStore) History(options HistoryOptions) ([]Version, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	limit := options.Limit
+	if limit == 0 {
+		limit = DefaultHistoryLimit
+	}
+
+	var events []models.Event
+	prefix := indexPrefix(options.Cluster, options.Namespace, options.Name)
+	if err := s.view(func(tx *bolt.Tx) error {
+		return scanEvents(tx, bucketNames, prefix, nil, true, func(e *models.Event) (bool, error) {
+			if e.Resource == options.Resource && e.Group == options.Group &&
+				e.EventType != models.EventTypeOccurred && e.Obj != nil {
+				events = append(events, *e)
+			}
+			return len(events) < limit, nil
+		})
+	}); err != nil {
+		return nil, errors.Wrap(err, "list events error")
+	}
+
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+	for i := range events {
+		if err := events[i].Decode(); err != nil {
+			return nil, err
+		}
+	}
+	return historyOf(events)
+}
+
+// Snapshot reconstructs objects existing at the time, see Store.Snapshot
+func (s *BoltStore) Snapshot(options SnapshotOptions, at time.Time) ([]models.Event, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	bucket, prefix := bucketNames, indexPrefix(options.Cluster, options.Namespace)
+	if options.InformerName != "" {
+		bucket, prefix = bucketInformers, indexPrefix(options.Cluster, options.InformerName)
+	}
+
+	// events of an object across informers and versions of the resource
+	latest := make(map[string]*models.Event)
+	if err := s.view(func(tx *bolt.Tx) error {
+		return scanEvents(tx, bucket, prefix, nil, false, func(e *models.Event) (bool, error) {
+			if e.EventType == models.EventTypeOccurred || e.Obj == nil || e.CreateTime.After(at) ||
+				(options.Namespace != "" && e.Namespace != options.Namespace) ||
+				(options.InformerName != "" && e.InformerName != options.InformerName) {
+				return true, nil
+			}
+			key := objectKey(e)
+			if l, ok := latest[key]; !ok || l.ID < e.ID {
+				latest[key] = e
+			}
+			return true, nil
+		})
+	}); err != nil {
+		return nil, errors.Wrap(err, "list latest events error")
+	}
+
+	var events []models.Event
+	for _, e := range latest {
+		if e.EventType == models.EventTypeDeleted {
+			continue
+		}
+		if err := e.Decode(); err != nil {
+			return nil, err
+		}
+		events = append(events, *e)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		a, b := &events[i], &events[j]
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		if a.Resource != b.Resource {
+			return a.Resource < b.Resource
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return events, nil
+}
+
+// DiffSnapshots lists objects added, removed and changed between snapshots at from and to
+func (s *BoltStore) DiffSnapshots(options SnapshotOptions, from, to time.Time) (*SnapshotDiff, error) {
+	before, err := s.Snapshot(options, from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.Snapshot(options, to)
+	if err != nil {
+		return nil, err
+	}
+	return diffSnapshots(before, after)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,286 @@
+package event_store
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	bolt "go.etcd.io/bbolt"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sort"
+	"strings"
+)
+
+// List lists a page of events, latest first
+func (s *BoltStore) List(options ListOptions) (events []models.Event, err error) {
+	page, err := s.ListPage(options)
+	if err != nil {
+		return nil, err
+	}
+	return page.Events, nil
+}
+
+// ListPage lists a page of events, latest first, with cursors of adjacent pages.
+// Events are scanned by the narrowest index usable, all events are scanned when there is none,
+// events are counted in the same scan when CountTotal is set.
+func (s *BoltStore) ListPage(options ListOptions) (*Page, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	limit := options.Limit
+	if limit == 0 {
+		limit = DefaultListLimit
+	}
+	c, _ := parseCursor(options.Cursor)
+	m, err := newEventMatcher(options)
+	if err != nil {
+		return nil, err
+	}
+
+	// events are scanned in the order of the page, from the cursor, or from the start to count events before it
+	reverse := c == nil || !c.Newer
+	var from *uint
+	if c != nil && !options.CountTotal {
+		from = &c.ID
+	}
+	var events []models.Event
+	var total int64
+	visit := func(e *models.Event) (bool, error) {
+		ok, err := m.matches(e)
+		if err != nil || !ok {
+			return true, err
+		}
+		total++
+		if len(events) <= limit && (c == nil || (c.Newer && e.ID > c.ID) || (!c.Newer && e.ID < c.ID)) {
+			events = append(events, *e)
+		}
+		return options.CountTotal || len(events) <= limit, nil
+	}
+	if err := s.view(func(tx *bolt.Tx) error {
+		ids, indexed := listCandidates(tx, options)
+		if !indexed {
+			var after []byte
+			if from != nil {
+				after = itob(*from)
+			}
+			return scanEvents(tx, bucketEvents, nil, after, reverse, visit)
+		}
+		return visitEvents(tx, ids, from, reverse, visit)
+	}); err != nil {
+		return nil, err
+	}
+
+	page := newPage(events, limit, c, options.Cursor)
+	if options.CountTotal {
+		page.Total = &total
+	}
+	for i := range page.Events {
+		e := &page.Events[i]
+		if options.Content != "" {
+			// objects were decoded to search contents
+			content, err := contentOf(e.Obj)
+			if err != nil {
+				return nil, err
+			}
+			e.Highlights = highlight(content, searchTerms(options.Content))
+		}
+		if options.OmitObjects {
+			*e = omitObjects(*e)
+		} else if err := e.Decode(); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// listCandidates returns sorted IDs of events possibly matching options by the narrowest index usable,
+// from keys of the index without reading events, indexed is false when no index is usable.
+// Indexes are used in the order of idx_name by namespace and name, idx_object by group resource,
+// idx_name by namespace and idx_informer by informer, every cluster is scanned unless the cluster is set.
+func listCandidates(tx *bolt.Tx, options ListOptions) (ids []uint, indexed bool) {
+	clusters := []string{options.Cluster}
+	if options.Cluster == "" {
+		clusters = clustersOf(tx)
+	}
+
+	var bucket []byte
+	var prefixOf func(cluster string) []byte
+	keep := func(k, v []byte) bool { return true }
+	switch {
+	case options.Namespace != "" && options.Name != "":
+		bucket = bucketNames
+		prefixOf = func(cluster string) []byte { return indexPrefix(cluster, options.Namespace, options.Name) }
+	case options.GroupResource != "":
+		gr := schema.ParseGroupResource(options.GroupResource)
+		bucket = bucketObjects
+		prefixOf = func(cluster string) []byte {
+			if options.InformerName == "" {
+				return indexPrefix(cluster)
+			}
+			return indexPrefix(cluster, options.InformerName, gr.Group)
+		}
+		// fields of keys: cluster, informer, group, version, resource, namespace and name
+		keep = func(k, v []byte) bool {
+			fields := indexFields(k)
+			return fields[2] == gr.Group && fields[4] == gr.Resource &&
+				(options.Namespace == "" || fields[5] == options.Namespace) &&
+				(options.Name == "" || fields[6] == options.Name) &&
+				(len(options.EventTypes) == 0 || containsString(options.EventTypes, string(v)))
+		}
+	case options.Namespace != "":
+		bucket = bucketNames
+		prefixOf = func(cluster string) []byte { return indexPrefix(cluster, options.Namespace) }
+	case options.InformerName != "":
+		bucket = bucketInformers
+		prefixOf = func(cluster string) []byte { return indexPrefix(cluster, options.InformerName) }
+	default:
+		return nil, false
+	}
+
+	for _, cluster := range clusters {
+		_ = scan(tx.Bucket(bucket), prefixOf(cluster), nil, false, func(k, v []byte) (bool, error) {
+			if keep(k, v) {
+				ids = append(ids, idOf(k))
+			}
+			return true, nil
+		})
+	}
+	// entries of different objects and clusters are interleaved
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, true
+}
+
+// visitEvents iterates events of sorted ids, latest first when reverse, after the ID from, exclusively,
+// when set, until fn returns false.
+func visitEvents(tx *bolt.Tx, ids []uint, from *uint, reverse bool, fn func(e *models.Event) (bool, error)) error {
+	start, end := 0, len(ids)
+	if from != nil {
+		if reverse {
+			end = sort.Search(len(ids), func(i int) bool { return ids[i] >= *from })
+		} else {
+			start = sort.Search(len(ids), func(i int) bool { return ids[i] > *from })
+		}
+	}
+	for i := start; i < end; i++ {
+		id := ids[i]
+		if reverse {
+			id = ids[end-1-(i-start)]
+		}
+		e, err := getEvent(tx, id)
+		if err != nil {
+			return err
+		}
+		if e == nil {
+			return errors.Errorf("event %d of index not found", id)
+		}
+		if more, err := fn(e); err != nil || !more {
+			return err
+		}
+	}
+	return nil
+}
+
+// eventMatcher matches events with filters of ListOptions, as Store.filter does in SQL,
+// objects are only decoded to search contents.
+type eventMatcher struct {
+	options ListOptions
+
+	ownerKind, ownerName string
+	groupResource        schema.GroupResource
+	requirements         []labels.Requirement
+	phrase               string
+}
+
+func newEventMatcher(options ListOptions) (*eventMatcher, error) {
+	m := &eventMatcher{options: options}
+	var err error
+	if options.Owner != "" {
+		if m.ownerKind, m.ownerName, err = parseOwner(options.Owner); err != nil {
+			return nil, err
+		}
+	}
+	if options.GroupResource != "" {
+		m.groupResource = schema.ParseGroupResource(options.GroupResource)
+	}
+	if options.LabelSelector != "" {
+		if m.requirements, err = parseLabelSelector(options.LabelSelector); err != nil {
+			return nil, err
+		}
+	}
+	if options.Content != "" {
+		m.phrase = " " + strings.Join(searchTerms(options.Content), " ") + " "
+	}
+	return m, nil
+}
+
+func (m *eventMatcher) matches(e *models.Event) (bool, error) {
+	o := &m.options
+	if (o.Cluster != "" && e.Cluster != o.Cluster) ||
+		(o.InformerName != "" && e.InformerName != o.InformerName) ||
+		(o.After != 0 && e.ID <= o.After) ||
+		(len(o.EventTypes) > 0 && !containsString(o.EventTypes, e.EventType)) ||
+		(o.Kind != "" && e.Kind != o.Kind) ||
+		(o.GroupResource != "" && (e.Group != m.groupResource.Group || e.Resource != m.groupResource.Resource)) ||
+		(o.Namespace != "" && e.Namespace != o.Namespace) ||
+		(o.Name != "" && e.Name != o.Name) ||
+		(o.UID != "" && e.UID != o.UID) ||
+		(!o.Since.IsZero() && e.CreateTime.Before(o.Since)) ||
+		(!o.Until.IsZero() && !e.CreateTime.Before(o.Until)) {
+		return false, nil
+	}
+
+	if o.Q != "" {
+		q := strings.ToLower(o.Q)
+		found := false
+		for _, field := range []string{e.Name, e.Namespace, e.Group, e.Version, e.Resource} {
+			if strings.Contains(strings.ToLower(field), q) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+
+	if o.Owner != "" && !(e.Kind == m.ownerKind && e.Name == m.ownerName) &&
+		!containsString(strings.Split(e.OwnerChain, ","), o.Owner) {
+		return false, nil
+	}
+
+	if len(m.requirements) > 0 {
+		set := labelsOf(e.Labels)
+		for _, r := range m.requirements {
+			if !r.Matches(set) {
+				return false, nil
+			}
+		}
+	}
+
+	if m.phrase != "" {
+		if err := e.Decode(); err != nil {
+			return false, err
+		}
+		if e.Obj == nil {
+			return false, nil
+		}
+		content, err := contentOf(e.Obj)
+		if err != nil {
+			return false, err
+		}
+		if !strings.Contains(" "+strings.Join(searchTerms(content), " ")+" ", m.phrase) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// labelsOf parses labels stored in the form of models.FormatLabels
+func labelsOf(formatted string) labels.Set {
+	set := labels.Set{}
+	for _, pair := range strings.Split(strings.Trim(formatted, ","), ",") {
+		if kv := strings.SplitN(pair, "=", 2); len(kv) == 2 {
+			set[kv[0]] = kv[1]
+		}
+	}
+	return set
+}
@@ -0,0 +1,248 @@
+package event_store
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	bolt "go.etcd.io/bbolt"
+	"time"
+)
+
+// Prune deletes events by retention policies of informers, see Store.Prune,
+// events are pruned in a transaction, BatchSize is ignored.
+func (s *BoltStore) Prune(policyOf func(cluster, informerName string) RetentionPolicy,
+	options PruneOptions) (results []PruneResult, err error) {
+	if options.Now.IsZero() {
+		options.Now = time.Now()
+	}
+
+	err = s.update(func(tx *bolt.Tx) error {
+		var informers [][]string
+		if err := scan(tx.Bucket(bucketInformers), nil, nil, false, func(k, v []byte) (bool, error) {
+			fields := indexFields(k)
+			if n := len(informers); n == 0 || informers[n-1][0] != fields[0] || informers[n-1][1] != fields[1] {
+				informers = append(informers, fields)
+			}
+			return true, nil
+		}); err != nil {
+			return errors.Wrap(err, "list informers error")
+		}
+
+		for _, informer := range informers {
+			policy := policyOf(informer[0], informer[1])
+			if policy.IsZero() {
+				continue
+			}
+			result, err := pruneInformer(tx, informer[0], informer[1], policy, options)
+			if err != nil {
+				return errors.Wrapf(err, "prune events of %s error", informer[1])
+			}
+			if result.Total() > 0 {
+				results = append(results, result)
+			}
+		}
+		if options.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err == errDryRun {
+		err = nil
+	}
+	return results, err
+}
+
+// boltEntry is an index entry of an event
+type boltEntry struct {
+	ID    uint
+	Value []byte
+}
+
+// entriesOf lists index entries with prefix, oldest first
+func entriesOf(tx *bolt.Tx, bucket, prefix []byte) ([]boltEntry, error) {
+	var entries []boltEntry
+	err := scan(tx.Bucket(bucket), prefix, nil, false, func(k, v []byte) (bool, error) {
+		entries = append(entries, boltEntry{ID: idOf(k), Value: append([]byte(nil), v...)})
+		return true, nil
+	})
+	return entries, err
+}
+
+func pruneInformer(tx *bolt.Tx, cluster, informerName string,
+	policy RetentionPolicy, options PruneOptions) (result PruneResult, err error) {
+	result.Cluster = cluster
+	result.InformerName = informerName
+
+	protected, err := protectedIDs(tx, cluster, informerName)
+	if err != nil {
+		return result, errors.Wrap(err, "list protected events error")
+	}
+	// deleteEntries deletes events of entries selected, except protected events
+	deleteEntries := func(entries []boltEntry, selected func(i int, entry boltEntry) bool) (int64, error) {
+		var deleted int64
+		for i, entry := range entries {
+			if protected[entry.ID] || !selected(i, entry) {
+				continue
+			}
+			e, err := getEvent(tx, entry.ID)
+			if err != nil {
+				return deleted, err
+			}
+			if e == nil {
+				continue
+			}
+			if err := deleteEvent(tx, e); err != nil {
+				return deleted, err
+			}
+			deleted++
+		}
+		return deleted, nil
+	}
+	informerPrefix := indexPrefix(cluster, informerName)
+
+	if policy.MaxAge > 0 {
+		entries, err := entriesOf(tx, bucketInformers, informerPrefix)
+		if err != nil {
+			return result, err
+		}
+		threshold := options.Now.Add(-policy.MaxAge)
+		result.Expired, err = deleteEntries(entries, func(i int, entry boltEntry) bool {
+			return timeOf(entry.Value).Before(threshold)
+		})
+		if err != nil {
+			return result, errors.Wrap(err, "prune expired events error")
+		}
+	}
+
+	if policy.MaxEvents > 0 {
+		entries, err := entriesOf(tx, bucketInformers, informerPrefix)
+		if err != nil {
+			return result, err
+		}
+		over := len(entries) - policy.MaxEvents
+		result.OverMaxEvents, err = deleteEntries(entries, func(i int, entry boltEntry) bool {
+			return i < over
+		})
+		if err != nil {
+			return result, errors.Wrap(err, "prune events over max events error")
+		}
+	}
+
+	if policy.MaxVersions > 0 {
+		// entries of an object are adjacent, sorted by ID
+		var keys []string
+		objects := make(map[string][]boltEntry)
+		if err := scan(tx.Bucket(bucketObjects), informerPrefix, nil, false, func(k, v []byte) (bool, error) {
+			key := string(k[:len(k)-8])
+			if _, ok := objects[key]; !ok {
+				keys = append(keys, key)
+			}
+			objects[key] = append(objects[key], boltEntry{ID: idOf(k)})
+			return true, nil
+		}); err != nil {
+			return result, errors.Wrap(err, "list objects error")
+		}
+		for _, key := range keys {
+			versions := objects[key]
+			over := len(versions) - policy.MaxVersions
+			if over <= 0 {
+				continue
+			}
+			pruned, err := deleteEntries(versions, func(i int, entry boltEntry) bool {
+				return i < over
+			})
+			result.OverMaxVersions += pruned
+			if err != nil {
+				return result, errors.Wrap(err, "prune events over max versions error")
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// protectedIDs returns IDs of the latest ADDED and TRIGGERED events of objects currently added or triggered
+func protectedIDs(tx *bolt.Tx, cluster, informerName string) (map[uint]bool, error) {
+	type latest struct {
+		id        uint
+		eventType string
+	}
+	classOf := map[string]int{
+		models.EventTypeAdded:     0,
+		models.EventTypeDeleted:   0,
+		models.EventTypeTriggered: 1,
+		models.EventTypeResolved:  1,
+	}
+	latestOf := make(map[string]*[2]latest)
+	err := scan(tx.Bucket(bucketObjects), indexPrefix(cluster, informerName), nil, false,
+		func(k, v []byte) (bool, error) {
+			class, ok := classOf[string(v)]
+			if !ok {
+				return true, nil
+			}
+			key := string(k[:len(k)-8])
+			if latestOf[key] == nil {
+				latestOf[key] = &[2]latest{}
+			}
+			latestOf[key][class] = latest{id: idOf(k), eventType: string(v)}
+			return true, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	protected := make(map[uint]bool)
+	for _, l := range latestOf {
+		for _, e := range l {
+			if e.eventType == models.EventTypeAdded || e.eventType == models.EventTypeTriggered {
+				protected[e.id] = true
+			}
+		}
+	}
+	return protected, nil
+}
+
+// Optimize does nothing, pages freed by pruning are reused by bolt, the file doesn't shrink
+func (s *BoltStore) Optimize() error {
+	return nil
+}
+
+// Convert rewrites stored objects of all events in the compression of the store,
+// objects are always stored in full in bolt.
+func (s *BoltStore) Convert(dryRun bool) (result ConvertResult, err error) {
+	objects := make(map[string]bool)
+	err = s.update(func(tx *bolt.Tx) error {
+		var converted []*models.Event
+		if err := scanEvents(tx, bucketEvents, nil, nil, false, func(e *models.Event) (bool, error) {
+			if e.Codec != s.Options.Compression {
+				converted = append(converted, e)
+			}
+			return true, nil
+		}); err != nil {
+			return err
+		}
+		for _, e := range converted {
+			if err := e.Encode(s.Options.Compression); err != nil {
+				return err
+			}
+			if err := putEvent(tx, e); err != nil {
+				return errors.Wrap(err, "update event error")
+			}
+			result.Converted++
+			objects[string(indexKey(0, e.Cluster, e.InformerName, e.Group, e.Version,
+				e.Resource, e.Namespace, e.Name, e.UID))] = true
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err == errDryRun {
+		err = nil
+	}
+	result.Objects = int64(len(objects))
+	return result, err
+}
+
+// Reindex does nothing, contents of objects are searched without an index in bolt
+func (s *BoltStore) Reindex(batchSize int) (indexed int64, err error) {
+	return 0, nil
+}
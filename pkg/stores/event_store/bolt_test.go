@@ -0,0 +1,40 @@
+package event_store
+
+import (
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+)
+
+func TestBoltReadOnly(t *testing.T) {
+	assertions := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "test.db")
+	_, err := NewBolt(path, Options{ReadOnly: true})
+	assertions.NotNil(err, "read-only files are not created")
+
+	store, err := NewBolt(path, Options{})
+	assertions.Nil(err)
+	e := &models.Event{InformerName: "w1", EventType: "ADDED", Version: "v1", Resource: "configmaps",
+		Namespace: "demo", Name: "a", Obj: []byte(`{"kind":"ConfigMap"}`)}
+	assertions.Nil(store.Save(e))
+	// the file is open for the life of the store, saved events are read without reopening it
+	found, err := store.Find(e.ID)
+	assertions.Nil(err)
+	assertions.Equal("a", found.Name)
+	assertions.Nil(store.(*BoltStore).Close())
+
+	// processes reading share the file
+	reader, err := NewBolt(path, Options{ReadOnly: true})
+	assertions.Nil(err)
+	defer reader.(*BoltStore).Close()
+	another, err := NewBolt(path, Options{ReadOnly: true})
+	assertions.Nil(err)
+	defer another.(*BoltStore).Close()
+
+	found, err = reader.Find(e.ID)
+	assertions.Nil(err)
+	assertions.Equal("a", found.Name)
+	assertions.NotNil(reader.Save(&models.Event{InformerName: "w1", EventType: "ADDED"}))
+}
@@ -0,0 +1,176 @@
+package event_store
+
+import (
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// backends are event stores every test of Interface runs against
+var backends = []struct {
+	name    string
+	dialect string
+	options Options
+}{
+	{name: "sqlite", dialect: "sqlite", options: Options{IndexContent: true}},
+	{name: "sqlite-diff", dialect: "sqlite",
+		options: Options{StorageMode: StorageModeDiff, Compression: models.CodecGzip, IndexContent: true}},
+	{name: "bolt", dialect: DialectBolt, options: Options{Compression: models.CodecGzip}},
+}
+
+// forEachBackend runs test against an empty store of every backend
+func forEachBackend(t *testing.T, test func(t *testing.T, store Interface)) {
+	for _, backend := range backends {
+		backend := backend
+		t.Run(backend.name, func(t *testing.T) {
+			store, err := Open(backend.dialect, filepath.Join(t.TempDir(), "test.db"), backend.options)
+			require.Nil(t, err)
+			if closer, ok := store.(io.Closer); ok {
+				defer closer.Close()
+			}
+			test(t, store)
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Interface) {
+		assertions := require.New(t)
+
+		e := &models.Event{InformerName: "w1", EventType: "ADDED", Version: "v1", Resource: "configmaps",
+			Namespace: "demo", Name: "a", Obj: []byte(`{"kind":"ConfigMap"}`)}
+		assertions.Nil(store.Save(e))
+		assertions.NotZero(e.ID)
+
+		found, err := store.Find(e.ID)
+		assertions.Nil(err)
+		assertions.Equal("a", found.Name)
+		assertions.JSONEq(`{"kind":"ConfigMap"}`, string(found.Obj))
+
+		_, err = store.Find(e.ID + 1)
+		assertions.Equal(gorm.ErrRecordNotFound, err)
+	})
+}
+
+func TestListCurrently(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Interface) {
+		assertions := require.New(t)
+
+		saveIn := func(namespace, eventType, name, uid string) {
+			assertions.Nil(store.Save(&models.Event{InformerName: "w1", EventType: eventType,
+				Version: "v1", Resource: "configmaps", Namespace: namespace, Name: name, UID: uid,
+				Obj: []byte(`{"kind":"ConfigMap"}`)}))
+		}
+		save := func(eventType, name string) {
+			saveIn("demo", eventType, name, name)
+		}
+		save("ADDED", "a")
+		save("ADDED", "b")
+		save("UPDATED", "a")
+		save("DELETED", "b")
+		save("TRIGGERED", "a")
+		save("TRIGGERED", "b")
+		save("RESOLVED", "b")
+		save("OCCURRED", "c")
+		// recreated, the latest ADDED event is listed with the new UID
+		save("ADDED", "d")
+		save("DELETED", "d")
+		saveIn("demo", "ADDED", "d", "d2")
+		// the same name in another namespace is another object
+		saveIn("other", "ADDED", "b", "b2")
+
+		names := func(events []models.Event, err error) []string {
+			assertions.Nil(err)
+			var names []string
+			for _, e := range events {
+				assertions.Nil(e.Obj)
+				names = append(names, e.EventType+" "+e.Name)
+			}
+			return names
+		}
+		added, err := store.ListCurrentlyAdded("", "w1", "", "v1", "configmaps")
+		assertions.Nil(err)
+		var keys []string
+		for _, e := range added {
+			keys = append(keys, e.Namespace+"/"+e.Name+"/"+e.UID)
+		}
+		assertions.ElementsMatch([]string{"demo/a/a", "demo/d/d2", "other/b/b2"}, keys)
+		assertions.Equal([]string{"TRIGGERED a"}, names(store.ListCurrentlyTriggered("", "w1", "", "v1", "configmaps")))
+		assertions.Equal([]string{"OCCURRED c"}, names(store.ListCurrentlyOccurred("", "w1", "", "v1", "configmaps")))
+		assertions.Empty(names(store.ListCurrentlyAdded("", "w2", "", "v1", "configmaps")))
+	})
+}
+
+func TestListRelated(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Interface) {
+		assertions := require.New(t)
+
+		pod := &models.Event{InformerName: "w1", EventType: "ADDED", Version: "v1", Resource: "pods",
+			Kind: "Pod", Namespace: "demo", Name: "web", Obj: []byte(`{"kind":"Pod"}`)}
+		assertions.Nil(store.Save(pod))
+		occurred := &models.Event{InformerName: "events", EventType: "OCCURRED", Version: "v1",
+			Resource: "events", Kind: "Event", Namespace: "demo", Name: "web.1",
+			InvolvedKind: "Pod", InvolvedNamespace: "demo", InvolvedName: "web"}
+		assertions.Nil(store.Save(occurred))
+		// an Event about another object
+		assertions.Nil(store.Save(&models.Event{InformerName: "events", EventType: "OCCURRED",
+			Version: "v1", Resource: "events", Kind: "Event", Namespace: "demo", Name: "other.1",
+			InvolvedKind: "Pod", InvolvedNamespace: "demo", InvolvedName: "other"}))
+
+		events, err := store.ListRelated(pod)
+		assertions.Nil(err)
+		assertions.Len(events, 1)
+		assertions.Equal(occurred.ID, events[0].ID)
+
+		events, err = store.ListRelated(occurred)
+		assertions.Nil(err)
+		assertions.Len(events, 1)
+		assertions.Equal(pod.ID, events[0].ID)
+		assertions.Nil(events[0].Obj)
+	})
+}
+
+func TestAttribute(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Interface) {
+		assertions := require.New(t)
+
+		now := time.Now()
+		save := func(eventType, resourceVersion string, age time.Duration) {
+			assertions.Nil(store.Save(&models.Event{InformerName: "w1", EventType: eventType,
+				Group: "apps", Version: "v1", Resource: "deployments", Namespace: "demo", Name: "web",
+				ResourceVersion: resourceVersion, CreateTime: now.Add(-age),
+				Obj: []byte(`{"kind":"Deployment"}`)}))
+		}
+		save("UPDATED", "1", time.Hour)
+		save("UPDATED", "2", 0)
+		save("TRIGGERED", "2", 0)
+
+		match := AuditMatch{Group: "apps", Resource: "deployments", Namespace: "demo", Name: "web",
+			EventTypes: []string{"UPDATED", "TRIGGERED"}, Time: now}
+		attributed, err := store.Attribute(match, models.Attribution{Username: "alice"})
+		assertions.Nil(err)
+		assertions.Equal(int64(2), attributed)
+
+		// events attributed already are skipped
+		attributed, err = store.Attribute(match, models.Attribution{Username: "bob"})
+		assertions.Nil(err)
+		assertions.Zero(attributed)
+
+		match.ResourceVersion = "1"
+		attributed, err = store.Attribute(match, models.Attribution{Username: "bob"})
+		assertions.Nil(err)
+		assertions.Equal(int64(1), attributed)
+
+		events, err := store.List(ListOptions{Name: "web"})
+		assertions.Nil(err)
+		var usernames []string
+		for _, e := range events {
+			usernames = append(usernames, e.Username)
+		}
+		assertions.Equal([]string{"alice", "alice", "bob"}, usernames)
+	})
+}
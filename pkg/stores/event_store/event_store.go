@@ -1,63 +1,78 @@
 package event_store
 
 import (
-	"fmt"
 	"github.com/pkg/errors"
+	"github.com/spongeprojects/kubebigbrother/pkg/gormdb"
 	"github.com/spongeprojects/kubebigbrother/pkg/models"
 	"gorm.io/gorm"
 	"k8s.io/klog/v2"
+	"time"
 )
 
-type ListOptions struct {
-	InformerName string
-	Q            string
-	After        uint
+// AuditMatch identifies events caused by a request in audit logs
+type AuditMatch struct {
+	Cluster   string
+	Group     string
+	Resource  string
+	Namespace string
+	Name      string
+
+	// EventTypes are types of events the request may cause, e.g. ADDED for create
+	EventTypes []string
+
+	// ResourceVersion is the resourceVersion of the object after the request, empty when unknown,
+	// the first event in [Time - auditClockSkew, Time + auditWindow] is matched when unknown.
+	ResourceVersion string
+	Time            time.Time
 }
 
+const (
+	// auditClockSkew tolerates clock difference between the API server and the controller
+	auditClockSkew = 5 * time.Second
+
+	// auditWindow is how long after a request its events are expected to be recorded
+	auditWindow = time.Minute
+)
+
 type Interface interface {
 	Find(id uint) (event *models.Event, err error)
 	List(options ListOptions) (events []models.Event, err error)
-	IsCurrentlyAdded(informerName,
-		group, version, resource, namespace, name string) (exist bool, err error)
+	ListPage(options ListOptions) (page *Page, err error)
+	ListCurrentlyAdded(cluster, informerName,
+		group, version, resource string) (events []models.Event, err error)
+	ListCurrentlyTriggered(cluster, informerName,
+		group, version, resource string) (events []models.Event, err error)
+	ListCurrentlyOccurred(cluster, informerName,
+		group, version, resource string) (events []models.Event, err error)
+	ListRelated(event *models.Event) (events []models.Event, err error)
+	History(options HistoryOptions) (versions []Version, err error)
+	Snapshot(options SnapshotOptions, at time.Time) (events []models.Event, err error)
+	DiffSnapshots(options SnapshotOptions, from, to time.Time) (diff *SnapshotDiff, err error)
+	Attribute(match AuditMatch, attribution models.Attribution) (attributed int64, err error)
 	Save(event *models.Event) (err error)
 	SaveSilently(event *models.Event)
+	Prune(policyOf func(cluster, informerName string) RetentionPolicy,
+		options PruneOptions) (results []PruneResult, err error)
+	Optimize() error
+	Convert(dryRun bool) (result ConvertResult, err error)
+	Reindex(batchSize int) (indexed int64, err error)
 }
 
 type Store struct {
-	DB *gorm.DB
+	DB      *gorm.DB
+	Options Options
 }
 
 func (s *Store) Find(id uint) (event *models.Event, err error) {
-	err = s.DB.First(&event, id).Error
-	return
-}
-
-func (s *Store) List(options ListOptions) (events []models.Event, err error) {
-	query := s.DB
-
-	if options.InformerName != "" {
-		query = query.Where("informer_name = ?", options.InformerName)
-	}
-
-	if options.Q != "" {
-		l := fmt.Sprintf("%%%s%%", options.Q)
-		query = query.Where("name like ?", l).
-			Or("namespace like ?", l).
-			Or("event_group like ?", l).
-			Or("version like ?", l).
-			Or("resource like ?", l)
+	if err = s.DB.First(&event, id).Error; err != nil {
+		return nil, err
 	}
-
-	if options.After != 0 {
-		query = query.Where("id > ?", options.After)
-	}
-
-	err = query.Order("id desc").Limit(50).Find(&events).Error
+	err = s.resolve(event)
 	return
 }
 
 func (s *Store) Save(event *models.Event) error {
-	return s.DB.Save(event).Error
+	return s.save(event)
 }
 
 func (s *Store) SaveSilently(event *models.Event) {
@@ -66,30 +81,147 @@ func (s *Store) SaveSilently(event *models.Event) {
 	}
 }
 
-func (s *Store) IsCurrentlyAdded(informerName,
-	group, version, resource, namespace, name string) (yes bool, err error) {
-	var e models.Event
-	if err := s.DB.Where("informer_name = ?", informerName).
-		// TODO: use event.EventType ADDED and DELETED without import loop
-		Where("event_type in ?", []string{"ADDED", "DELETED"}).
+// ListCurrentlyAdded lists the latest ADDED events of all resources currently added,
+// in a single query, objects are omitted to keep the result compact.
+func (s *Store) ListCurrentlyAdded(cluster, informerName,
+	group, version, resource string) (events []models.Event, err error) {
+	return s.listLatest(cluster, informerName, group, version, resource,
+		[]string{models.EventTypeAdded, models.EventTypeDeleted}, models.EventTypeAdded)
+}
+
+// ListCurrentlyTriggered lists the latest TRIGGERED events of all resources currently triggered,
+// in a single query, objects are omitted to keep the result compact.
+func (s *Store) ListCurrentlyTriggered(cluster, informerName,
+	group, version, resource string) (events []models.Event, err error) {
+	return s.listLatest(cluster, informerName, group, version, resource,
+		[]string{models.EventTypeTriggered, models.EventTypeResolved}, models.EventTypeTriggered)
+}
+
+// ListCurrentlyOccurred lists the latest OCCURRED events of all Kubernetes Events noticed,
+// in a single query, objects are omitted to keep the result compact.
+func (s *Store) ListCurrentlyOccurred(cluster, informerName,
+	group, version, resource string) (events []models.Event, err error) {
+	return s.listLatest(cluster, informerName, group, version, resource,
+		[]string{models.EventTypeOccurred}, models.EventTypeOccurred)
+}
+
+// listLatest finds the latest event among eventTypes for every resource,
+// returns those whose type is currentType
+func (s *Store) listLatest(cluster, informerName, group, version, resource string,
+	eventTypes []string, currentType string) (events []models.Event, err error) {
+	latest := s.DB.Model(&models.Event{}).
+		Select("max(id)").
+		Where("cluster = ?", cluster).
+		Where("informer_name = ?", informerName).
+		Where("event_type in ?", eventTypes).
 		Where("event_group = ?", group).
 		Where("version = ?", version).
 		Where("resource = ?", resource).
-		Where("namespace = ?", namespace).
-		Where("name = ?", name).
-		Order("create_time desc").
-		First(&e).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return false, nil
+		Group("namespace, name")
+	err = s.DB.Omit("obj", "old_obj").
+		Where("id in (?)", latest).
+		Where("event_type = ?", currentType).
+		Find(&events).Error
+	return
+}
+
+// ListRelated links Kubernetes Events and events of the objects they are about,
+// for an OCCURRED event, events of its involved object are listed,
+// for other events, OCCURRED events involving the object are listed.
+// objects are omitted to keep the result compact.
+func (s *Store) ListRelated(event *models.Event) (events []models.Event, err error) {
+	query := s.DB.Omit("obj", "old_obj").Where("cluster = ?", event.Cluster)
+
+	if event.EventType == models.EventTypeOccurred {
+		query = query.
+			Where("event_type <> ?", models.EventTypeOccurred).
+			Where("kind = ?", event.InvolvedKind).
+			Where("namespace = ?", event.InvolvedNamespace).
+			Where("name = ?", event.InvolvedName)
+	} else {
+		query = query.
+			Where("event_type = ?", models.EventTypeOccurred).
+			Where("involved_kind = ?", event.Kind).
+			Where("involved_namespace = ?", event.Namespace).
+			Where("involved_name = ?", event.Name)
+	}
+
+	err = query.Order("id desc").Limit(50).Find(&events).Error
+	return
+}
+
+// Attribute records who made the change on events matching an audit record,
+// events attributed already are skipped, manager from managedFields is kept.
+func (s *Store) Attribute(match AuditMatch, attribution models.Attribution) (int64, error) {
+	resourceVersion := match.ResourceVersion
+	if resourceVersion == "" {
+		var e models.Event
+		err := s.unattributed(match).Omit("obj", "old_obj").
+			Where("create_time between ? and ?",
+				match.Time.Add(-auditClockSkew), match.Time.Add(auditWindow)).
+			Order("create_time").
+			First(&e).Error
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return 0, nil
+			}
+			return 0, err
 		}
-		return false, err
+		// events of the same change share the resourceVersion, e.g. UPDATED and TRIGGERED
+		resourceVersion = e.ResourceVersion
 	}
-	// the latest event type is ADDED means the kind is in cache
-	return e.EventType == "ADDED", nil
+
+	result := s.unattributed(match).
+		Where("resource_version = ?", resourceVersion).
+		Updates(map[string]interface{}{
+			"username":    attribution.Username,
+			"user_groups": attribution.UserGroups,
+			"user_agent":  attribution.UserAgent,
+			"source_ips":  attribution.SourceIPs,
+		})
+	return result.RowsAffected, result.Error
 }
 
+// unattributed queries events of the object in match not attributed yet
+func (s *Store) unattributed(match AuditMatch) *gorm.DB {
+	return s.DB.Model(&models.Event{}).
+		Where("cluster = ?", match.Cluster).
+		Where("event_group = ?", match.Group).
+		Where("resource = ?", match.Resource).
+		Where("namespace = ?", match.Namespace).
+		Where("name = ?", match.Name).
+		Where("event_type in ?", match.EventTypes).
+		Where("username = ?", "")
+}
+
+// New creates Store storing full objects
 func New(db *gorm.DB) Interface {
 	return &Store{
-		DB: db,
+		DB:      db,
+		Options: Options{StorageMode: StorageModeFull, SnapshotInterval: DefaultSnapshotInterval},
+	}
+}
+
+// NewWithOptions creates Store with options, e.g. to store objects as diffs
+func NewWithOptions(db *gorm.DB, options Options) (Interface, error) {
+	if err := options.complete(); err != nil {
+		return nil, err
+	}
+	return &Store{
+		DB:      db,
+		Options: options,
+	}, nil
+}
+
+// Open creates the event store of the dialect, e.g. sqlite or bolt,
+// pending migrations are applied to SQL databases.
+func Open(dialect, dsn string, options Options) (Interface, error) {
+	if dialect == DialectBolt {
+		return NewBolt(dsn, options)
+	}
+	db, err := gormdb.New(dialect, dsn)
+	if err != nil {
+		return nil, err
 	}
+	return NewWithOptions(db, options)
 }
@@ -0,0 +1,40 @@
+package event_store
+
+import (
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestListByOwner(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Interface) {
+		assertions := require.New(t)
+
+		for _, e := range []*models.Event{
+			{Kind: "Deployment", Namespace: "demo", Name: "canary"},
+			{Kind: "ReplicaSet", Namespace: "demo", Name: "canary-5d4b7c",
+				OwnerChain: "Deployment/canary"},
+			{Kind: "Pod", Namespace: "demo", Name: "canary-5d4b7c-x2k9p",
+				OwnerChain: "ReplicaSet/canary-5d4b7c,Deployment/canary"},
+			{Kind: "Pod", Namespace: "demo", Name: "canary-2-6f8d9b-p7x4q",
+				OwnerChain: "ReplicaSet/canary-2-6f8d9b,Deployment/canary-2"},
+		} {
+			assertions.Nil(store.Save(e))
+		}
+
+		events, err := store.List(ListOptions{Owner: "Deployment/canary"})
+		assertions.Nil(err)
+		assertions.Len(events, 3)
+
+		events, err = store.List(ListOptions{Owner: "ReplicaSet/canary-5d4b7c"})
+		assertions.Nil(err)
+		assertions.Len(events, 2)
+
+		events, err = store.List(ListOptions{Owner: "Deployment/canary", Q: "x2k9p"})
+		assertions.Nil(err)
+		assertions.Len(events, 1)
+
+		_, err = store.List(ListOptions{Owner: "canary"})
+		assertions.NotNil(err)
+	})
+}
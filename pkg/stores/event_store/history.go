@@ -0,0 +1,161 @@
+package event_store
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// DefaultHistoryLimit is the number of events of an object in a history by default
+	DefaultHistoryLimit = 100
+
+	// MaxHistoryLimit is the max number of events of an object in a history
+	MaxHistoryLimit = 1000
+)
+
+// HistoryOptions identifies an object, its events across informers are listed
+type HistoryOptions struct {
+	Cluster   string
+	Group     string
+	Resource  string
+	Namespace string
+	Name      string
+
+	// Limit is the number of latest events listed, DefaultHistoryLimit when 0,
+	// versions are fewer when events are deduplicated.
+	Limit int
+}
+
+// Validate returns error if options are invalid
+func (o *HistoryOptions) Validate() error {
+	if o.Resource == "" || o.Name == "" {
+		return errors.New("resource and name are required")
+	}
+	if o.Limit < 0 || o.Limit > MaxHistoryLimit {
+		return errors.Errorf("limit should be in [1, %d]", MaxHistoryLimit)
+	}
+	return nil
+}
+
+// Version is a version of an object in its history
+type Version struct {
+	// Event is the first event recording the version, objects are omitted
+	Event models.Event `json:"event"`
+
+	// Diff is the unified diff of the object in YAML from the previous version,
+	// the whole object is added when there is no previous version, e.g. ADDED after DELETED.
+	Diff string `json:"diff"`
+
+	// Actor is who made the change, nil when unknown
+	Actor *models.Attribution `json:"actor,omitempty"`
+}
+
+// History lists versions of an object, oldest first, events of informers watching the object
+// are deduplicated by resourceVersion, DELETED events are kept as versions of their own.
+func (s *Store) History(options HistoryOptions) ([]Version, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	limit := options.Limit
+	if limit == 0 {
+		limit = DefaultHistoryLimit
+	}
+
+	var events []models.Event
+	if err := s.DB.
+		Where("cluster = ?", options.Cluster).
+		Where("namespace = ?", options.Namespace).
+		Where("name = ?", options.Name).
+		Where("resource = ?", options.Resource).
+		Where("event_group = ?", options.Group).
+		Where("event_type <> ?", models.EventTypeOccurred).
+		Where("obj is not null").
+		Order("id desc").
+		Limit(limit).
+		Find(&events).Error; err != nil {
+		return nil, errors.Wrap(err, "list events error")
+	}
+
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+	for i := range events {
+		if err := s.resolve(&events[i]); err != nil {
+			return nil, err
+		}
+	}
+	return historyOf(events)
+}
+
+// historyOf builds versions from events of an object with full objects, oldest first
+func historyOf(events []models.Event) ([]Version, error) {
+	var versions []Version
+	indexOf := make(map[string]int)
+	var previous *models.Event
+	for i := range events {
+		e := &events[i]
+		key := versionKey(e)
+		if j, ok := indexOf[key]; ok && key != "" {
+			// the first event recording the version may not be attributed
+			if versions[j].Actor == nil {
+				versions[j].Actor = actorOf(e)
+			}
+			continue
+		}
+
+		oldObj := e.OldObj
+		if previous != nil {
+			oldObj = previous.Obj
+		}
+		if e.EventType == models.EventTypeAdded && (previous == nil || previous.EventType == models.EventTypeDeleted) {
+			oldObj = nil
+		}
+		d, err := diffObjects(oldObj, e.Obj)
+		if err != nil {
+			return nil, errors.Wrapf(err, "diff event %d error", e.ID)
+		}
+		previous = e
+
+		version := Version{Event: *e, Diff: d, Actor: actorOf(e)}
+		version.Event.Obj = nil
+		version.Event.OldObj = nil
+		indexOf[key] = len(versions)
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+// versionKey identifies the version recorded by an event, empty when unknown
+func versionKey(e *models.Event) string {
+	if e.ResourceVersion == "" {
+		return ""
+	}
+	// the object of a DELETED event may be the last known state, sharing resourceVersion with it
+	if e.EventType == models.EventTypeDeleted {
+		return "DELETED/" + e.ResourceVersion
+	}
+	return e.ResourceVersion
+}
+
+// actorOf returns who made the change of event, nil when unknown
+func actorOf(e *models.Event) *models.Attribution {
+	if e.Username == "" && e.Manager == "" {
+		return nil
+	}
+	attribution := e.Attribution
+	return &attribution
+}
+
+// unmarshal unmarshals raw JSON of an object, nil when b is nil
+func unmarshal(b []byte) (*unstructured.Unstructured, error) {
+	if b == nil {
+		return nil, nil
+	}
+	obj := &unstructured.Unstructured{}
+	if err := json.Unmarshal(b, &obj.Object); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
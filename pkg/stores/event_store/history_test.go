@@ -0,0 +1,68 @@
+package event_store
+
+import (
+	"fmt"
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Interface) {
+		assertions := require.New(t)
+
+		deployment := func(resourceVersion string, replicas int) []byte {
+			return []byte(fmt.Sprintf(`{"apiVersion":"apps/v1","kind":"Deployment",`+
+				`"metadata":{"namespace":"prod","name":"api","resourceVersion":"%s"},"spec":{"replicas":%d}}`,
+				resourceVersion, replicas))
+		}
+		save := func(informerName, eventType, resourceVersion string, obj, oldObj []byte,
+			attribution models.Attribution) {
+			assertions.Nil(store.Save(&models.Event{InformerName: informerName, EventType: eventType,
+				Group: "apps", Version: "v1", Resource: "deployments", Kind: "Deployment",
+				Namespace: "prod", Name: "api", UID: "u1", ResourceVersion: resourceVersion,
+				Obj: obj, OldObj: oldObj, Attribution: attribution}))
+		}
+		save("w1", "ADDED", "1", deployment("1", 1), nil, models.Attribution{})
+		save("w2", "ADDED", "1", deployment("1", 1), nil, models.Attribution{})
+		save("w1", "UPDATED", "2", deployment("2", 3), deployment("1", 1), models.Attribution{})
+		// the same change noticed by another informer, attributed later
+		save("w2", "UPDATED", "2", deployment("2", 3), deployment("1", 1), models.Attribution{Username: "alice"})
+		save("w1", "DELETED", "2", deployment("2", 3), nil, models.Attribution{Manager: "kubectl"})
+		save("w1", "ADDED", "3", deployment("3", 2), nil, models.Attribution{})
+		// events of other objects are not listed
+		assertions.Nil(store.Save(&models.Event{InformerName: "w1", EventType: "ADDED", Group: "apps",
+			Resource: "deployments", Namespace: "prod", Name: "web", ResourceVersion: "4", Obj: deployment("4", 1)}))
+
+		versions, err := store.History(HistoryOptions{Group: "apps", Resource: "deployments",
+			Namespace: "prod", Name: "api"})
+		assertions.Nil(err)
+		var types []string
+		for _, v := range versions {
+			types = append(types, v.Event.EventType+" "+v.Event.ResourceVersion)
+			assertions.Nil(v.Event.Obj)
+		}
+		assertions.Equal([]string{"ADDED 1", "UPDATED 2", "DELETED 2", "ADDED 3"}, types)
+
+		assertions.Contains(versions[0].Diff, "+  replicas: 1\n")
+		assertions.Nil(versions[0].Actor)
+		assertions.Contains(versions[1].Diff, "-  replicas: 1\n")
+		assertions.Contains(versions[1].Diff, "+  replicas: 3\n")
+		assertions.Equal("alice", versions[1].Actor.Username)
+		assertions.Empty(versions[2].Diff)
+		assertions.Equal("kubectl", versions[2].Actor.Manager)
+		// recreated after deleted, the whole object is added
+		assertions.Contains(versions[3].Diff, "+  replicas: 2\n")
+		assertions.Contains(versions[3].Diff, "+kind: Deployment\n")
+
+		// the oldest version in the limit is diffed from the old object of its event
+		versions, err = store.History(HistoryOptions{Group: "apps", Resource: "deployments",
+			Namespace: "prod", Name: "api", Limit: 4})
+		assertions.Nil(err)
+		assertions.Len(versions, 3)
+		assertions.Contains(versions[0].Diff, "-  replicas: 1\n")
+
+		_, err = store.History(HistoryOptions{Resource: "deployments"})
+		assertions.NotNil(err)
+	})
+}
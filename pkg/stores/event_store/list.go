@@ -0,0 +1,411 @@
+package event_store
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	"gorm.io/gorm"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultListLimit is the number of events in a page by default
+	DefaultListLimit = 50
+
+	// MaxListLimit is the max number of events in a page
+	MaxListLimit = 500
+)
+
+// ListOptions filters events, all filters set must match, zero values match all
+type ListOptions struct {
+	Cluster      string
+	InformerName string
+
+	// Q matches name, namespace, group, version or resource partially
+	Q string
+
+	// After matches events with ID greater than it
+	After uint
+
+	// Owner is kind and name of a resource, e.g. Deployment/foo,
+	// events of the resource and resources owned by it at any depth are matched
+	Owner string
+
+	// EventTypes matches any of event types, e.g. ADDED
+	EventTypes []string
+
+	Kind string
+
+	// GroupResource is resource and group of events, e.g. deployments.apps, pods
+	GroupResource string
+
+	// Namespace and Name match the object exactly
+	Namespace string
+	Name      string
+	UID       string
+
+	// Since and Until match events created in [Since, Until)
+	Since time.Time
+	Until time.Time
+
+	// Content matches events with objects containing the phrase, e.g. a hostname or an image,
+	// matched lines are set to Highlights of events, only events indexed can be matched.
+	Content string
+
+	// LabelSelector matches labels of the object, e.g. app=foo,tier in (web, api),
+	// only events recorded with labels can be matched.
+	LabelSelector string
+
+	// Limit is the number of events in a page, DefaultListLimit when 0
+	Limit int
+
+	// Cursor is NextCursor or PrevCursor of a page, the latest page when empty
+	Cursor string
+
+	// CountTotal counts events matched by filters, which is expensive on large tables
+	CountTotal bool
+
+	// OmitObjects omits Obj and OldObj, so that objects stored as patches are not resolved
+	OmitObjects bool
+}
+
+// Validate returns error if options are invalid, e.g. invalid cursor
+func (o *ListOptions) Validate() error {
+	if o.Limit < 0 || o.Limit > MaxListLimit {
+		return errors.Errorf("limit should be in [1, %d]", MaxListLimit)
+	}
+	if _, err := parseCursor(o.Cursor); err != nil {
+		return err
+	}
+	if o.Owner != "" {
+		if _, _, err := parseOwner(o.Owner); err != nil {
+			return err
+		}
+	}
+	if o.LabelSelector != "" {
+		if _, err := parseLabelSelector(o.LabelSelector); err != nil {
+			return err
+		}
+	}
+	if o.Content != "" && len(searchTerms(o.Content)) == 0 {
+		return errors.Errorf("invalid content search, no words found: %s", o.Content)
+	}
+	return nil
+}
+
+// Page is a page of events, latest first
+type Page struct {
+	Events []models.Event `json:"events"`
+
+	// Total is the number of events matched, nil when not counted
+	Total *int64 `json:"total,omitempty"`
+
+	// NextCursor lists older events, empty when there are no more
+	NextCursor string `json:"next_cursor,omitempty"`
+
+	// PrevCursor lists newer events, set when the page is not empty, so that it can be polled for new events
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// cursor points to an event, events older or newer than it are listed
+type cursor struct {
+	ID    uint
+	Newer bool
+}
+
+func (c cursor) String() string {
+	direction := "o"
+	if c.Newer {
+		direction = "n"
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%s%d", direction, c.ID)))
+}
+
+func parseCursor(s string) (*cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) < 2 || (b[0] != 'o' && b[0] != 'n') {
+		return nil, errors.Errorf("invalid cursor: %s", s)
+	}
+	id, err := strconv.ParseUint(string(b[1:]), 10, 64)
+	if err != nil {
+		return nil, errors.Errorf("invalid cursor: %s", s)
+	}
+	return &cursor{ID: uint(id), Newer: b[0] == 'n'}, nil
+}
+
+// List lists a page of events, latest first
+func (s *Store) List(options ListOptions) (events []models.Event, err error) {
+	page, err := s.ListPage(options)
+	if err != nil {
+		return nil, err
+	}
+	return page.Events, nil
+}
+
+// ListPage lists a page of events, latest first, with cursors of adjacent pages
+func (s *Store) ListPage(options ListOptions) (*Page, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	limit := options.Limit
+	if limit == 0 {
+		limit = DefaultListLimit
+	}
+	c, _ := parseCursor(options.Cursor)
+
+	query, err := s.filter(options)
+	if err != nil {
+		return nil, err
+	}
+	if options.OmitObjects {
+		query = query.Omit("obj", "old_obj")
+	}
+	switch {
+	case c == nil:
+		query = query.Order("id desc")
+	case c.Newer:
+		query = query.Where("id > ?", c.ID).Order("id")
+	default:
+		query = query.Where("id < ?", c.ID).Order("id desc")
+	}
+
+	var events []models.Event
+	if err := query.Limit(limit + 1).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	page := newPage(events, limit, c, options.Cursor)
+
+	if options.CountTotal {
+		query, err := s.filter(options)
+		if err != nil {
+			return nil, err
+		}
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			return nil, errors.Wrap(err, "count events error")
+		}
+		page.Total = &total
+	}
+
+	if options.Content != "" {
+		if err := s.highlightContents(page.Events, options.Content); err != nil {
+			return nil, err
+		}
+	}
+
+	if !options.OmitObjects {
+		for i := range page.Events {
+			if err := s.resolve(&page.Events[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return page, nil
+}
+
+// newPage builds a page of events listed from the cursor, latest first when listing older events,
+// oldest first when listing newer events, with an extra event when there are more.
+func newPage(events []models.Event, limit int, c *cursor, cursorString string) *Page {
+	more := len(events) > limit
+	if more {
+		events = events[:limit]
+	}
+	if c != nil && c.Newer {
+		for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+			events[i], events[j] = events[j], events[i]
+		}
+	}
+
+	page := &Page{Events: events}
+	if len(events) > 0 {
+		page.PrevCursor = cursor{ID: events[0].ID, Newer: true}.String()
+		// listing newer events, the event of the cursor is older
+		if more || (c != nil && c.Newer) {
+			page.NextCursor = cursor{ID: events[len(events)-1].ID}.String()
+		}
+	} else if c != nil && c.Newer {
+		page.PrevCursor = cursorString
+	}
+	return page
+}
+
+// filter queries events matched by filters of options, conditions are grouped,
+// so that OR in a filter doesn't escape other filters.
+func (s *Store) filter(options ListOptions) (*gorm.DB, error) {
+	query := s.DB.Model(&models.Event{})
+
+	if options.Cluster != "" {
+		query = query.Where("cluster = ?", options.Cluster)
+	}
+
+	if options.InformerName != "" {
+		query = query.Where("informer_name = ?", options.InformerName)
+	}
+
+	if options.Q != "" {
+		// wildcards in Q are matched literally
+		l := fmt.Sprintf("%%%s%%", likeEscaper.Replace(options.Q))
+		query = query.Where(s.DB.Where("name like ? escape '!'", l).
+			Or("namespace like ? escape '!'", l).
+			Or("event_group like ? escape '!'", l).
+			Or("version like ? escape '!'", l).
+			Or("resource like ? escape '!'", l))
+	}
+
+	if options.After != 0 {
+		query = query.Where("id > ?", options.After)
+	}
+
+	if options.Owner != "" {
+		ownerQuery, err := ownerCondition(s.DB, options.Owner)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(ownerQuery)
+	}
+
+	if len(options.EventTypes) > 0 {
+		query = query.Where("event_type in ?", options.EventTypes)
+	}
+
+	if options.Kind != "" {
+		query = query.Where("kind = ?", options.Kind)
+	}
+
+	if options.GroupResource != "" {
+		gr := schema.ParseGroupResource(options.GroupResource)
+		query = query.Where("event_group = ?", gr.Group).Where("resource = ?", gr.Resource)
+	}
+
+	if options.Namespace != "" {
+		query = query.Where("namespace = ?", options.Namespace)
+	}
+
+	if options.Name != "" {
+		query = query.Where("name = ?", options.Name)
+	}
+
+	if options.UID != "" {
+		query = query.Where("uid = ?", options.UID)
+	}
+
+	if !options.Since.IsZero() {
+		query = query.Where("create_time >= ?", options.Since)
+	}
+
+	if !options.Until.IsZero() {
+		query = query.Where("create_time < ?", options.Until)
+	}
+
+	if options.Content != "" {
+		contentQuery, err := contentCondition(s.DB, options.Content)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(contentQuery)
+	}
+
+	if options.LabelSelector != "" {
+		labelQuery, err := labelCondition(s.DB, options.LabelSelector)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(labelQuery)
+	}
+
+	return query, nil
+}
+
+func parseOwner(owner string) (kind, name string, err error) {
+	parts := strings.SplitN(owner, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", errors.Errorf("invalid owner, expect kind/name: %s", owner)
+	}
+	return parts[0], parts[1], nil
+}
+
+// ownerCondition matches events of the owner and resources owned by it,
+// the owner can be anywhere in the comma separated owner chain.
+func ownerCondition(db *gorm.DB, owner string) (*gorm.DB, error) {
+	kind, name, err := parseOwner(owner)
+	if err != nil {
+		return nil, err
+	}
+	return db.Where("kind = ? and name = ?", kind, name).
+		Or("owner_chain = ?", owner).
+		Or("owner_chain like ?", owner+",%").
+		Or("owner_chain like ?", "%,"+owner).
+		Or("owner_chain like ?", "%,"+owner+",%"), nil
+}
+
+// likeEscaper escapes wildcards in LIKE patterns, with ESCAPE '!' supported by all dialects
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// parseLabelSelector parses a label selector, operators not supported by labelCondition are rejected
+func parseLabelSelector(selector string) ([]labels.Requirement, error) {
+	parsed, err := labels.Parse(selector)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid label selector")
+	}
+	requirements, _ := parsed.Requirements()
+	for _, r := range requirements {
+		switch r.Operator() {
+		case selection.Equals, selection.DoubleEquals, selection.In,
+			selection.NotEquals, selection.NotIn, selection.Exists, selection.DoesNotExist:
+		default:
+			return nil, errors.Errorf("unsupported operator in label selector: %s", r.Operator())
+		}
+	}
+	return requirements, nil
+}
+
+// labelPattern matches a label in labels stored in the form of models.FormatLabels,
+// or a label key when value is nil.
+func labelPattern(key string, value *string) string {
+	if value == nil {
+		return "%," + likeEscaper.Replace(key) + "=%"
+	}
+	return "%," + likeEscaper.Replace(key+"="+*value) + ",%"
+}
+
+// labelCondition matches labels stored in the form of models.FormatLabels
+func labelCondition(db *gorm.DB, selector string) (*gorm.DB, error) {
+	requirements, err := parseLabelSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	const like = "labels like ? escape '!'"
+	const notLike = "labels not like ? escape '!'"
+	query := db
+	for _, r := range requirements {
+		key := r.Key()
+		values := r.Values().List()
+		switch r.Operator() {
+		case selection.Equals, selection.DoubleEquals, selection.In:
+			anyOf := db.Where(like, labelPattern(key, &values[0]))
+			for i := range values[1:] {
+				anyOf = anyOf.Or(like, labelPattern(key, &values[i+1]))
+			}
+			query = query.Where(anyOf)
+		case selection.NotEquals, selection.NotIn:
+			for i := range values {
+				query = query.Where(notLike, labelPattern(key, &values[i]))
+			}
+		case selection.Exists:
+			query = query.Where(like, labelPattern(key, nil))
+		case selection.DoesNotExist:
+			query = query.Where(notLike, labelPattern(key, nil))
+		}
+	}
+	return query, nil
+}
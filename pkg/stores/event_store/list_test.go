@@ -0,0 +1,218 @@
+package event_store
+
+import (
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestListFilters(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Interface) {
+		assertions := require.New(t)
+
+		now := time.Now()
+		for _, e := range []*models.Event{
+			{InformerName: "w1", EventType: "ADDED", Group: "apps", Version: "v1", Resource: "deployments",
+				Kind: "Deployment", Namespace: "demo", Name: "web", UID: "u1",
+				Labels:     models.FormatLabels(map[string]string{"app": "web", "tier": "frontend"}),
+				CreateTime: now.Add(-2 * time.Hour)},
+			{InformerName: "w1", EventType: "UPDATED", Group: "apps", Version: "v1", Resource: "deployments",
+				Kind: "Deployment", Namespace: "demo", Name: "web", UID: "u1",
+				Labels:     models.FormatLabels(map[string]string{"app": "web", "tier": "frontend"}),
+				CreateTime: now.Add(-time.Hour)},
+			{InformerName: "w1", EventType: "ADDED", Version: "v1", Resource: "pods",
+				Kind: "Pod", Namespace: "demo", Name: "web-x", UID: "u2",
+				Labels:     models.FormatLabels(map[string]string{"app": "web_x"}),
+				CreateTime: now},
+			{InformerName: "w2", EventType: "ADDED", Version: "v1", Resource: "pods",
+				Kind: "Pod", Namespace: "other", Name: "web-y", UID: "u3",
+				Labels:     models.FormatLabels(map[string]string{"app": "webax"}),
+				CreateTime: now},
+		} {
+			assertions.Nil(store.Save(e))
+		}
+
+		names := func(options ListOptions) []string {
+			events, err := store.List(options)
+			assertions.Nil(err)
+			var names []string
+			for _, e := range events {
+				names = append(names, e.EventType+" "+e.Name)
+			}
+			return names
+		}
+
+		// Q is grouped, it doesn't escape the informer filter
+		assertions.Equal([]string{"ADDED web-x", "UPDATED web", "ADDED web"},
+			names(ListOptions{InformerName: "w1", Q: "web"}))
+		// wildcards in Q are matched literally
+		assertions.Empty(names(ListOptions{Q: "web_"}))
+		assertions.Empty(names(ListOptions{Q: "%"}))
+		assertions.Equal([]string{"ADDED web-x", "ADDED web"},
+			names(ListOptions{InformerName: "w1", EventTypes: []string{"ADDED"}}))
+		assertions.Equal([]string{"UPDATED web", "ADDED web"},
+			names(ListOptions{GroupResource: "deployments.apps", Kind: "Deployment"}))
+		assertions.Equal([]string{"ADDED web-y", "ADDED web-x"}, names(ListOptions{GroupResource: "pods"}))
+		assertions.Equal([]string{"ADDED web-y"}, names(ListOptions{Namespace: "other"}))
+		assertions.Equal([]string{"UPDATED web", "ADDED web"}, names(ListOptions{Name: "web"}))
+		assertions.Equal([]string{"ADDED web-x"}, names(ListOptions{UID: "u2"}))
+		assertions.Equal([]string{"UPDATED web"}, names(ListOptions{
+			Since: now.Add(-90 * time.Minute), Until: now.Add(-time.Minute)}))
+
+		// wildcards in labels are matched literally
+		assertions.Equal([]string{"ADDED web-x"}, names(ListOptions{LabelSelector: "app=web_x"}))
+		assertions.Equal([]string{"ADDED web-y", "ADDED web-x"},
+			names(ListOptions{LabelSelector: "app in (web_x, webax)"}))
+		assertions.Equal([]string{"UPDATED web", "ADDED web"}, names(ListOptions{LabelSelector: "tier"}))
+		assertions.Equal([]string{"ADDED web-y", "ADDED web-x"}, names(ListOptions{LabelSelector: "!tier"}))
+		assertions.Equal([]string{"ADDED web-y", "UPDATED web", "ADDED web"},
+			names(ListOptions{LabelSelector: "app!=web_x"}))
+		assertions.Equal([]string{"UPDATED web", "ADDED web"},
+			names(ListOptions{LabelSelector: "app=web,tier=frontend"}))
+
+		for _, options := range []ListOptions{
+			{LabelSelector: "replicas>1"},
+			{LabelSelector: "app in ("},
+			{Cursor: "invalid"},
+			{Limit: MaxListLimit + 1},
+			{Owner: "web"},
+		} {
+			_, err := store.ListPage(options)
+			assertions.NotNil(err, "%+v", options)
+		}
+	})
+}
+
+func TestListPage(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Interface) {
+		assertions := require.New(t)
+
+		for i := 0; i < 5; i++ {
+			assertions.Nil(store.Save(&models.Event{InformerName: "w1", EventType: "ADDED",
+				Obj: []byte(`{"kind":"ConfigMap"}`)}))
+		}
+		assertions.Nil(store.Save(&models.Event{InformerName: "w2", EventType: "ADDED"}))
+
+		ids := func(page *Page) []uint {
+			var ids []uint
+			for _, e := range page.Events {
+				ids = append(ids, e.ID)
+			}
+			return ids
+		}
+
+		page, err := store.ListPage(ListOptions{InformerName: "w1", Limit: 2, CountTotal: true})
+		assertions.Nil(err)
+		assertions.Equal([]uint{5, 4}, ids(page))
+		assertions.Equal(int64(5), *page.Total)
+		assertions.NotEmpty(page.Events[0].Obj)
+
+		next, err := store.ListPage(ListOptions{InformerName: "w1", Limit: 2, Cursor: page.NextCursor})
+		assertions.Nil(err)
+		assertions.Equal([]uint{3, 2}, ids(next))
+		assertions.Nil(next.Total)
+
+		last, err := store.ListPage(ListOptions{InformerName: "w1", Limit: 2, Cursor: next.NextCursor})
+		assertions.Nil(err)
+		assertions.Equal([]uint{1}, ids(last))
+		assertions.Empty(last.NextCursor)
+
+		// back to newer pages
+		prev, err := store.ListPage(ListOptions{InformerName: "w1", Limit: 2, Cursor: last.PrevCursor})
+		assertions.Nil(err)
+		assertions.Equal([]uint{3, 2}, ids(prev))
+		assertions.Equal(next.NextCursor, prev.NextCursor)
+		prev, err = store.ListPage(ListOptions{InformerName: "w1", Limit: 2, Cursor: prev.PrevCursor})
+		assertions.Nil(err)
+		assertions.Equal([]uint{5, 4}, ids(prev))
+
+		// polling for new events
+		newer, err := store.ListPage(ListOptions{InformerName: "w1", Cursor: page.PrevCursor, OmitObjects: true})
+		assertions.Nil(err)
+		assertions.Empty(newer.Events)
+		assertions.Equal(page.PrevCursor, newer.PrevCursor)
+		assertions.Nil(store.Save(&models.Event{InformerName: "w1", EventType: "UPDATED",
+			Obj: []byte(`{"kind":"ConfigMap"}`)}))
+		newer, err = store.ListPage(ListOptions{InformerName: "w1", Cursor: newer.PrevCursor, OmitObjects: true})
+		assertions.Nil(err)
+		assertions.Equal([]uint{7}, ids(newer))
+		assertions.Nil(newer.Events[0].Obj)
+	})
+}
+
+func TestListPageByIndexes(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Interface) {
+		assertions := require.New(t)
+
+		for i := 0; i < 24; i++ {
+			e := &models.Event{Cluster: []string{"", "c2"}[i%2], InformerName: []string{"w1", "w2"}[i/2%2],
+				EventType: []string{"ADDED", "UPDATED", "DELETED"}[i%3], Version: "v1",
+				Namespace: []string{"demo", "other"}[i/4%2], Name: []string{"a", "b", "c"}[i%3],
+				Obj: []byte(`{"kind":"ConfigMap"}`)}
+			if i%5 == 0 {
+				e.Group, e.Resource = "apps", "deployments"
+			} else {
+				e.Resource = "configmaps"
+			}
+			assertions.Nil(store.Save(e))
+		}
+
+		ids := func(events []models.Event) []uint {
+			var ids []uint
+			for _, e := range events {
+				ids = append(ids, e.ID)
+			}
+			return ids
+		}
+
+		for _, options := range []ListOptions{
+			{},
+			{Cluster: "c2"},
+			{Namespace: "demo", Name: "a"},
+			{Namespace: "other", Name: "b", EventTypes: []string{"UPDATED"}},
+			{GroupResource: "configmaps"},
+			{GroupResource: "deployments.apps", Namespace: "demo"},
+			{GroupResource: "configmaps", InformerName: "w2", Name: "c", EventTypes: []string{"DELETED"}},
+			{Namespace: "other", Cluster: "c2"},
+			{InformerName: "w1"},
+		} {
+			options.Limit = MaxListLimit
+			all, err := store.List(options)
+			assertions.Nil(err)
+			assertions.NotEmpty(all, "%+v", options)
+
+			// pages counted, scanned from the start, are the same as pages scanned from cursors
+			for _, count := range []bool{false, true} {
+				options.Limit = 2
+				options.CountTotal = count
+				var paged []models.Event
+				var page *Page
+				for cursor := ""; ; cursor = page.NextCursor {
+					options.Cursor = cursor
+					page, err = store.ListPage(options)
+					assertions.Nil(err)
+					if count {
+						assertions.Equal(int64(len(all)), *page.Total, "%+v", options)
+					}
+					paged = append(paged, page.Events...)
+					if page.NextCursor == "" {
+						break
+					}
+				}
+				assertions.Equal(ids(all), ids(paged), "%+v", options)
+
+				options.Cursor = page.PrevCursor
+				newer, err := store.ListPage(options)
+				assertions.Nil(err)
+				if end := len(all) - len(page.Events); end > 0 {
+					start := end - 2
+					if start < 0 {
+						start = 0
+					}
+					assertions.Equal(ids(all[start:end]), ids(newer.Events), "%+v", options)
+				}
+			}
+		}
+	})
+}
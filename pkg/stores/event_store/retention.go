@@ -0,0 +1,296 @@
+package event_store
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	"gorm.io/gorm"
+	"time"
+)
+
+// DefaultPruneBatchSize is the number of events deleted in a statement
+const DefaultPruneBatchSize = 500
+
+// RetentionPolicy defines events to keep, zero values mean unlimited
+type RetentionPolicy struct {
+	// MaxAge is the max age of events
+	MaxAge time.Duration
+
+	// MaxEvents is the max number of events of an informer
+	MaxEvents int
+
+	// MaxVersions is the max number of events of an object
+	MaxVersions int
+}
+
+// Override returns the policy with fields set in o taking precedence
+func (p RetentionPolicy) Override(o RetentionPolicy) RetentionPolicy {
+	if o.MaxAge != 0 {
+		p.MaxAge = o.MaxAge
+	}
+	if o.MaxEvents != 0 {
+		p.MaxEvents = o.MaxEvents
+	}
+	if o.MaxVersions != 0 {
+		p.MaxVersions = o.MaxVersions
+	}
+	return p
+}
+
+// IsZero returns true if all events are kept
+func (p RetentionPolicy) IsZero() bool {
+	return p.MaxAge <= 0 && p.MaxEvents <= 0 && p.MaxVersions <= 0
+}
+
+type PruneOptions struct {
+	// BatchSize is the number of events deleted in a statement, DefaultPruneBatchSize when 0
+	BatchSize int
+
+	// DryRun counts events to prune without deleting them
+	DryRun bool
+
+	// Now is the time MaxAge is counted from, time.Now() when zero
+	Now time.Time
+}
+
+// PruneResult is the number of events pruned of an informer by each rule,
+// an event is counted by the first rule pruning it, in the order of fields.
+type PruneResult struct {
+	Cluster      string
+	InformerName string
+
+	Expired         int64
+	OverMaxEvents   int64
+	OverMaxVersions int64
+}
+
+// Total returns the number of events pruned
+func (r PruneResult) Total() int64 {
+	return r.Expired + r.OverMaxEvents + r.OverMaxVersions
+}
+
+// Prune deletes events by retention policies of informers, oldest first,
+// the latest ADDED and TRIGGERED events of objects currently added or triggered are kept,
+// otherwise they would be noticed again when the controller restarts.
+// In a dry run, events to prune are counted by the same conditions and nothing is changed.
+func (s *Store) Prune(policyOf func(cluster, informerName string) RetentionPolicy,
+	options PruneOptions) ([]PruneResult, error) {
+	if options.BatchSize < 1 {
+		options.BatchSize = DefaultPruneBatchSize
+	}
+	if options.Now.IsZero() {
+		options.Now = time.Now()
+	}
+
+	return s.prune(policyOf, options)
+}
+
+func (s *Store) prune(policyOf func(cluster, informerName string) RetentionPolicy,
+	options PruneOptions) ([]PruneResult, error) {
+	var informers []struct {
+		Cluster      string
+		InformerName string
+	}
+	if err := s.DB.Model(&models.Event{}).
+		Distinct("cluster", "informer_name").
+		Order("cluster, informer_name").
+		Find(&informers).Error; err != nil {
+		return nil, errors.Wrap(err, "list informers error")
+	}
+
+	var results []PruneResult
+	for _, informer := range informers {
+		policy := policyOf(informer.Cluster, informer.InformerName)
+		if policy.IsZero() {
+			continue
+		}
+		result, err := s.pruneInformer(informer.Cluster, informer.InformerName, policy, options)
+		if err != nil {
+			return results, errors.Wrapf(err, "prune events of %s error", informer.InformerName)
+		}
+		if result.Total() > 0 {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+func (s *Store) pruneInformer(cluster, informerName string,
+	policy RetentionPolicy, options PruneOptions) (result PruneResult, err error) {
+	result.Cluster = cluster
+	result.InformerName = informerName
+
+	// pruned selects events counted by previous rules in a dry run, they are deleted otherwise
+	var pruned []*gorm.DB
+	remaining := func(query *gorm.DB) *gorm.DB {
+		for _, p := range pruned {
+			query = query.Where("id not in (?)", p)
+		}
+		return query
+	}
+	ofInformer := func() *gorm.DB {
+		return remaining(s.DB.Model(&models.Event{}).
+			Where("cluster = ?", cluster).
+			Where("informer_name = ?", informerName))
+	}
+	prunable := func() *gorm.DB {
+		return ofInformer().Where("id not in (?)", s.protected(cluster, informerName))
+	}
+	prune := func(query func() *gorm.DB) (int64, error) {
+		if !options.DryRun {
+			return s.deleteBatched(query, options.BatchSize)
+		}
+		var count int64
+		if err := query().Count(&count).Error; err != nil {
+			return 0, err
+		}
+		pruned = append(pruned, query().Select("id"))
+		return count, nil
+	}
+
+	if policy.MaxAge > 0 {
+		cutoff := options.Now.Add(-policy.MaxAge)
+		result.Expired, err = prune(func() *gorm.DB {
+			return prunable().Where("create_time < ?", cutoff)
+		})
+		if err != nil {
+			return result, errors.Wrap(err, "prune expired events error")
+		}
+	}
+
+	if policy.MaxEvents > 0 {
+		threshold, found, err := nthLatestID(ofInformer(), policy.MaxEvents)
+		if err != nil {
+			return result, errors.Wrap(err, "find oldest event to keep error")
+		}
+		if found {
+			result.OverMaxEvents, err = prune(func() *gorm.DB {
+				return prunable().Where("id <= ?", threshold)
+			})
+			if err != nil {
+				return result, errors.Wrap(err, "prune events over max events error")
+			}
+		}
+	}
+
+	if policy.MaxVersions > 0 {
+		var objects []struct {
+			Group     string `gorm:"column:event_group"`
+			Version   string
+			Resource  string
+			Namespace string
+			Name      string
+		}
+		if err := ofInformer().
+			Select("event_group, version, resource, namespace, name").
+			Group("event_group, version, resource, namespace, name").
+			Having("count(*) > ?", policy.MaxVersions).
+			Find(&objects).Error; err != nil {
+			return result, errors.Wrap(err, "list objects over max versions error")
+		}
+		for _, o := range objects {
+			ofObject := func(query *gorm.DB) *gorm.DB {
+				return query.
+					Where("event_group = ?", o.Group).
+					Where("version = ?", o.Version).
+					Where("resource = ?", o.Resource).
+					Where("namespace = ?", o.Namespace).
+					Where("name = ?", o.Name)
+			}
+			threshold, found, err := nthLatestID(ofObject(ofInformer()), policy.MaxVersions)
+			if err != nil {
+				return result, errors.Wrap(err, "find oldest version to keep error")
+			}
+			if !found {
+				continue
+			}
+			count, err := prune(func() *gorm.DB {
+				return ofObject(prunable()).Where("id <= ?", threshold)
+			})
+			result.OverMaxVersions += count
+			if err != nil {
+				return result, errors.Wrap(err, "prune events over max versions error")
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// protected selects IDs of the latest ADDED and TRIGGERED events of objects currently added or triggered
+func (s *Store) protected(cluster, informerName string) *gorm.DB {
+	latest := s.DB.Model(&models.Event{}).
+		Select("max(id)").
+		Where("cluster = ?", cluster).
+		Where("informer_name = ?", informerName).
+		Where("event_type in ?", []string{models.EventTypeAdded, models.EventTypeDeleted,
+			models.EventTypeTriggered, models.EventTypeResolved}).
+		Group(fmt.Sprintf("event_group, version, resource, namespace, name, "+
+			"case when event_type in ('%s', '%s') then 0 else 1 end",
+			models.EventTypeAdded, models.EventTypeDeleted))
+	return s.DB.Model(&models.Event{}).
+		Select("id").
+		Where("id in (?)", latest).
+		Where("event_type in ?", []string{models.EventTypeAdded, models.EventTypeTriggered})
+}
+
+// nthLatestID returns ID of the (n+1)th latest event in query, events up to it are over the limit n
+func nthLatestID(query *gorm.DB, n int) (id uint, found bool, err error) {
+	var ids []uint
+	if err := query.Order("id desc").Offset(n).Limit(1).Pluck("id", &ids).Error; err != nil {
+		return 0, false, err
+	}
+	if len(ids) == 0 {
+		return 0, false, nil
+	}
+	return ids[0], true, nil
+}
+
+// deleteBatched deletes events selected by query in batches, oldest first
+func (s *Store) deleteBatched(query func() *gorm.DB, batchSize int) (deleted int64, err error) {
+	for {
+		var ids []uint
+		if err := query().Order("id").Limit(batchSize).Pluck("id", &ids).Error; err != nil {
+			return deleted, err
+		}
+		if len(ids) == 0 {
+			return deleted, nil
+		}
+		// events stored as patches against events to delete are stored in full first
+		if err := s.materialize(ids); err != nil {
+			return deleted, err
+		}
+		if err := s.deleteContents(ids); err != nil {
+			return deleted, err
+		}
+		result := s.DB.Where("id in ?", ids).Delete(&models.Event{})
+		deleted += result.RowsAffected
+		if result.Error != nil {
+			return deleted, result.Error
+		}
+		if len(ids) < batchSize {
+			return deleted, nil
+		}
+	}
+}
+
+// Optimize reclaims space and updates statistics after pruning, e.g. VACUUM and ANALYZE
+func (s *Store) Optimize() error {
+	var statements []string
+	switch s.DB.Dialector.Name() {
+	case "sqlite":
+		statements = []string{"VACUUM", "ANALYZE"}
+	case "postgres":
+		statements = []string{"VACUUM ANALYZE events"}
+	case "mysql":
+		statements = []string{"OPTIMIZE TABLE events"}
+	default:
+		return errors.Errorf("unsupported dialect: %s", s.DB.Dialector.Name())
+	}
+	for _, statement := range statements {
+		if err := s.DB.Exec(statement).Error; err != nil {
+			return errors.Wrapf(err, "execute %s error", statement)
+		}
+	}
+	return nil
+}
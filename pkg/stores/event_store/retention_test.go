@@ -0,0 +1,112 @@
+package event_store
+
+import (
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestPrune(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Interface) {
+		assertions := require.New(t)
+
+		now := time.Now()
+		save := func(informerName, eventType, name string, age time.Duration) {
+			assertions.Nil(store.Save(&models.Event{
+				CreateTime:   now.Add(-age),
+				InformerName: informerName,
+				EventType:    eventType,
+				Resource:     "configmaps",
+				Namespace:    "demo",
+				Name:         name,
+			}))
+		}
+		// a: added long ago and still exists, its ADDED event is protected
+		save("w1", "ADDED", "a", 72*time.Hour)
+		save("w1", "UPDATED", "a", 48*time.Hour)
+		save("w1", "UPDATED", "a", 2*time.Hour)
+		save("w1", "UPDATED", "a", time.Hour)
+		// b: deleted long ago
+		save("w1", "ADDED", "b", 72*time.Hour)
+		save("w1", "DELETED", "b", 48*time.Hour)
+		// w2 is not pruned
+		save("w2", "UPDATED", "c", 72*time.Hour)
+
+		policyOf := func(cluster, informerName string) RetentionPolicy {
+			if informerName == "w1" {
+				return RetentionPolicy{MaxAge: 24 * time.Hour}.Override(RetentionPolicy{MaxVersions: 1})
+			}
+			return RetentionPolicy{}
+		}
+
+		results, err := store.Prune(policyOf, PruneOptions{DryRun: true, BatchSize: 1, Now: now})
+		assertions.Nil(err)
+		assertions.Equal([]PruneResult{{InformerName: "w1", Expired: 3, OverMaxVersions: 1}}, results)
+
+		events, err := store.List(ListOptions{})
+		assertions.Nil(err)
+		assertions.Len(events, 7)
+
+		results, err = store.Prune(policyOf, PruneOptions{BatchSize: 1, Now: now})
+		assertions.Nil(err)
+		assertions.Equal(int64(4), results[0].Total())
+
+		events, err = store.List(ListOptions{})
+		assertions.Nil(err)
+		assertions.Len(events, 3)
+		assertions.Equal("w2", events[0].InformerName)
+		assertions.Equal("UPDATED", events[1].EventType)
+		assertions.Equal("ADDED", events[2].EventType)
+
+		// the protected ADDED event of w1 is kept
+		save("w2", "UPDATED", "c", 0)
+		results, err = store.Prune(func(string, string) RetentionPolicy {
+			return RetentionPolicy{MaxEvents: 1}
+		}, PruneOptions{Now: now})
+		assertions.Nil(err)
+		assertions.Equal([]PruneResult{{InformerName: "w2", OverMaxEvents: 1}}, results)
+
+		assertions.Nil(store.Optimize())
+	})
+}
+
+func TestPruneDryRun(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Interface) {
+		assertions := require.New(t)
+
+		now := time.Now()
+		for i, age := range []time.Duration{72 * time.Hour, 48 * time.Hour, 2 * time.Hour, time.Hour} {
+			assertions.Nil(store.Save(&models.Event{
+				CreateTime:   now.Add(-age),
+				InformerName: "w1",
+				EventType:    "UPDATED",
+				Resource:     "configmaps",
+				Namespace:    "demo",
+				Name:         string(rune('a' + i%2)),
+			}))
+		}
+
+		// expired events are not counted again over max events and max versions
+		policyOf := func(cluster, informerName string) RetentionPolicy {
+			return RetentionPolicy{MaxAge: 24 * time.Hour, MaxEvents: 1, MaxVersions: 1}
+		}
+		expected := []PruneResult{{InformerName: "w1", Expired: 2, OverMaxEvents: 1}}
+
+		results, err := store.Prune(policyOf, PruneOptions{DryRun: true, Now: now})
+		assertions.Nil(err)
+		assertions.Equal(expected, results)
+
+		events, err := store.List(ListOptions{})
+		assertions.Nil(err)
+		assertions.Len(events, 4)
+
+		results, err = store.Prune(policyOf, PruneOptions{Now: now})
+		assertions.Nil(err)
+		assertions.Equal(expected, results)
+
+		events, err = store.List(ListOptions{})
+		assertions.Nil(err)
+		assertions.Len(events, 1)
+	})
+}
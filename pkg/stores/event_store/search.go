@@ -0,0 +1,272 @@
+package event_store
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	"gorm.io/gorm"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// DefaultReindexBatchSize is the number of events indexed in a transaction
+	DefaultReindexBatchSize = 200
+
+	// maxHighlights is the max number of matched lines of an event
+	maxHighlights = 3
+
+	// maxHighlightLength is the max length of a matched line, longer lines are cut around the first match
+	maxHighlightLength = 200
+)
+
+// excludedContentPaths are fields not indexed, they are noisy or duplicate the object
+var excludedContentPaths = map[string]bool{
+	"metadata.managedFields": true,
+	"metadata.annotations.kubectl.kubernetes.io/last-applied-configuration": true,
+}
+
+// contentOf returns the text indexed of obj, one "path: value" line for every leaf value
+func contentOf(obj []byte) (string, error) {
+	var v interface{}
+	if err := json.Unmarshal(obj, &v); err != nil {
+		return "", err
+	}
+	var lines []string
+	var walk func(path string, v interface{})
+	walk = func(path string, v interface{}) {
+		if excludedContentPaths[path] {
+			return
+		}
+		join := func(key string) string {
+			if path == "" {
+				return key
+			}
+			return path + "." + key
+		}
+		switch t := v.(type) {
+		case map[string]interface{}:
+			keys := make([]string, 0, len(t))
+			for k := range t {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				walk(join(k), t[k])
+			}
+		case []interface{}:
+			for i, item := range t {
+				walk(join(fmt.Sprint(i)), item)
+			}
+		case nil:
+		default:
+			lines = append(lines, fmt.Sprintf("%s: %v", path, t))
+		}
+	}
+	walk("", v)
+	return strings.Join(lines, "\n"), nil
+}
+
+// searchTerms splits q into lowercase terms, the way full-text indexes tokenize text
+func searchTerms(q string) []string {
+	return strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// contentCondition matches events with content containing q as a phrase
+func contentCondition(db *gorm.DB, q string) (*gorm.DB, error) {
+	terms := searchTerms(q)
+	if len(terms) == 0 {
+		return nil, errors.Errorf("invalid content search, no words found: %s", q)
+	}
+	phrase := strings.Join(terms, " ")
+	switch db.Dialector.Name() {
+	case "sqlite":
+		return db.Where("id in (select rowid from event_contents where event_contents match ?)",
+			`"`+phrase+`"`), nil
+	case "postgres":
+		return db.Where("id in (select event_id from event_contents where tsv @@ phraseto_tsquery('simple', ?))",
+			phrase), nil
+	case "mysql":
+		return db.Where("id in (select event_id from event_contents where match(content) against (? in boolean mode))",
+			`"`+phrase+`"`), nil
+	default:
+		return nil, errors.Errorf("unsupported dialect: %s", db.Dialector.Name())
+	}
+}
+
+// contentIDColumn is the column of event IDs in the content table
+func contentIDColumn(db *gorm.DB) string {
+	if db.Dialector.Name() == "sqlite" {
+		return "rowid"
+	}
+	return "event_id"
+}
+
+// indexContent indexes content of Obj of event, the old content is replaced
+func (s *Store) indexContent(event *models.Event) error {
+	if err := s.deleteContents([]uint{event.ID}); err != nil {
+		return err
+	}
+	if event.Obj == nil {
+		return nil
+	}
+	content, err := contentOf(event.Obj)
+	if err != nil {
+		return errors.Wrapf(err, "extract content of event %d error", event.ID)
+	}
+	switch s.DB.Dialector.Name() {
+	case "postgres":
+		return s.DB.Exec("INSERT INTO event_contents (event_id, content, tsv) VALUES (?, ?, to_tsvector('simple', ?))",
+			event.ID, content, content).Error
+	default:
+		return s.DB.Exec(fmt.Sprintf("INSERT INTO event_contents (%s, content) VALUES (?, ?)", contentIDColumn(s.DB)),
+			event.ID, content).Error
+	}
+}
+
+// deleteContents deletes content of events in ids from the index
+func (s *Store) deleteContents(ids []uint) error {
+	return s.DB.Exec(fmt.Sprintf("DELETE FROM event_contents WHERE %s IN ?", contentIDColumn(s.DB)), ids).Error
+}
+
+// highlightContents sets Highlights of events, lines of content matching q with matches marked
+func (s *Store) highlightContents(events []models.Event, q string) error {
+	if len(events) == 0 {
+		return nil
+	}
+	ids := make([]uint, 0, len(events))
+	for _, e := range events {
+		ids = append(ids, e.ID)
+	}
+	var contents []struct {
+		ID      uint
+		Content string
+	}
+	if err := s.DB.Raw(fmt.Sprintf("SELECT %s AS id, content FROM event_contents WHERE %s IN ?",
+		contentIDColumn(s.DB), contentIDColumn(s.DB)), ids).Scan(&contents).Error; err != nil {
+		return errors.Wrap(err, "get contents error")
+	}
+	contentOfID := make(map[uint]string, len(contents))
+	for _, c := range contents {
+		contentOfID[c.ID] = c.Content
+	}
+	terms := searchTerms(q)
+	for i := range events {
+		events[i].Highlights = highlight(contentOfID[events[i].ID], terms)
+	}
+	return nil
+}
+
+// highlight finds lines of content containing all terms, terms are marked with <mark>,
+// lines are HTML escaped.
+func highlight(content string, terms []string) []string {
+	if len(terms) == 0 {
+		return nil
+	}
+	quoted := make([]string, 0, len(terms))
+	for _, t := range terms {
+		quoted = append(quoted, regexp.QuoteMeta(t))
+	}
+	matcher := regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+
+	var highlights []string
+	for _, line := range strings.Split(content, "\n") {
+		lower := strings.ToLower(line)
+		matched := true
+		for _, t := range terms {
+			if !strings.Contains(lower, t) {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		if runes := []rune(line); len(runes) > maxHighlightLength {
+			loc := matcher.FindStringIndex(line)
+			start := len([]rune(line[:loc[0]])) - maxHighlightLength/4
+			if start < 0 {
+				start = 0
+			}
+			end := start + maxHighlightLength
+			if end > len(runes) {
+				end = len(runes)
+			}
+			line = string(runes[start:end])
+			if start > 0 {
+				line = "…" + line
+			}
+			if end < len(runes) {
+				line += "…"
+			}
+		}
+		highlights = append(highlights, markMatches(line, matcher))
+		if len(highlights) >= maxHighlights {
+			break
+		}
+	}
+	return highlights
+}
+
+// markMatches HTML escapes line, with matches of matcher marked with <mark>
+func markMatches(line string, matcher *regexp.Regexp) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range matcher.FindAllStringIndex(line, -1) {
+		b.WriteString(html.EscapeString(line[last:loc[0]]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(line[loc[0]:loc[1]]))
+		b.WriteString("</mark>")
+		last = loc[1]
+	}
+	b.WriteString(html.EscapeString(line[last:]))
+	return b.String()
+}
+
+// Reindex rebuilds the content index of all events in batches,
+// events saved before content indexing is enabled become searchable.
+func (s *Store) Reindex(batchSize int) (indexed int64, err error) {
+	if batchSize < 1 {
+		batchSize = DefaultReindexBatchSize
+	}
+	var lastID uint
+	for {
+		var events []models.Event
+		if err := s.DB.
+			Where("id > ?", lastID).
+			Where("obj is not null").
+			Order("id").
+			Limit(batchSize).
+			Find(&events).Error; err != nil {
+			return indexed, errors.Wrap(err, "list events error")
+		}
+		if len(events) == 0 {
+			return indexed, nil
+		}
+		lastID = events[len(events)-1].ID
+
+		err := s.DB.Transaction(func(tx *gorm.DB) error {
+			store := &Store{DB: tx, Options: s.Options}
+			for i := range events {
+				if err := store.resolve(&events[i]); err != nil {
+					return err
+				}
+				if err := store.indexContent(&events[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return indexed, err
+		}
+		indexed += int64(len(events))
+	}
+}
@@ -0,0 +1,152 @@
+package event_store
+
+import (
+	"github.com/spongeprojects/kubebigbrother/pkg/gormdb"
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContentOf(t *testing.T) {
+	assertions := require.New(t)
+
+	content, err := contentOf([]byte(`{"kind":"Pod","metadata":{"name":"a","managedFields":[{"manager":"m"}],` +
+		`"annotations":{"kubectl.kubernetes.io/last-applied-configuration":"{}","note":"x"}},` +
+		`"spec":{"containers":[{"image":"nginx:1.21","ports":[80]}],"nodeName":null}}`))
+	assertions.Nil(err)
+	assertions.Equal(strings.Join([]string{
+		"kind: Pod",
+		"metadata.annotations.note: x",
+		"metadata.name: a",
+		"spec.containers.0.image: nginx:1.21",
+		"spec.containers.0.ports.0: 80",
+	}, "\n"), content)
+
+	_, err = contentOf([]byte("invalid"))
+	assertions.NotNil(err)
+}
+
+func TestHighlight(t *testing.T) {
+	assertions := require.New(t)
+
+	content := "metadata.name: web\nspec.host: <Foo>.example.com\nspec.other: foo"
+	assertions.Equal([]string{"spec.host: &lt;<mark>Foo</mark>&gt;.<mark>example</mark>.com"},
+		highlight(content, searchTerms("foo.example")))
+	// lines are matched before escaping, entities of escaped characters are never matched
+	assertions.Nil(highlight(content, searchTerms("gt")))
+	assertions.Nil(highlight(content, nil))
+
+	long := "spec.data: " + strings.Repeat("a", 300) + " needle " + strings.Repeat("b", 300)
+	highlights := highlight(long, searchTerms("needle"))
+	assertions.Len(highlights, 1)
+	assertions.Contains(highlights[0], "<mark>needle</mark>")
+	assertions.True(strings.HasPrefix(highlights[0], "…"))
+	assertions.True(strings.HasSuffix(highlights[0], "…"))
+}
+
+func TestContentSearch(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Interface) {
+		assertions := require.New(t)
+
+		save := func(kind, name, image string) {
+			assertions.Nil(store.Save(&models.Event{InformerName: "w1", EventType: "ADDED", Version: "v1",
+				Resource: "pods", Kind: kind, Namespace: "demo", Name: name, UID: name, Obj: podOf(name, image)}))
+		}
+		save("Pod", "a", "registry.example.com/nginx:1.21")
+		save("Pod", "b", "docker.io/library/redis:6")
+		save("Other", "c", "registry.example.com/redis:6")
+
+		names := func(options ListOptions) []string {
+			events, err := store.List(options)
+			assertions.Nil(err)
+			var names []string
+			for _, e := range events {
+				names = append(names, e.Name)
+			}
+			return names
+		}
+
+		assertions.Equal([]string{"c", "a"}, names(ListOptions{Content: "registry.example.com"}))
+		assertions.Equal([]string{"a"}, names(ListOptions{Content: "registry.example.com", Kind: "Pod"}))
+		// terms are matched as a phrase
+		assertions.Empty(names(ListOptions{Content: "example registry"}))
+		assertions.Equal([]string{"c", "b"}, names(ListOptions{Content: "REDIS:6"}))
+
+		_, err := store.List(ListOptions{Content: "..."})
+		assertions.NotNil(err)
+
+		page, err := store.ListPage(ListOptions{Content: "nginx", OmitObjects: true, CountTotal: true})
+		assertions.Nil(err)
+		assertions.Equal(int64(1), *page.Total)
+		assertions.Equal([]string{"spec.containers.0.image: registry.example.com/<mark>nginx</mark>:1.21"},
+			page.Events[0].Highlights)
+
+		// pruned events are not found, events of objects currently added are kept
+		assertions.Nil(store.Save(&models.Event{InformerName: "w1", EventType: "DELETED", Version: "v1",
+			Resource: "pods", Kind: "Pod", Namespace: "demo", Name: "b", UID: "b",
+			Obj: podOf("b", "docker.io/library/redis:6")}))
+		_, err = store.Prune(func(cluster, informerName string) RetentionPolicy {
+			return RetentionPolicy{MaxAge: time.Nanosecond}
+		}, PruneOptions{Now: time.Now().Add(time.Hour)})
+		assertions.Nil(err)
+		assertions.Equal([]string{"c"}, names(ListOptions{Content: "REDIS:6"}))
+	})
+}
+
+func TestReindex(t *testing.T) {
+	assertions := require.New(t)
+
+	db, err := gormdb.New("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	assertions.Nil(err)
+	store, err := NewWithOptions(db, Options{StorageMode: StorageModeDiff, IndexContent: true})
+	assertions.Nil(err)
+
+	save := func(store Interface, name, image string) {
+		assertions.Nil(store.Save(&models.Event{InformerName: "w1", EventType: "ADDED", Version: "v1",
+			Resource: "pods", Kind: "Pod", Namespace: "demo", Name: name, UID: name, Obj: podOf(name, image)}))
+	}
+	save(store, "a", "registry.example.com/nginx:1.21")
+	save(store, "b", "docker.io/library/redis:6")
+	save(store, "c", "registry.example.com/redis:6")
+
+	names := func(options ListOptions) []string {
+		events, err := store.List(options)
+		assertions.Nil(err)
+		var names []string
+		for _, e := range events {
+			names = append(names, e.Name)
+		}
+		return names
+	}
+
+	// events saved before content indexing is enabled are searchable after reindexing
+	notIndexed, err := NewWithOptions(db, Options{StorageMode: StorageModeDiff})
+	assertions.Nil(err)
+	save(notIndexed, "d", "quay.io/postgres:13")
+	assertions.Empty(names(ListOptions{Content: "postgres"}))
+	indexed, err := store.Reindex(1)
+	assertions.Nil(err)
+	assertions.Equal(int64(4), indexed)
+	assertions.Equal([]string{"d"}, names(ListOptions{Content: "postgres"}))
+	assertions.Equal([]string{"c", "a"}, names(ListOptions{Content: "registry.example.com"}))
+
+	// contents of pruned events are deleted, events of objects currently added are kept
+	assertions.Nil(store.Save(&models.Event{InformerName: "w1", EventType: "DELETED", Version: "v1",
+		Resource: "pods", Kind: "Pod", Namespace: "demo", Name: "d", UID: "d", Obj: podOf("d", "quay.io/postgres:13")}))
+	_, err = store.Prune(func(cluster, informerName string) RetentionPolicy {
+		return RetentionPolicy{MaxAge: time.Nanosecond}
+	}, PruneOptions{Now: time.Now().Add(time.Hour)})
+	assertions.Nil(err)
+	var contents int64
+	assertions.Nil(db.Raw("SELECT count(*) FROM event_contents").Scan(&contents).Error)
+	assertions.Equal(int64(3), contents)
+	assertions.Empty(names(ListOptions{Content: "postgres"}))
+}
+
+func podOf(name, image string) []byte {
+	return []byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"` + name + `"},` +
+		`"spec":{"containers":[{"image":"` + image + `"}]}}`)
+}
@@ -0,0 +1,160 @@
+package event_store
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	"github.com/spongeprojects/kubebigbrother/pkg/utils/diff"
+	"time"
+)
+
+// SnapshotOptions selects objects of a snapshot, an informer, a namespace or both
+type SnapshotOptions struct {
+	Cluster      string
+	InformerName string
+
+	// Namespace is the namespace of objects
+	Namespace string
+}
+
+// Validate returns error if options are invalid
+func (o *SnapshotOptions) Validate() error {
+	if o.InformerName == "" && o.Namespace == "" {
+		return errors.New("informer or namespace is required")
+	}
+	return nil
+}
+
+// SnapshotDiff is the difference between snapshots,
+// objects are omitted, Diff of changes is the unified diff of objects in YAML.
+type SnapshotDiff struct {
+	Added   []models.Event `json:"added"`
+	Removed []models.Event `json:"removed"`
+	Changed []Change       `json:"changed"`
+}
+
+// Change is a change of an object between snapshots
+type Change struct {
+	From models.Event `json:"from"`
+	To   models.Event `json:"to"`
+	Diff string       `json:"diff"`
+}
+
+// Snapshot reconstructs objects existing at the time, with full objects, sorted by resource, namespace and name,
+// the latest event of every object before the time is its state, objects whose latest events are DELETED
+// are gone, objects with events pruned by retention policies may be missing or outdated.
+func (s *Store) Snapshot(options SnapshotOptions, at time.Time) ([]models.Event, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	latest := s.DB.Model(&models.Event{}).
+		Select("max(id)").
+		Where("cluster = ?", options.Cluster).
+		Where("event_type <> ?", models.EventTypeOccurred).
+		Where("obj is not null").
+		Where("create_time <= ?", at)
+	if options.InformerName != "" {
+		latest = latest.Where("informer_name = ?", options.InformerName)
+	}
+	if options.Namespace != "" {
+		latest = latest.Where("namespace = ?", options.Namespace)
+	}
+	// events of an object across informers and versions of the resource
+	latest = latest.Group("event_group, resource, namespace, name")
+
+	var events []models.Event
+	if err := s.DB.
+		Where("id in (?)", latest).
+		Where("event_type <> ?", models.EventTypeDeleted).
+		Order("event_group, resource, namespace, name").
+		Find(&events).Error; err != nil {
+		return nil, errors.Wrap(err, "list latest events error")
+	}
+	for i := range events {
+		if err := s.resolve(&events[i]); err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+// DiffSnapshots lists objects added, removed and changed between snapshots at from and to,
+// changes of fields changing on every update only, e.g. resourceVersion, are ignored.
+func (s *Store) DiffSnapshots(options SnapshotOptions, from, to time.Time) (*SnapshotDiff, error) {
+	before, err := s.Snapshot(options, from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.Snapshot(options, to)
+	if err != nil {
+		return nil, err
+	}
+	return diffSnapshots(before, after)
+}
+
+// diffSnapshots lists objects added, removed and changed between snapshots sorted by objects
+func diffSnapshots(before, after []models.Event) (*SnapshotDiff, error) {
+	beforeOf := make(map[string]*models.Event, len(before))
+	for i := range before {
+		beforeOf[objectKey(&before[i])] = &before[i]
+	}
+
+	d := &SnapshotDiff{
+		Added:   []models.Event{},
+		Removed: []models.Event{},
+		Changed: []Change{},
+	}
+	for i := range after {
+		a := &after[i]
+		key := objectKey(a)
+		b, ok := beforeOf[key]
+		if !ok {
+			d.Added = append(d.Added, omitObjects(*a))
+			continue
+		}
+		delete(beforeOf, key)
+		if a.ID == b.ID || (a.ResourceVersion != "" && a.ResourceVersion == b.ResourceVersion) {
+			continue
+		}
+		objDiff, err := diffObjects(b.Obj, a.Obj)
+		if err != nil {
+			return nil, errors.Wrapf(err, "diff events %d and %d error", b.ID, a.ID)
+		}
+		if objDiff == "" {
+			continue
+		}
+		d.Changed = append(d.Changed, Change{From: omitObjects(*b), To: omitObjects(*a), Diff: objDiff})
+	}
+	// before is sorted, removed objects are kept in order
+	for i := range before {
+		if _, ok := beforeOf[objectKey(&before[i])]; ok {
+			d.Removed = append(d.Removed, omitObjects(before[i]))
+		}
+	}
+	return d, nil
+}
+
+// objectKey identifies an object in a snapshot
+func objectKey(e *models.Event) string {
+	return e.Group + "/" + e.Resource + "/" + e.Namespace + "/" + e.Name
+}
+
+// omitObjects returns event without objects
+func omitObjects(e models.Event) models.Event {
+	e.Obj = nil
+	e.OldObj = nil
+	return e
+}
+
+// diffObjects returns unified diff of raw JSON objects in YAML
+func diffObjects(oldObj, obj []byte) (string, error) {
+	old, err := unmarshal(oldObj)
+	if err != nil {
+		return "", err
+	}
+	o, err := unmarshal(obj)
+	if err != nil {
+		return "", err
+	}
+	return diff.Objects(old, o)
+}
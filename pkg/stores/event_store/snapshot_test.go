@@ -0,0 +1,84 @@
+package event_store
+
+import (
+	"fmt"
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Interface) {
+		assertions := require.New(t)
+
+		configMap := func(name, resourceVersion, value string) []byte {
+			return []byte(fmt.Sprintf(`{"apiVersion":"v1","kind":"ConfigMap",`+
+				`"metadata":{"namespace":"prod","name":"%s","resourceVersion":"%s"},"data":{"key":"%s"}}`,
+				name, resourceVersion, value))
+		}
+		start := time.Date(2021, 6, 1, 14, 0, 0, 0, time.UTC)
+		save := func(minutes int, informerName, eventType, name, resourceVersion, value string) {
+			assertions.Nil(store.Save(&models.Event{InformerName: informerName, EventType: eventType,
+				Version: "v1", Resource: "configmaps", Kind: "ConfigMap", Namespace: "prod", Name: name,
+				UID: name, ResourceVersion: resourceVersion, Obj: configMap(name, resourceVersion, value),
+				CreateTime: start.Add(time.Duration(minutes) * time.Minute)}))
+		}
+		save(0, "w1", "ADDED", "a", "1", "a1")
+		save(0, "w1", "ADDED", "b", "2", "b1")
+		// the same object noticed by another informer
+		save(1, "w2", "ADDED", "a", "1", "a1")
+		save(10, "w1", "UPDATED", "a", "3", "a2")
+		save(20, "w1", "DELETED", "b", "4", "b1")
+		save(30, "w2", "ADDED", "c", "5", "c1")
+		save(40, "w1", "UPDATED", "a", "6", "a3")
+		assertions.Nil(store.Save(&models.Event{InformerName: "w1", EventType: "ADDED", Version: "v1",
+			Resource: "configmaps", Namespace: "other", Name: "d", Obj: configMap("d", "7", "d1"),
+			CreateTime: start}))
+
+		snapshot := func(options SnapshotOptions, minutes int) []string {
+			events, err := store.Snapshot(options, start.Add(time.Duration(minutes)*time.Minute))
+			assertions.Nil(err)
+			var objects []string
+			for _, e := range events {
+				obj, err := e.GetObj()
+				assertions.Nil(err)
+				assertions.NotNil(obj)
+				value, _, _ := unstructured.NestedString(obj.Object, "data", "key")
+				objects = append(objects, e.Name+"="+value)
+			}
+			return objects
+		}
+
+		assertions.Empty(snapshot(SnapshotOptions{Namespace: "prod"}, -1))
+		assertions.Equal([]string{"a=a1", "b=b1"}, snapshot(SnapshotOptions{Namespace: "prod"}, 5))
+		assertions.Equal([]string{"a=a2"}, snapshot(SnapshotOptions{Namespace: "prod"}, 25))
+		assertions.Equal([]string{"a=a3", "c=c1"}, snapshot(SnapshotOptions{Namespace: "prod"}, 45))
+		assertions.Equal([]string{"a=a1", "c=c1"}, snapshot(SnapshotOptions{InformerName: "w2"}, 45))
+		assertions.Equal([]string{"d=d1"}, snapshot(SnapshotOptions{InformerName: "w1", Namespace: "other"}, 45))
+
+		_, err := store.Snapshot(SnapshotOptions{}, start)
+		assertions.NotNil(err)
+
+		d, err := store.DiffSnapshots(SnapshotOptions{Namespace: "prod"},
+			start.Add(5*time.Minute), start.Add(45*time.Minute))
+		assertions.Nil(err)
+		assertions.Len(d.Added, 1)
+		assertions.Equal("c", d.Added[0].Name)
+		assertions.Nil(d.Added[0].Obj)
+		assertions.Len(d.Removed, 1)
+		assertions.Equal("b", d.Removed[0].Name)
+		assertions.Len(d.Changed, 1)
+		assertions.Equal("a", d.Changed[0].To.Name)
+		assertions.Contains(d.Changed[0].Diff, "-  key: a1\n")
+		assertions.Contains(d.Changed[0].Diff, "+  key: a3\n")
+
+		d, err = store.DiffSnapshots(SnapshotOptions{Namespace: "prod"},
+			start.Add(2*time.Minute), start.Add(5*time.Minute))
+		assertions.Nil(err)
+		assertions.Empty(d.Added)
+		assertions.Empty(d.Removed)
+		assertions.Empty(d.Changed)
+	})
+}
@@ -0,0 +1,452 @@
+package event_store
+
+import (
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	"gorm.io/gorm"
+)
+
+// Storage modes
+const (
+	// StorageModeFull stores full objects in every event
+	StorageModeFull = "full"
+
+	// StorageModeDiff stores merge patches (RFC 7386) against the previous version of the object,
+	// with a full snapshot every SnapshotInterval versions
+	StorageModeDiff = "diff"
+)
+
+// DefaultSnapshotInterval is the default max number of patches between full snapshots
+const DefaultSnapshotInterval = 10
+
+// CompressionNone disables compression
+const CompressionNone = "none"
+
+// convertBatchSize is the number of events not versioned converted in a transaction
+const convertBatchSize = 500
+
+// errDryRun rolls back the transaction of a dry run
+var errDryRun = errors.New("dry run")
+
+type Options struct {
+	// StorageMode is how objects are stored, full or diff, full by default
+	StorageMode string
+
+	// SnapshotInterval is the max number of patches between full snapshots of an object in diff mode,
+	// DefaultSnapshotInterval when 0
+	SnapshotInterval int
+
+	// Compression is the codec objects are compressed with, e.g. gzip, not compressed when empty or none
+	Compression string
+
+	// IndexContent indexes content of objects for full-text search
+	IndexContent bool
+
+	// ReadOnly opens bolt files for reads only, so that processes reading, e.g. serve and queries,
+	// share the file, which is still locked while the controller has it open, ignored by SQL databases.
+	ReadOnly bool
+}
+
+func (o *Options) complete() error {
+	switch o.StorageMode {
+	case "":
+		o.StorageMode = StorageModeFull
+	case StorageModeFull, StorageModeDiff:
+	default:
+		return errors.Errorf("unknown storage mode: %s", o.StorageMode)
+	}
+	if o.SnapshotInterval < 1 {
+		o.SnapshotInterval = DefaultSnapshotInterval
+	}
+	if o.Compression == CompressionNone {
+		o.Compression = models.CodecNone
+	}
+	return models.ValidateCodec(o.Compression)
+}
+
+// save saves event, objects are stored as patches in diff mode when it saves space,
+// and compressed when compression is enabled, event is kept with raw full objects.
+func (s *Store) save(event *models.Event) error {
+	stored := *event
+	if err := stored.Decode(); err != nil {
+		return err
+	}
+
+	if s.Options.StorageMode == StorageModeDiff && stored.ID == 0 && isVersioned(&stored) {
+		base, err := s.previousVersion(&stored)
+		if err != nil {
+			return errors.Wrap(err, "find previous version error")
+		}
+		if base != nil {
+			encodeDiff(&stored, base, s.Options.SnapshotInterval)
+		}
+	}
+
+	if err := stored.Encode(s.Options.Compression); err != nil {
+		return err
+	}
+	if !s.Options.IndexContent {
+		if err := s.DB.Save(&stored).Error; err != nil {
+			return err
+		}
+	} else if err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&stored).Error; err != nil {
+			return err
+		}
+		indexed := *event
+		indexed.ID = stored.ID
+		return (&Store{DB: tx, Options: s.Options}).indexContent(&indexed)
+	}); err != nil {
+		return err
+	}
+	event.ID = stored.ID
+	event.CreateTime = stored.CreateTime
+	return nil
+}
+
+// isVersioned returns true if objects of the event are versions of a resource,
+// objects of OCCURRED events are Kubernetes Events, they are always stored in full.
+func isVersioned(event *models.Event) bool {
+	return event.Obj != nil && event.EventType != models.EventTypeOccurred
+}
+
+// versionsOf queries stored versions of the object of event
+func (s *Store) versionsOf(event *models.Event) *gorm.DB {
+	return s.DB.
+		Where("cluster = ?", event.Cluster).
+		Where("informer_name = ?", event.InformerName).
+		Where("event_group = ?", event.Group).
+		Where("version = ?", event.Version).
+		Where("resource = ?", event.Resource).
+		Where("namespace = ?", event.Namespace).
+		Where("name = ?", event.Name).
+		Where("uid = ?", event.UID).
+		Where("event_type <> ?", models.EventTypeOccurred).
+		Where("obj is not null")
+}
+
+// previousVersion finds the latest stored version of the object with full objects,
+// PatchDepth of it is kept as stored.
+func (s *Store) previousVersion(event *models.Event) (*models.Event, error) {
+	var bases []models.Event
+	if err := s.versionsOf(event).Order("id desc").Limit(1).Find(&bases).Error; err != nil {
+		return nil, err
+	}
+	if len(bases) == 0 {
+		return nil, nil
+	}
+	base := bases[0]
+	depth := base.PatchDepth
+	if err := s.resolve(&base); err != nil {
+		return nil, err
+	}
+	base.PatchDepth = depth
+	return &base, nil
+}
+
+// encodeDiff encodes objects of event as patches against the full objects of base,
+// Obj is patched from Obj of base, OldObj is patched from Obj.
+// Event is unchanged when a snapshot is due, or patches don't save space or don't reproduce the objects.
+func encodeDiff(event *models.Event, base *models.Event, snapshotInterval int) {
+	depth := base.PatchDepth + 1
+	if depth >= snapshotInterval {
+		return
+	}
+
+	objPatch, ok := createPatch(base.Obj, event.Obj)
+	if !ok {
+		return
+	}
+	var oldObjPatch []byte
+	if event.OldObj != nil {
+		oldObjPatch, ok = createPatch(event.Obj, event.OldObj)
+		if !ok {
+			return
+		}
+	}
+	if len(objPatch)+len(oldObjPatch) >= len(event.Obj)+len(event.OldObj) {
+		return
+	}
+
+	event.Obj = objPatch
+	event.OldObj = oldObjPatch
+	event.BaseID = base.ID
+	event.PatchDepth = depth
+}
+
+// createPatch creates merge patch from original to modified,
+// returns false if the patch cannot reproduce modified, e.g. null values are added.
+func createPatch(original, modified []byte) ([]byte, bool) {
+	patch, err := jsonpatch.CreateMergePatch(original, modified)
+	if err != nil {
+		return nil, false
+	}
+	patched, err := jsonpatch.MergePatch(original, patch)
+	if err != nil || !jsonpatch.Equal(patched, modified) {
+		return nil, false
+	}
+	return patch, true
+}
+
+// resolve reconstructs raw full objects of event stored as patches or compressed
+func (s *Store) resolve(event *models.Event) error {
+	if err := event.Decode(); err != nil {
+		return err
+	}
+	if event.BaseID == 0 {
+		return nil
+	}
+	chain, err := s.baseChain(event)
+	if err != nil {
+		return err
+	}
+	// the last base is the full snapshot, patches are applied from it
+	baseObj := chain[len(chain)-1].Obj
+	for i := len(chain) - 2; i >= 0; i-- {
+		if err := applyDiff(&chain[i], baseObj); err != nil {
+			return err
+		}
+		baseObj = chain[i].Obj
+	}
+	return applyDiff(event, baseObj)
+}
+
+// baseChain loads decoded bases of event, nearest first, up to the full snapshot.
+// Bases are the previous PatchDepth versions of the object, they are loaded in a single query,
+// bases not among them, e.g. after the object was converted, are loaded one by one.
+func (s *Store) baseChain(event *models.Event) ([]models.Event, error) {
+	var versions []models.Event
+	if err := s.versionsOf(event).
+		Where("id <= ?", event.BaseID).
+		Order("id desc").
+		Limit(event.PatchDepth).
+		Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*models.Event, len(versions))
+	for i := range versions {
+		byID[versions[i].ID] = &versions[i]
+	}
+
+	var chain []models.Event
+	for id, next := event.BaseID, event.ID; id != 0; {
+		// bases are always saved before events based on them, a cycle is a corrupted chain
+		if id >= next {
+			return nil, errors.Errorf("invalid base %d of event %d", id, next)
+		}
+		base, ok := byID[id]
+		if !ok {
+			base = &models.Event{}
+			if err := s.DB.First(base, id).Error; err != nil {
+				return nil, errors.Wrapf(err, "find base %d of event %d error", id, next)
+			}
+		}
+		if err := base.Decode(); err != nil {
+			return nil, err
+		}
+		chain = append(chain, *base)
+		id, next = base.BaseID, base.ID
+	}
+	return chain, nil
+}
+
+// applyDiff reconstructs full objects of event from full Obj of base
+func applyDiff(event *models.Event, baseObj []byte) error {
+	obj, err := jsonpatch.MergePatch(baseObj, event.Obj)
+	if err != nil {
+		return errors.Wrapf(err, "apply patch of event %d error", event.ID)
+	}
+	var oldObj []byte
+	if event.OldObj != nil {
+		oldObj, err = jsonpatch.MergePatch(obj, event.OldObj)
+		if err != nil {
+			return errors.Wrapf(err, "apply patch of old object of event %d error", event.ID)
+		}
+	}
+	event.Obj = obj
+	event.OldObj = oldObj
+	event.BaseID = 0
+	event.PatchDepth = 0
+	return nil
+}
+
+// materialize stores full objects in events based on events in ids,
+// so that events in ids can be deleted.
+func (s *Store) materialize(ids []uint) error {
+	var events []models.Event
+	if err := s.DB.
+		Where("base_id in ?", ids).
+		Where("id not in ?", ids).
+		Find(&events).Error; err != nil {
+		return errors.Wrap(err, "list events based on events to delete error")
+	}
+	for i := range events {
+		e := &events[i]
+		if err := s.resolve(e); err != nil {
+			return err
+		}
+		if err := s.updateObjects(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateObjects updates stored objects of event, compressed when compression is enabled
+func (s *Store) updateObjects(event *models.Event) error {
+	stored := *event
+	if err := stored.Encode(s.Options.Compression); err != nil {
+		return err
+	}
+	return s.DB.Model(&models.Event{}).
+		Where("id = ?", stored.ID).
+		Updates(map[string]interface{}{
+			"obj":         stored.Obj,
+			"old_obj":     stored.OldObj,
+			"base_id":     stored.BaseID,
+			"patch_depth": stored.PatchDepth,
+			"codec":       stored.Codec,
+		}).Error
+}
+
+// ConvertResult is the number of events converted
+type ConvertResult struct {
+	Objects   int64
+	Converted int64
+}
+
+// Convert rewrites stored objects of all events in the storage mode and compression of the store,
+// events of an object are converted in a transaction, so it's safe to run with the controller.
+func (s *Store) Convert(dryRun bool) (result ConvertResult, err error) {
+	result.Converted, err = s.convertUnversioned(dryRun)
+	if err != nil {
+		return result, err
+	}
+
+	var objects []models.Event
+	if err := s.DB.Model(&models.Event{}).
+		Distinct("cluster", "informer_name", "event_group", "version", "resource",
+			"namespace", "name", "uid").
+		Where("event_type <> ?", models.EventTypeOccurred).
+		Where("obj is not null").
+		Find(&objects).Error; err != nil {
+		return result, errors.Wrap(err, "list objects error")
+	}
+
+	for i := range objects {
+		err := s.DB.Transaction(func(tx *gorm.DB) error {
+			converted, err := (&Store{DB: tx, Options: s.Options}).convertObject(&objects[i])
+			result.Converted += converted
+			if err != nil {
+				return err
+			}
+			if dryRun {
+				return errDryRun
+			}
+			return nil
+		})
+		if err != nil && err != errDryRun {
+			return result, errors.Wrapf(err, "convert events of %s/%s error",
+				objects[i].Namespace, objects[i].Name)
+		}
+		result.Objects++
+	}
+	return result, nil
+}
+
+// convertObject rewrites stored objects of events of the object
+func (s *Store) convertObject(object *models.Event) (converted int64, err error) {
+	var events []models.Event
+	if err := s.versionsOf(object).Order("id").Find(&events).Error; err != nil {
+		return 0, errors.Wrap(err, "list versions error")
+	}
+
+	// events are resolved in order, bases are always resolved before events based on them
+	resolved := make(map[uint][]byte, len(events))
+	var base *models.Event
+	for i := range events {
+		e := &events[i]
+		original := *e
+
+		if err := e.Decode(); err != nil {
+			return converted, err
+		}
+		if e.BaseID != 0 {
+			baseObj, ok := resolved[e.BaseID]
+			if !ok {
+				return converted, errors.Errorf("base %d of event %d not found", e.BaseID, e.ID)
+			}
+			if err := applyDiff(e, baseObj); err != nil {
+				return converted, err
+			}
+		}
+		e.PatchDepth = 0
+		resolved[e.ID] = e.Obj
+
+		full := *e
+		if s.Options.StorageMode == StorageModeDiff && base != nil {
+			encodeDiff(e, base, s.Options.SnapshotInterval)
+		}
+		// the next event is based on this one, with its new depth
+		full.PatchDepth = e.PatchDepth
+		base = &full
+
+		stored := *e
+		if err := stored.Encode(s.Options.Compression); err != nil {
+			return converted, err
+		}
+		if stored.BaseID != original.BaseID || stored.PatchDepth != original.PatchDepth ||
+			stored.Codec != original.Codec || string(stored.Obj) != string(original.Obj) ||
+			string(stored.OldObj) != string(original.OldObj) {
+			if err := s.updateObjects(e); err != nil {
+				return converted, errors.Wrap(err, "update event error")
+			}
+			converted++
+		}
+	}
+	return converted, nil
+}
+
+// convertUnversioned rewrites stored objects of events not versioned in batches, e.g. OCCURRED events,
+// only compression applies to them.
+func (s *Store) convertUnversioned(dryRun bool) (converted int64, err error) {
+	var lastID uint
+	for {
+		var events []models.Event
+		if err := s.DB.
+			Where("id > ?", lastID).
+			Where("event_type = ?", models.EventTypeOccurred).
+			Where("coalesce(codec, ?) <> ?", models.CodecNone, s.Options.Compression).
+			Order("id").
+			Limit(convertBatchSize).
+			Find(&events).Error; err != nil {
+			return converted, errors.Wrap(err, "list events error")
+		}
+		if len(events) == 0 {
+			return converted, nil
+		}
+		lastID = events[len(events)-1].ID
+		if dryRun {
+			converted += int64(len(events))
+			continue
+		}
+
+		err := s.DB.Transaction(func(tx *gorm.DB) error {
+			store := &Store{DB: tx, Options: s.Options}
+			for i := range events {
+				if err := events[i].Decode(); err != nil {
+					return err
+				}
+				if err := store.updateObjects(&events[i]); err != nil {
+					return errors.Wrap(err, "update event error")
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return converted, err
+		}
+		converted += int64(len(events))
+	}
+}
@@ -0,0 +1,299 @@
+package event_store
+
+import (
+	"fmt"
+	"github.com/spongeprojects/kubebigbrother/pkg/gormdb"
+	"github.com/spongeprojects/kubebigbrother/pkg/models"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func configMapVersion(i int) []byte {
+	return []byte(fmt.Sprintf(
+		`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"a","resourceVersion":"%d"},`+
+			`"data":{"large":"%s","version":"%d"}}`, i, strings.Repeat("x", 512), i))
+}
+
+func TestDiffStorage(t *testing.T) {
+	assertions := require.New(t)
+
+	db, err := gormdb.New("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	assertions.Nil(err)
+	store, err := NewWithOptions(db, Options{StorageMode: StorageModeDiff, SnapshotInterval: 3})
+	assertions.Nil(err)
+
+	_, err = NewWithOptions(db, Options{StorageMode: "unknown"})
+	assertions.NotNil(err)
+
+	save := func(eventType string, obj, oldObj []byte) *models.Event {
+		e := &models.Event{
+			InformerName: "w1",
+			EventType:    eventType,
+			Resource:     "configmaps",
+			Namespace:    "demo",
+			Name:         "a",
+			UID:          "uid-a",
+			Obj:          obj,
+			OldObj:       oldObj,
+		}
+		assertions.Nil(store.Save(e))
+		// the caller keeps full objects
+		assertions.Equal(obj, e.Obj)
+		assertions.Zero(e.BaseID)
+		return e
+	}
+
+	var ids []uint
+	ids = append(ids, save("ADDED", configMapVersion(0), nil).ID)
+	for i := 1; i < 6; i++ {
+		ids = append(ids, save("UPDATED", configMapVersion(i), configMapVersion(i-1)).ID)
+	}
+	// a null value can't be expressed by a merge patch, stored in full
+	withNull := []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"a"},"data":null}`)
+	ids = append(ids, save("UPDATED", withNull, configMapVersion(5)).ID)
+
+	var stored []models.Event
+	assertions.Nil(db.Order("id").Find(&stored).Error)
+	var depths []int
+	for _, e := range stored {
+		depths = append(depths, e.PatchDepth)
+	}
+	assertions.Equal([]int{0, 1, 2, 0, 1, 2, 0}, depths)
+	assertions.Equal(stored[1].ID, stored[2].BaseID)
+	assertions.Less(len(stored[2].Obj)+len(stored[2].OldObj), len(configMapVersion(2)))
+	// patches are only resolved by the store
+	_, err = stored[2].GetObj()
+	assertions.Equal(models.ErrUnresolved, err)
+	_, err = stored[2].GetOldObj()
+	assertions.Equal(models.ErrUnresolved, err)
+	obj, err := stored[0].GetObj()
+	assertions.Nil(err)
+	assertions.Equal("0", obj.GetResourceVersion())
+
+	assertFull := func() {
+		for i, id := range ids[:6] {
+			e, err := store.Find(id)
+			assertions.Nil(err)
+			assertions.JSONEq(string(configMapVersion(i)), string(e.Obj))
+			if i > 0 {
+				assertions.JSONEq(string(configMapVersion(i-1)), string(e.OldObj))
+			}
+		}
+		e, err := store.Find(ids[6])
+		assertions.Nil(err)
+		assertions.Equal(withNull, e.Obj)
+
+		events, err := store.List(ListOptions{InformerName: "w1"})
+		assertions.Nil(err)
+		assertions.Len(events, len(ids))
+		for _, e := range events {
+			assertions.Zero(e.BaseID)
+			assertions.Equal(byte('{'), e.Obj[0])
+		}
+	}
+	assertFull()
+
+	// events based on pruned events are stored in full
+	_, err = store.Prune(func(string, string) RetentionPolicy {
+		return RetentionPolicy{MaxVersions: 3}
+	}, PruneOptions{})
+	assertions.Nil(err)
+	var remaining []models.Event
+	assertions.Nil(db.Order("id").Find(&remaining).Error)
+	// the ADDED event is protected
+	assertions.Len(remaining, 4)
+	assertions.Equal(ids[0], remaining[0].ID)
+	assertions.Equal(ids[4], remaining[1].ID)
+	assertions.Zero(remaining[1].BaseID)
+	assertions.Equal(ids[4], remaining[2].BaseID)
+	ids = []uint{ids[0], ids[4], ids[5], ids[6]}
+	for i, id := range ids[1:3] {
+		e, err := store.Find(id)
+		assertions.Nil(err)
+		assertions.JSONEq(string(configMapVersion(i+4)), string(e.Obj))
+		assertions.JSONEq(string(configMapVersion(i+3)), string(e.OldObj))
+	}
+}
+
+func TestResolveChain(t *testing.T) {
+	assertions := require.New(t)
+
+	db, err := gormdb.New("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	assertions.Nil(err)
+	store, err := NewWithOptions(db, Options{StorageMode: StorageModeDiff, SnapshotInterval: 10})
+	assertions.Nil(err)
+
+	for i := 0; i < 6; i++ {
+		e := &models.Event{InformerName: "w1", EventType: "UPDATED", Resource: "configmaps",
+			Namespace: "demo", Name: "a", UID: "uid-a", Obj: configMapVersion(i)}
+		if i > 0 {
+			e.OldObj = configMapVersion(i - 1)
+		}
+		assertions.Nil(store.Save(e))
+	}
+	var latest models.Event
+	assertions.Nil(db.Order("id desc").First(&latest).Error)
+	assertions.Equal(5, latest.PatchDepth)
+
+	queries := 0
+	assertions.Nil(db.Callback().Query().Before("gorm:query").Register("test:count", func(*gorm.DB) {
+		queries++
+	}))
+	assertions.Nil(store.(*Store).resolve(&latest))
+	// the whole chain is loaded in a single query
+	assertions.Equal(1, queries)
+	assertions.Zero(latest.BaseID)
+	assertions.JSONEq(string(configMapVersion(5)), string(latest.Obj))
+	assertions.JSONEq(string(configMapVersion(4)), string(latest.OldObj))
+}
+
+func TestConvert(t *testing.T) {
+	assertions := require.New(t)
+
+	db, err := gormdb.New("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	assertions.Nil(err)
+	fullStore := New(db)
+	for i := 0; i < 4; i++ {
+		e := &models.Event{
+			InformerName: "w1",
+			EventType:    "UPDATED",
+			Resource:     "configmaps",
+			Namespace:    "demo",
+			Name:         "a",
+			Obj:          configMapVersion(i),
+		}
+		if i > 0 {
+			e.OldObj = configMapVersion(i - 1)
+		}
+		assertions.Nil(fullStore.Save(e))
+	}
+	assertions.Nil(fullStore.Save(&models.Event{InformerName: "w1", EventType: "OCCURRED",
+		Resource: "events", Namespace: "demo", Name: "a.1", Obj: configMapVersion(0)}))
+
+	diffStore, err := NewWithOptions(db, Options{StorageMode: StorageModeDiff})
+	assertions.Nil(err)
+
+	result, err := diffStore.Convert(true)
+	assertions.Nil(err)
+	assertions.Equal(ConvertResult{Objects: 1, Converted: 3}, result)
+	var count int64
+	assertions.Nil(db.Model(&models.Event{}).Where("base_id <> 0").Count(&count).Error)
+	assertions.Zero(count)
+
+	result, err = diffStore.Convert(false)
+	assertions.Nil(err)
+	assertions.Equal(ConvertResult{Objects: 1, Converted: 3}, result)
+	assertions.Nil(db.Model(&models.Event{}).Where("base_id <> 0").Count(&count).Error)
+	assertions.Equal(int64(3), count)
+
+	assertVersions := func() {
+		events, err := fullStore.List(ListOptions{})
+		assertions.Nil(err)
+		assertions.Len(events, 5)
+		for i, e := range events[1:] {
+			assertions.JSONEq(string(configMapVersion(3-i)), string(e.Obj))
+		}
+	}
+	assertVersions()
+
+	result, err = fullStore.Convert(false)
+	assertions.Nil(err)
+	assertions.Equal(ConvertResult{Objects: 1, Converted: 3}, result)
+	assertions.Nil(db.Model(&models.Event{}).Where("base_id <> 0").Count(&count).Error)
+	assertions.Zero(count)
+	assertVersions()
+}
+
+func TestCompression(t *testing.T) {
+	assertions := require.New(t)
+
+	db, err := gormdb.New("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	assertions.Nil(err)
+	_, err = NewWithOptions(db, Options{Compression: "unknown"})
+	assertions.NotNil(err)
+
+	// events saved before compression is enabled coexist with compressed events
+	assertions.Nil(New(db).Save(&models.Event{InformerName: "w1", EventType: "OCCURRED",
+		Resource: "events", Namespace: "demo", Name: "a.1", Obj: configMapVersion(0)}))
+
+	store, err := NewWithOptions(db, Options{StorageMode: StorageModeDiff, Compression: models.CodecGzip})
+	assertions.Nil(err)
+	for i := 0; i < 3; i++ {
+		e := &models.Event{
+			InformerName: "w1",
+			EventType:    "UPDATED",
+			Resource:     "configmaps",
+			Namespace:    "demo",
+			Name:         "a",
+			Obj:          configMapVersion(i),
+		}
+		if i > 0 {
+			e.OldObj = configMapVersion(i - 1)
+		}
+		assertions.Nil(store.Save(e))
+		assertions.Equal(configMapVersion(i), e.Obj)
+	}
+
+	var stored []models.Event
+	assertions.Nil(db.Order("id").Find(&stored).Error)
+	assertions.Equal(models.CodecNone, stored[0].Codec)
+	for _, e := range stored[1:] {
+		assertions.Equal(models.CodecGzip, e.Codec)
+	}
+	assertions.Zero(stored[1].BaseID)
+	assertions.Less(len(stored[1].Obj), len(configMapVersion(0)))
+	assertions.Equal(stored[1].ID, stored[2].BaseID)
+	// objects are decoded by GetObj
+	for _, e := range stored[:2] {
+		obj, err := e.GetObj()
+		assertions.Nil(err)
+		assertions.Equal("0", obj.GetResourceVersion())
+	}
+
+	events, err := store.List(ListOptions{})
+	assertions.Nil(err)
+	assertions.Len(events, 4)
+	for i, e := range events[:3] {
+		assertions.Equal(models.CodecNone, e.Codec)
+		assertions.JSONEq(string(configMapVersion(2-i)), string(e.Obj))
+	}
+
+	result, err := store.Convert(false)
+	assertions.Nil(err)
+	assertions.Equal(ConvertResult{Objects: 1, Converted: 1}, result)
+	var count int64
+	assertions.Nil(db.Model(&models.Event{}).Where("codec = ?", models.CodecGzip).Count(&count).Error)
+	assertions.Equal(int64(4), count)
+
+	// recompress with zstd
+	zstdStore, err := NewWithOptions(db, Options{StorageMode: StorageModeDiff, Compression: models.CodecZstd})
+	assertions.Nil(err)
+	result, err = zstdStore.Convert(false)
+	assertions.Nil(err)
+	assertions.Equal(ConvertResult{Objects: 1, Converted: 4}, result)
+	assertions.Nil(db.Model(&models.Event{}).Where("codec = ?", models.CodecZstd).Count(&count).Error)
+	assertions.Equal(int64(4), count)
+	events, err = zstdStore.List(ListOptions{})
+	assertions.Nil(err)
+	for i, e := range events[:3] {
+		assertions.JSONEq(string(configMapVersion(2-i)), string(e.Obj))
+	}
+
+	// decompress everything
+	fullStore, err := NewWithOptions(db, Options{Compression: CompressionNone})
+	assertions.Nil(err)
+	result, err = fullStore.Convert(false)
+	assertions.Nil(err)
+	assertions.Equal(ConvertResult{Objects: 1, Converted: 4}, result)
+	assertions.Nil(db.Order("id").Find(&stored).Error)
+	for i, e := range stored {
+		assertions.Equal(models.CodecNone, e.Codec)
+		assertions.Zero(e.BaseID)
+		if i > 0 {
+			assertions.JSONEq(string(configMapVersion(i-1)), string(e.Obj))
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package diff
+
+import (
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// noiseFields are removed before diffing, they change on every update
+var noiseFields = [][]string{
+	{"metadata", "managedFields"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "generation"},
+}
+
+// Objects returns unified diff of objects in YAML, empty if no difference,
+// fields changing on every update, e.g. resourceVersion, are ignored.
+func Objects(oldObj, obj *unstructured.Unstructured) (string, error) {
+	a, err := toYAML(oldObj)
+	if err != nil {
+		return "", err
+	}
+	b, err := toYAML(obj)
+	if err != nil {
+		return "", err
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(a),
+		B:        difflib.SplitLines(b),
+		FromFile: "old",
+		ToFile:   "new",
+		Context:  3,
+	})
+}
+
+func toYAML(obj *unstructured.Unstructured) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+	obj = obj.DeepCopy()
+	for _, field := range noiseFields {
+		unstructured.RemoveNestedField(obj.Object, field...)
+	}
+	b, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return "", errors.Wrap(err, "yaml marshal error")
+	}
+	return string(b), nil
+}
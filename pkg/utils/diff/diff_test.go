@@ -0,0 +1,36 @@
+package diff
+
+import (
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+)
+
+func newDeployment(resourceVersion string, replicas int64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"namespace":       "demo",
+			"name":            "canary",
+			"resourceVersion": resourceVersion,
+		},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+		},
+	}}
+}
+
+func TestObjects(t *testing.T) {
+	assertions := require.New(t)
+
+	d, err := Objects(newDeployment("1", 1), newDeployment("2", 1))
+	assertions.Nil(err)
+	assertions.Empty(d)
+
+	d, err = Objects(newDeployment("1", 1), newDeployment("2", 3))
+	assertions.Nil(err)
+	assertions.Contains(d, "-  replicas: 1\n")
+	assertions.Contains(d, "+  replicas: 3\n")
+	assertions.NotContains(d, "resourceVersion")
+}
@@ -0,0 +1,336 @@
+package fieldpath
+
+import (
+	"github.com/pkg/errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Segment is a segment of a Path, either a map key or an array index
+type Segment struct {
+	Key     string
+	Index   int
+	IsIndex bool
+}
+
+// Path is the path of a field in an object,
+// e.g. .spec.template.spec.containers[0].image
+type Path []Segment
+
+// String returns path in JSONPath format,
+// keys containing special characters are quoted, e.g. .metadata.annotations['app.kubernetes.io/name']
+func (p Path) String() string {
+	b := &strings.Builder{}
+	for _, s := range p {
+		switch {
+		case s.IsIndex:
+			b.WriteString("[" + strconv.Itoa(s.Index) + "]")
+		case strings.ContainsAny(s.Key, ".[]'\"\\*") || s.Key == "":
+			b.WriteString("['" + strings.ReplaceAll(s.Key, "'", "\\'") + "']")
+		default:
+			b.WriteString("." + s.Key)
+		}
+	}
+	return b.String()
+}
+
+// patternSegment is a segment of a Pattern
+type patternSegment struct {
+	Segment
+	Wildcard bool
+}
+
+// Pattern is a parsed JSONPath expression used to match Path,
+// a Pattern matches all fields under the field it points to.
+type Pattern struct {
+	expr     string
+	segments []patternSegment
+}
+
+// String returns the original expression
+func (p *Pattern) String() string {
+	return p.expr
+}
+
+// Parse parses a JSONPath expression as Pattern, supported syntax:
+//
+//	.spec.replicas, spec.replicas, {.spec.replicas}, $.spec.replicas
+//	.spec.containers[*].image, .spec.containers[0].image
+//	.metadata.annotations.app\.kubernetes\.io/name
+//	.metadata.annotations['app.kubernetes.io/name']
+//	.metadata.labels.*
+func Parse(expr string) (*Pattern, error) {
+	s := strings.TrimSpace(expr)
+	if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+		s = s[1 : len(s)-1]
+	}
+	s = strings.TrimPrefix(s, "$")
+	if s != "" && s[0] != '.' && s[0] != '[' {
+		s = "." + s
+	}
+
+	var segments []patternSegment
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case '.':
+			i++
+			var key strings.Builder
+			for ; i < len(s) && s[i] != '.' && s[i] != '['; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				key.WriteByte(s[i])
+			}
+			if key.Len() == 0 {
+				if i >= len(s) && len(segments) == 0 {
+					break // "." matches everything
+				}
+				return nil, errors.Errorf("empty field name at %d: %s", i, expr)
+			}
+			if key.String() == "*" {
+				segments = append(segments, patternSegment{Wildcard: true})
+			} else {
+				segments = append(segments, patternSegment{Segment: Segment{Key: key.String()}})
+			}
+		case '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return nil, errors.Errorf("unterminated bracket at %d: %s", i, expr)
+			}
+			inner := s[i+1 : i+end]
+			i += end + 1
+			switch {
+			case inner == "*":
+				segments = append(segments, patternSegment{Wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				key := strings.ReplaceAll(inner[1:len(inner)-1], "\\"+string(inner[0]), string(inner[0]))
+				segments = append(segments, patternSegment{Segment: Segment{Key: key}})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, errors.Errorf("unsupported subscript [%s]: %s", inner, expr)
+				}
+				segments = append(segments, patternSegment{Segment: Segment{Index: index, IsIndex: true}})
+			}
+		default:
+			return nil, errors.Errorf("unexpected character %q at %d: %s", s[i], i, expr)
+		}
+	}
+
+	return &Pattern{
+		expr:     expr,
+		segments: segments,
+	}, nil
+}
+
+// ParseAll parses all expressions
+func ParseAll(exprs []string) ([]*Pattern, error) {
+	patterns := make([]*Pattern, 0, len(exprs))
+	for _, expr := range exprs {
+		p, err := Parse(expr)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
+
+// Match checks whether path is the field, or is under the field, the pattern points to
+func (p *Pattern) Match(path Path) bool {
+	if len(path) < len(p.segments) {
+		return false
+	}
+	for i, ps := range p.segments {
+		if ps.Wildcard {
+			continue
+		}
+		if ps.IsIndex != path[i].IsIndex || ps.Key != path[i].Key || ps.Index != path[i].Index {
+			return false
+		}
+	}
+	return true
+}
+
+// Remove removes fields the pattern points to from obj in place,
+// an empty pattern removes nothing.
+func (p *Pattern) Remove(obj map[string]interface{}) {
+	if len(p.segments) == 0 {
+		return
+	}
+	walk(obj, p.segments, nil)
+}
+
+// Replace replaces values of fields the pattern points to in obj in place,
+// an empty pattern replaces nothing.
+func (p *Pattern) Replace(obj map[string]interface{}, replace func(v interface{}) interface{}) {
+	if len(p.segments) == 0 {
+		return
+	}
+	walk(obj, p.segments, replace)
+}
+
+// walk replaces fields matching segments in v, or removes them if replace is nil,
+// returns v or its replacement
+func walk(v interface{}, segments []patternSegment, replace func(v interface{}) interface{}) interface{} {
+	s := segments[0]
+	last := len(segments) == 1
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		if s.IsIndex {
+			return v
+		}
+		for k, child := range vv {
+			if !s.Wildcard && k != s.Key {
+				continue
+			}
+			switch {
+			case !last:
+				vv[k] = walk(child, segments[1:], replace)
+			case replace != nil:
+				vv[k] = replace(child)
+			default:
+				delete(vv, k)
+			}
+		}
+	case []interface{}:
+		if !s.Wildcard && !s.IsIndex {
+			return v
+		}
+		if last && replace == nil {
+			if s.Wildcard {
+				return []interface{}{}
+			}
+			if s.Index < len(vv) {
+				return append(vv[:s.Index:s.Index], vv[s.Index+1:]...)
+			}
+			return v
+		}
+		for i, child := range vv {
+			if !s.Wildcard && i != s.Index {
+				continue
+			}
+			if last {
+				vv[i] = replace(child)
+			} else {
+				vv[i] = walk(child, segments[1:], replace)
+			}
+		}
+	}
+	return v
+}
+
+// MatchAny checks whether any of patterns matches path
+func MatchAny(patterns []*Pattern, path Path) bool {
+	for _, p := range patterns {
+		if p.Match(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Diff returns paths of all leaf fields which differ between a and b,
+// fields exist in only one of them are included, paths are sorted.
+func Diff(a, b interface{}) []Path {
+	var paths []Path
+	diff(nil, a, b, &paths)
+	sort.Slice(paths, func(i, j int) bool {
+		return paths[i].String() < paths[j].String()
+	})
+	// leaves of both sides are reported when type changed, remove duplicates
+	deduplicated := paths[:0]
+	for i, p := range paths {
+		if i == 0 || p.String() != paths[i-1].String() {
+			deduplicated = append(deduplicated, p)
+		}
+	}
+	return deduplicated
+}
+
+func diff(prefix Path, a, b interface{}, paths *[]Path) {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		if bv, ok := b.(map[string]interface{}); ok {
+			for k, v := range av {
+				diff(appendSegment(prefix, Segment{Key: k}), v, bv[k], paths)
+			}
+			for k, v := range bv {
+				if _, exist := av[k]; !exist {
+					diff(appendSegment(prefix, Segment{Key: k}), nil, v, paths)
+				}
+			}
+			return
+		}
+	case []interface{}:
+		if bv, ok := b.([]interface{}); ok {
+			for i := 0; i < len(av) || i < len(bv); i++ {
+				var x, y interface{}
+				if i < len(av) {
+					x = av[i]
+				}
+				if i < len(bv) {
+					y = bv[i]
+				}
+				diff(appendSegment(prefix, Segment{Index: i, IsIndex: true}), x, y, paths)
+			}
+			return
+		}
+	}
+	if reflect.DeepEqual(a, b) {
+		return
+	}
+	// type changed, or field added/removed, report all leaves of both sides
+	if a != nil && b != nil && !isContainer(a) && !isContainer(b) {
+		*paths = append(*paths, prefix)
+		return
+	}
+	reported := len(*paths)
+	leaves(prefix, a, paths)
+	leaves(prefix, b, paths)
+	if len(*paths) == reported {
+		*paths = append(*paths, prefix)
+	}
+}
+
+// leaves appends paths of all leaf fields in v
+func leaves(prefix Path, v interface{}, paths *[]Path) {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		for k, child := range vv {
+			leaves(appendSegment(prefix, Segment{Key: k}), child, paths)
+		}
+	case []interface{}:
+		for i, child := range vv {
+			leaves(appendSegment(prefix, Segment{Index: i, IsIndex: true}), child, paths)
+		}
+	case nil:
+	default:
+		*paths = append(*paths, prefix)
+	}
+}
+
+func isContainer(v interface{}) bool {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		return true
+	}
+	return false
+}
+
+func appendSegment(prefix Path, s Segment) Path {
+	p := make(Path, len(prefix), len(prefix)+1)
+	copy(p, prefix)
+	return append(p, s)
+}
+
+// Strings formats paths as strings
+func Strings(paths []Path) []string {
+	s := make([]string, 0, len(paths))
+	for _, p := range paths {
+		s = append(s, p.String())
+	}
+	return s
+}
@@ -0,0 +1,138 @@
+package fieldpath
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	assertions := require.New(t)
+
+	for expr, expected := range map[string]string{
+		".spec.replicas":          ".spec.replicas",
+		"spec.replicas":           ".spec.replicas",
+		"{.spec.replicas}":        ".spec.replicas",
+		"$.spec.replicas":         ".spec.replicas",
+		".spec.containers[0].tag": ".spec.containers[0].tag",
+		`.metadata.annotations.app\.kubernetes\.io/name`:  ".metadata.annotations['app.kubernetes.io/name']",
+		".metadata.annotations['app.kubernetes.io/name']": ".metadata.annotations['app.kubernetes.io/name']",
+	} {
+		p, err := Parse(expr)
+		assertions.Nil(err, expr)
+		var path Path
+		for _, s := range p.segments {
+			path = append(path, s.Segment)
+		}
+		assertions.Equal(expected, path.String(), expr)
+	}
+
+	for _, expr := range []string{".spec..replicas", ".spec.containers[x]", ".spec.containers[0", "[0]x"} {
+		_, err := Parse(expr)
+		assertions.NotNil(err, expr)
+	}
+}
+
+func TestDiffAndMatch(t *testing.T) {
+	assertions := require.New(t)
+
+	a := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				"app.kubernetes.io/name": "a",
+			},
+		},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": "app:v1"},
+				map[string]interface{}{"name": "sidecar", "image": "sidecar:v1"},
+			},
+		},
+		"status": map[string]interface{}{"phase": "Running"},
+	}
+	b := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				"app.kubernetes.io/name": "b",
+			},
+		},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": "app:v2"},
+			},
+		},
+	}
+
+	paths := Diff(a, b)
+	assertions.Equal([]string{
+		".metadata.annotations['app.kubernetes.io/name']",
+		".spec.containers[0].image",
+		".spec.containers[1].image",
+		".spec.containers[1].name",
+		".status.phase",
+	}, Strings(paths))
+
+	images, err := Parse(".spec.containers[*].image")
+	assertions.Nil(err)
+	status, err := Parse(".status")
+	assertions.Nil(err)
+
+	assertions.True(images.Match(paths[1]))
+	assertions.True(images.Match(paths[2]))
+	assertions.False(images.Match(paths[3]))
+	assertions.True(MatchAny([]*Pattern{images, status}, paths[4]))
+	assertions.False(MatchAny([]*Pattern{images, status}, paths[0]))
+}
+
+func TestRemove(t *testing.T) {
+	assertions := require.New(t)
+
+	obj := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":          "canary",
+			"managedFields": []interface{}{map[string]interface{}{"manager": "kubectl"}},
+			"annotations": map[string]interface{}{
+				"kubectl.kubernetes.io/last-applied-configuration": "{}",
+				"app": "canary",
+			},
+		},
+		"data": map[string]interface{}{
+			"a": "1",
+			"b": "2",
+		},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "a", "env": []interface{}{"x"}},
+				map[string]interface{}{"name": "b", "env": []interface{}{"y"}},
+			},
+		},
+	}
+
+	for _, expr := range []string{
+		".metadata.managedFields",
+		".metadata.annotations['kubectl.kubernetes.io/last-applied-configuration']",
+		".data.*",
+		".spec.containers[*].env",
+		".spec.notExist.field",
+		".",
+	} {
+		p, err := Parse(expr)
+		assertions.Nil(err, expr)
+		p.Remove(obj)
+	}
+
+	assertions.Equal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name": "canary",
+			"annotations": map[string]interface{}{
+				"app": "canary",
+			},
+		},
+		"data": map[string]interface{}{},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "a"},
+				map[string]interface{}{"name": "b"},
+			},
+		},
+	}, obj)
+}
@@ -2,13 +2,29 @@ package resourcebuilder
 
 import (
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
 )
 
 // Interface is used to parse resource name
 type Interface interface {
 	ParseGroupResource(resourceArg string) (schema.GroupVersionResource, error)
+
+	// ExpandWildcard expands a wildcard resource name through discovery
+	ExpandWildcard(resourceArg string, namespacedOnly bool, excludes []string) ([]schema.GroupVersionResource, error)
+
+	// KindFor returns kind of the resource
+	KindFor(gvr schema.GroupVersionResource) (schema.GroupVersionKind, error)
+
+	// RESTMappingFor returns resource and scope of the kind
+	RESTMappingFor(gvk schema.GroupVersionKind) (*meta.RESTMapping, error)
+
+	// Invalidate drops cached discovery information, call it when resources are installed or removed
+	Invalidate()
 }
 
 // ResourceBuilder is used to parse resource name
@@ -31,6 +47,59 @@ func (b *ResourceBuilder) ParseGroupResource(resourceArg string) (schema.GroupVe
 	return infos[0].Mapping.Resource, nil
 }
 
+// ExpandWildcard expands wildcard resourceArg as resources served by the API server,
+// only resources supporting list and watch are returned, in their preferred version.
+// Cluster scoped resources are skipped when namespacedOnly is true.
+func (b *ResourceBuilder) ExpandWildcard(
+	resourceArg string, namespacedOnly bool, excludes []string) ([]schema.GroupVersionResource, error) {
+	group, ok := wildcardGroup(resourceArg)
+	if !ok {
+		return nil, errors.Errorf("not a wildcard: %s", resourceArg)
+	}
+
+	discoveryClient, err := b.ClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "get discovery client error")
+	}
+	lists, err := discoveryClient.ServerPreferredResources()
+	if err != nil {
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			return nil, errors.Wrap(err, "discover resources error")
+		}
+		// unavailable aggregated APIs should not stop other resources from being watched
+		klog.Warningf("some groups cannot be discovered, they are skipped: %s", err)
+	}
+
+	return filterResources(lists, group, namespacedOnly, excludes), nil
+}
+
+// KindFor returns kind of the resource
+func (b *ResourceBuilder) KindFor(gvr schema.GroupVersionResource) (schema.GroupVersionKind, error) {
+	mapper, err := b.ClientGetter.ToRESTMapper()
+	if err != nil {
+		return schema.GroupVersionKind{}, errors.Wrap(err, "get rest mapper error")
+	}
+	return mapper.KindFor(gvr)
+}
+
+// RESTMappingFor returns resource and scope of the kind
+func (b *ResourceBuilder) RESTMappingFor(gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+	mapper, err := b.ClientGetter.ToRESTMapper()
+	if err != nil {
+		return nil, errors.Wrap(err, "get rest mapper error")
+	}
+	return mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+}
+
+// Invalidate drops cached discovery information
+func (b *ResourceBuilder) Invalidate() {
+	discoveryClient, err := b.ClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return
+	}
+	discoveryClient.Invalidate()
+}
+
 // NewFromClientGetter creates new ResourceBuilder
 func NewFromClientGetter(clientGetter resource.RESTClientGetter) (*ResourceBuilder, error) {
 	return &ResourceBuilder{
@@ -46,3 +115,12 @@ func New(kubeconfig string) (*ResourceBuilder, error) {
 	}
 	return NewFromClientGetter(clientGetter)
 }
+
+// NewForConfig creates new ResourceBuilder from rest config
+func NewForConfig(restConfig *rest.Config) (*ResourceBuilder, error) {
+	clientGetter, err := NewPersistentRESTClientGetterForConfig(restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewPersistentRESTClientGetterForConfig error")
+	}
+	return NewFromClientGetter(clientGetter)
+}
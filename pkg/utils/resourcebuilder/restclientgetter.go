@@ -41,11 +41,12 @@ func NewPersistentRESTClientGetter(kubeconfig string) (*PersistentRESTClientGett
 	if err != nil {
 		return nil, errors.Wrap(err, "clientcmd.BuildConfigFromFlags error")
 	}
+	return NewPersistentRESTClientGetterForConfig(restConfig)
+}
 
-	discoveryConfig, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
-	if err != nil {
-		return nil, errors.Wrap(err, "clientcmd.BuildConfigFromFlags error")
-	}
+// NewPersistentRESTClientGetterForConfig creates new PersistentRESTClientGetter from rest config
+func NewPersistentRESTClientGetterForConfig(restConfig *rest.Config) (*PersistentRESTClientGetter, error) {
+	discoveryConfig := rest.CopyConfig(restConfig)
 
 	// The more groups you have, the more discovery requests you need to make.
 	// given 25 groups (our groups + a few custom resources) with one-ish version each, discovery needs to make 50 requests
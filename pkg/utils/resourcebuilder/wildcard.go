@@ -0,0 +1,92 @@
+package resourcebuilder
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sort"
+	"strings"
+)
+
+// coreGroupAlias is used in wildcards to select the core group, whose name is empty
+const coreGroupAlias = "core"
+
+// IsWildcard checks whether resourceArg selects multiple resources,
+// "*" selects all resources, "*.cert-manager.io" selects all resources in group cert-manager.io,
+// "*.core" selects all resources in the core group.
+func IsWildcard(resourceArg string) bool {
+	_, ok := wildcardGroup(resourceArg)
+	return ok
+}
+
+// wildcardGroup returns group selected by the wildcard, "*" for all groups
+func wildcardGroup(resourceArg string) (string, bool) {
+	if resourceArg == "*" {
+		return "*", true
+	}
+	if !strings.HasPrefix(resourceArg, "*.") || len(resourceArg) == 2 {
+		return "", false
+	}
+	group := strings.TrimPrefix(resourceArg, "*.")
+	if group == coreGroupAlias {
+		group = ""
+	}
+	return group, true
+}
+
+// filterResources selects watchable resources in group from discovery results
+func filterResources(lists []*metav1.APIResourceList,
+	group string, namespacedOnly bool, excludes []string) []schema.GroupVersionResource {
+	var gvrs []schema.GroupVersionResource
+	for _, list := range lists {
+		if list == nil {
+			continue
+		}
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		if group != "*" && gv.Group != group {
+			continue
+		}
+		for _, r := range list.APIResources {
+			if strings.Contains(r.Name, "/") { // subresources, e.g. pods/log
+				continue
+			}
+			if namespacedOnly && !r.Namespaced {
+				continue
+			}
+			verbs := sets(r.Verbs)
+			if !verbs["list"] || !verbs["watch"] {
+				continue
+			}
+			gvr := gv.WithResource(r.Name)
+			if isExcluded(gvr, excludes) {
+				continue
+			}
+			gvrs = append(gvrs, gvr)
+		}
+	}
+	sort.Slice(gvrs, func(i, j int) bool {
+		return gvrs[i].String() < gvrs[j].String()
+	})
+	return gvrs
+}
+
+// isExcluded checks whether gvr is in excludes,
+// an exclude without group matches resources of that name in any group
+func isExcluded(gvr schema.GroupVersionResource, excludes []string) bool {
+	for _, exclude := range excludes {
+		if exclude == gvr.Resource || exclude == gvr.GroupResource().String() {
+			return true
+		}
+	}
+	return false
+}
+
+func sets(items []string) map[string]bool {
+	m := make(map[string]bool, len(items))
+	for _, item := range items {
+		m[item] = true
+	}
+	return m
+}
@@ -0,0 +1,75 @@
+package resourcebuilder
+
+import (
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+var watchable = []string{"get", "list", "watch"}
+
+var discovered = []*metav1.APIResourceList{
+	{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{
+			{Name: "pods", Namespaced: true, Verbs: watchable},
+			{Name: "pods/log", Namespaced: true, Verbs: []string{"get"}},
+			{Name: "events", Namespaced: true, Verbs: watchable},
+			{Name: "nodes", Namespaced: false, Verbs: watchable},
+			{Name: "bindings", Namespaced: true, Verbs: []string{"create"}},
+		},
+	},
+	{
+		GroupVersion: "coordination.k8s.io/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "leases", Namespaced: true, Verbs: watchable},
+		},
+	},
+	{
+		GroupVersion: "cert-manager.io/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "certificates", Namespaced: true, Verbs: watchable},
+			{Name: "clusterissuers", Namespaced: false, Verbs: watchable},
+		},
+	},
+}
+
+func TestIsWildcard(t *testing.T) {
+	assertions := require.New(t)
+	assertions.True(IsWildcard("*"))
+	assertions.True(IsWildcard("*.cert-manager.io"))
+	assertions.True(IsWildcard("*.core"))
+	assertions.False(IsWildcard("*."))
+	assertions.False(IsWildcard("pods"))
+	assertions.False(IsWildcard("deployments.v1.apps"))
+}
+
+func TestFilterResources(t *testing.T) {
+	assertions := require.New(t)
+
+	strs := func(group string, namespacedOnly bool, excludes []string) []string {
+		var s []string
+		for _, gvr := range filterResources(discovered, group, namespacedOnly, excludes) {
+			s = append(s, gvr.String())
+		}
+		return s
+	}
+
+	assertions.Equal([]string{
+		"cert-manager.io/v1, Resource=certificates",
+		"cert-manager.io/v1, Resource=clusterissuers",
+	}, strs("cert-manager.io", false, nil))
+
+	assertions.Equal([]string{
+		"cert-manager.io/v1, Resource=certificates",
+	}, strs("cert-manager.io", true, nil))
+
+	assertions.Equal([]string{
+		"/v1, Resource=pods",
+	}, strs("", true, []string{"events"}))
+
+	assertions.Equal([]string{
+		"/v1, Resource=pods",
+		"cert-manager.io/v1, Resource=certificates",
+	}, strs("*", true, []string{"events", "leases.coordination.k8s.io"}))
+}
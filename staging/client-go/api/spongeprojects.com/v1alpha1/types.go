@@ -0,0 +1,346 @@
+package v1alpha1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Channel is a top-level type
+type Channel struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ChannelSpec `json:"spec,omitempty"`
+}
+
+// ChannelSpec defines a channel to receive notifications
+type ChannelSpec struct {
+	// Type is the type of the channel
+	Type string `json:"type" yaml:"type"`
+
+	Callback *ChannelCallbackConfig `json:"callback,omitempty" yaml:"callback,omitempty"`
+	Dingtalk *ChannelDingtalkConfig `json:"dingtalk,omitempty" yaml:"dingtalk,omitempty"`
+	Flock    *ChannelFlockConfig    `json:"flock,omitempty" yaml:"flock,omitempty"`
+	Print    *ChannelPrintConfig    `json:"print,omitempty" yaml:"print,omitempty"`
+	Slack    *ChannelSlackConfig    `json:"slack,omitempty" yaml:"slack,omitempty"`
+	Telegram *ChannelTelegramConfig `json:"telegram,omitempty" yaml:"telegram,omitempty"`
+
+	// Shim is a special field used in new channel development
+	Shim map[string]string `json:"shim,omitempty" yaml:"shim,omitempty"`
+}
+
+// ChannelCallbackConfig is config for ChannelCallback,
+type ChannelCallbackConfig struct {
+	Method            string `json:"method,omitempty" yaml:"method,omitempty"`
+	URL               string `json:"url" yaml:"url"`
+	Proxy             string `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+	UseTemplate       bool   `json:"useTemplate,omitempty" yaml:"useTemplate,omitempty"`
+	AddedTemplate     string `json:"addedTemplate,omitempty" yaml:"addedTemplate,omitempty"`
+	DeletedTemplate   string `json:"deletedTemplate,omitempty" yaml:"deletedTemplate,omitempty"`
+	UpdatedTemplate   string `json:"updatedTemplate,omitempty" yaml:"updatedTemplate,omitempty"`
+	TriggeredTemplate string `json:"triggeredTemplate,omitempty" yaml:"triggeredTemplate,omitempty"`
+	ResolvedTemplate  string `json:"resolvedTemplate,omitempty" yaml:"resolvedTemplate,omitempty"`
+	StuckTemplate     string `json:"stuckTemplate,omitempty" yaml:"stuckTemplate,omitempty"`
+	OccurredTemplate  string `json:"occurredTemplate,omitempty" yaml:"occurredTemplate,omitempty"`
+}
+
+// ChannelDingtalkConfig is config for ChannelDingtalk,
+type ChannelDingtalkConfig struct {
+	WebhookURL        string   `json:"webhookURL" yaml:"webhookURL"`
+	Proxy             string   `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+	AtMobiles         []string `json:"atMobiles,omitempty" yaml:"atMobiles,omitempty"`
+	AtAll             bool     `json:"atAll,omitempty" yaml:"atAll,omitempty"`
+	AddedTemplate     string   `json:"addedTemplate,omitempty" yaml:"addedTemplate,omitempty"`
+	DeletedTemplate   string   `json:"deletedTemplate,omitempty" yaml:"deletedTemplate,omitempty"`
+	UpdatedTemplate   string   `json:"updatedTemplate,omitempty" yaml:"updatedTemplate,omitempty"`
+	TriggeredTemplate string   `json:"triggeredTemplate,omitempty" yaml:"triggeredTemplate,omitempty"`
+	ResolvedTemplate  string   `json:"resolvedTemplate,omitempty" yaml:"resolvedTemplate,omitempty"`
+	StuckTemplate     string   `json:"stuckTemplate,omitempty" yaml:"stuckTemplate,omitempty"`
+	OccurredTemplate  string   `json:"occurredTemplate,omitempty" yaml:"occurredTemplate,omitempty"`
+}
+
+// ChannelFlockConfig is config for ChannelFlock,
+type ChannelFlockConfig struct {
+	URL               string `json:"url" yaml:"url"`
+	Proxy             string `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+	TitleTemplate     string `json:"titleTemplate,omitempty" yaml:"titleTemplate,omitempty"`
+	AddedTemplate     string `json:"addedTemplate,omitempty" yaml:"addedTemplate,omitempty"`
+	DeletedTemplate   string `json:"deletedTemplate,omitempty" yaml:"deletedTemplate,omitempty"`
+	UpdatedTemplate   string `json:"updatedTemplate,omitempty" yaml:"updatedTemplate,omitempty"`
+	TriggeredTemplate string `json:"triggeredTemplate,omitempty" yaml:"triggeredTemplate,omitempty"`
+	ResolvedTemplate  string `json:"resolvedTemplate,omitempty" yaml:"resolvedTemplate,omitempty"`
+	StuckTemplate     string `json:"stuckTemplate,omitempty" yaml:"stuckTemplate,omitempty"`
+	OccurredTemplate  string `json:"occurredTemplate,omitempty" yaml:"occurredTemplate,omitempty"`
+}
+
+// ChannelPrintConfig is config for ChannelPrint,
+// Format is one of json, yaml and template, json by default, template if UseTemplate is set,
+// Diff appends diff of objects to UPDATED events.
+type ChannelPrintConfig struct {
+	Writer            string `json:"writer" yaml:"writer"`
+	UseTemplate       bool   `json:"useTemplate,omitempty" yaml:"useTemplate,omitempty"`
+	Format            string `json:"format,omitempty" yaml:"format,omitempty"`
+	Diff              bool   `json:"diff,omitempty" yaml:"diff,omitempty"`
+	AddedTemplate     string `json:"addedTemplate,omitempty" yaml:"addedTemplate,omitempty"`
+	DeletedTemplate   string `json:"deletedTemplate,omitempty" yaml:"deletedTemplate,omitempty"`
+	UpdatedTemplate   string `json:"updatedTemplate,omitempty" yaml:"updatedTemplate,omitempty"`
+	TriggeredTemplate string `json:"triggeredTemplate,omitempty" yaml:"triggeredTemplate,omitempty"`
+	ResolvedTemplate  string `json:"resolvedTemplate,omitempty" yaml:"resolvedTemplate,omitempty"`
+	StuckTemplate     string `json:"stuckTemplate,omitempty" yaml:"stuckTemplate,omitempty"`
+	OccurredTemplate  string `json:"occurredTemplate,omitempty" yaml:"occurredTemplate,omitempty"`
+}
+
+// ChannelSlackConfig is config for ChannelSlack,
+type ChannelSlackConfig struct {
+	Token             string `json:"token" yaml:"token"`
+	Proxy             string `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+	WebhookURL        string `json:"webhookURL" yaml:"webhookURL"`
+	TitleTemplate     string `json:"titleTemplate,omitempty" yaml:"titleTemplate,omitempty"`
+	AddedTemplate     string `json:"addedTemplate,omitempty" yaml:"addedTemplate,omitempty"`
+	DeletedTemplate   string `json:"deletedTemplate,omitempty" yaml:"deletedTemplate,omitempty"`
+	UpdatedTemplate   string `json:"updatedTemplate,omitempty" yaml:"updatedTemplate,omitempty"`
+	TriggeredTemplate string `json:"triggeredTemplate,omitempty" yaml:"triggeredTemplate,omitempty"`
+	ResolvedTemplate  string `json:"resolvedTemplate,omitempty" yaml:"resolvedTemplate,omitempty"`
+	StuckTemplate     string `json:"stuckTemplate,omitempty" yaml:"stuckTemplate,omitempty"`
+	OccurredTemplate  string `json:"occurredTemplate,omitempty" yaml:"occurredTemplate,omitempty"`
+}
+
+// ChannelTelegramConfig is config for ChannelTelegram,
+type ChannelTelegramConfig struct {
+	Token             string   `json:"token" yaml:"token"`
+	ChatIDs           []string `json:"chatIDs" yaml:"chatIDs"`
+	Proxy             string   `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+	AddedTemplate     string   `json:"addedTemplate,omitempty" yaml:"addedTemplate,omitempty"`
+	DeletedTemplate   string   `json:"deletedTemplate,omitempty" yaml:"deletedTemplate,omitempty"`
+	UpdatedTemplate   string   `json:"updatedTemplate,omitempty" yaml:"updatedTemplate,omitempty"`
+	TriggeredTemplate string   `json:"triggeredTemplate,omitempty" yaml:"triggeredTemplate,omitempty"`
+	ResolvedTemplate  string   `json:"resolvedTemplate,omitempty" yaml:"resolvedTemplate,omitempty"`
+	StuckTemplate     string   `json:"stuckTemplate,omitempty" yaml:"stuckTemplate,omitempty"`
+	OccurredTemplate  string   `json:"occurredTemplate,omitempty" yaml:"occurredTemplate,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ChannelList no client needed for list as it's been created in above
+type ChannelList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `son:"metadata,omitempty"`
+
+	Items []Channel `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Watcher is a top-level type
+type Watcher struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec WatcherSpec `json:"spec,omitempty"`
+}
+
+type WatcherSpec struct {
+	// Resource is the resource to watch, e.g. "deployments.v1.apps",
+	// wildcards are expanded through discovery: "*" for all resources,
+	// "*.cert-manager.io" for all resources in an API group, "*.core" for the core group.
+	Resource string `json:"resource" yaml:"resource"`
+
+	// ExcludeResources defines resources not to watch when Resource is a wildcard,
+	// e.g. "events" for events in any group, "leases.coordination.k8s.io" for a specific group
+	ExcludeResources []string `json:"excludeResources,omitempty" yaml:"excludeResources,omitempty"`
+
+	// NoticeWhenAdded determine whether to notice when a resource is added
+	NoticeWhenAdded bool `json:"noticeWhenAdded" yaml:"noticeWhenAdded"`
+
+	// NoticeWhenDeleted determine whether to notice when a resource is deleted
+	NoticeWhenDeleted bool `json:"noticeWhenDeleted" yaml:"noticeWhenDeleted"`
+
+	// NoticeWhenUpdated determine whether to notice when a resource is updated,
+	// When UpdateOn is not nil, only notice when fields in UpdateOn is changed
+	NoticeWhenUpdated bool `json:"noticeWhenUpdated" yaml:"noticeWhenUpdated"`
+
+	// UpdateOn defines fields to watch in JSONPath, used with NoticeWhenUpdated,
+	// array wildcards are supported, e.g. .spec.template.spec.containers[*].image
+	UpdateOn []string `json:"updateOn,omitempty" yaml:"updateOn,omitempty"`
+
+	// IgnoreOn defines fields not to watch in JSONPath, used with NoticeWhenUpdated,
+	// it takes precedence over UpdateOn, e.g. .status
+	IgnoreOn []string `json:"ignoreOn,omitempty" yaml:"ignoreOn,omitempty"`
+
+	// SkipNoiseUpdates determine whether to skip updates changing only fields in IgnoreFields,
+	// used with NoticeWhenUpdated
+	SkipNoiseUpdates bool `json:"skipNoiseUpdates,omitempty" yaml:"skipNoiseUpdates,omitempty"`
+
+	// IgnoreFields defines fields to ignore when comparing objects, used with NoticeWhenUpdated,
+	// setting IgnoreFields implies SkipNoiseUpdates,
+	// when empty, managedFields, resourceVersion, generation and status are ignored
+	IgnoreFields []string `json:"ignoreFields,omitempty" yaml:"ignoreFields,omitempty"`
+
+	// TriggerExpression is a CEL expression evaluated on the object, e.g. object.status.phase == "Failed",
+	// notice when it becomes true, NoticeWhenAdded, NoticeWhenDeleted and NoticeWhenUpdated are not required
+	TriggerExpression string `json:"triggerExpression,omitempty" yaml:"triggerExpression,omitempty"`
+
+	// NoticeWhenResolved determine whether to notice when TriggerExpression becomes false again
+	NoticeWhenResolved bool `json:"noticeWhenResolved,omitempty" yaml:"noticeWhenResolved,omitempty"`
+
+	// Timers defines conditions to notice when they hold continuously for a duration
+	Timers []WatcherTimer `json:"timers,omitempty" yaml:"timers,omitempty"`
+
+	// MetadataOnly determine whether to watch and cache metadata of objects only,
+	// it saves memory when only ADDED and DELETED are needed, not allowed in events mode,
+	// or with NoticeWhenUpdated, UpdateOn, IgnoreOn, TriggerExpression and Timers
+	MetadataOnly bool `json:"metadataOnly,omitempty" yaml:"metadataOnly,omitempty"`
+
+	// StripManagedFields determine whether to strip .metadata.managedFields before caching,
+	// the manager and time of the latest entry are kept to record who made changes
+	StripManagedFields bool `json:"stripManagedFields,omitempty" yaml:"stripManagedFields,omitempty"`
+
+	// StripFields defines fields in JSONPath to strip before caching, they are neither noticed nor recorded,
+	// e.g. .data, .metadata.annotations['kubectl.kubernetes.io/last-applied-configuration']
+	StripFields []string `json:"stripFields,omitempty" yaml:"stripFields,omitempty"`
+
+	// RedactFields defines fields in JSONPath to redact before storage and notification,
+	// values are replaced with their hashes, so that changes are still visible,
+	// .data and .stringData of Secrets are always redacted unless the object is annotated with
+	// kubebigbrother.spongeprojects.com/redact: "false"
+	RedactFields []string `json:"redactFields,omitempty" yaml:"redactFields,omitempty"`
+
+	// Events enables events mode, Resource should be "events" or "events.events.k8s.io",
+	// Kubernetes Events are noticed as OCCURRED events, NoticeWhen* flags are ignored
+	Events *WatcherEvents `json:"events,omitempty" yaml:"events,omitempty"`
+
+	// Enrich attaches context to notifications, e.g. recent Warning Events and logs of crashed containers,
+	// it's disabled by default as it costs extra requests to the API server
+	Enrich *WatcherEnrich `json:"enrich,omitempty" yaml:"enrich,omitempty"`
+
+	// Retention overrides the global retention policy of events recorded
+	Retention *WatcherRetention `json:"retention,omitempty" yaml:"retention,omitempty"`
+
+	// OwnerDepth is the max depth of owner chain to resolve, e.g. 2 for Pod -> ReplicaSet -> Deployment,
+	// 0 uses the default depth, -1 disables resolving
+	OwnerDepth int `json:"ownerDepth,omitempty" yaml:"ownerDepth,omitempty"`
+
+	// ChannelNames defines channels to send notification
+	ChannelNames []string `json:"channelNames,omitempty" yaml:"channelNames,omitempty"`
+
+	// LabelSelector selects objects to watch by labels, e.g. app=foo
+	LabelSelector string `json:"labelSelector,omitempty" yaml:"labelSelector,omitempty"`
+
+	// ResyncPeriod is the resync period in reflectors for this resource
+	ResyncPeriod string `json:"resyncPeriod,omitempty" yaml:"resyncPeriod,omitempty"`
+
+	// Workers is the number of workers
+	Workers int `json:"workers,omitempty" yaml:"workers,omitempty"`
+
+	// MaxRetries is the max retry times
+	MaxRetries int `json:"maxRetries,omitempty" yaml:"maxRetries,omitempty"`
+}
+
+// WatcherTimer defines a condition to notice when it holds continuously for a duration,
+// e.g. a pod is pending for 10 minutes
+type WatcherTimer struct {
+	// Name is the name of the timer, e.g. "pending-too-long"
+	Name string `json:"name" yaml:"name"`
+
+	// Expression is a CEL expression evaluated on the object, e.g. object.status.phase == "Pending"
+	Expression string `json:"expression" yaml:"expression"`
+
+	// Duration is how long the condition should hold before noticing, e.g. 10m
+	Duration string `json:"duration" yaml:"duration"`
+}
+
+// WatcherEvents is config of events mode, filters are ignored when empty,
+// repeated occurrences aggregated in an Event by count or series are noticed once by default
+type WatcherEvents struct {
+	// Types are types of Events to notice, e.g. Warning
+	Types []string `json:"types,omitempty" yaml:"types,omitempty"`
+
+	// Reasons are reasons of Events to notice, e.g. BackOff, FailedScheduling
+	Reasons []string `json:"reasons,omitempty" yaml:"reasons,omitempty"`
+
+	// InvolvedObjectKinds are kinds of objects the Events are about, e.g. Pod
+	InvolvedObjectKinds []string `json:"involvedObjectKinds,omitempty" yaml:"involvedObjectKinds,omitempty"`
+
+	// InvolvedObjectNamespaces are namespaces of objects the Events are about
+	InvolvedObjectNamespaces []string `json:"involvedObjectNamespaces,omitempty" yaml:"involvedObjectNamespaces,omitempty"`
+
+	// MinCount is the number of occurrences before an Event is noticed, 1 by default
+	MinCount int64 `json:"minCount,omitempty" yaml:"minCount,omitempty"`
+
+	// NoticeRepeats determine whether to notice again when an Event occurs repeatedly
+	NoticeRepeats bool `json:"noticeRepeats,omitempty" yaml:"noticeRepeats,omitempty"`
+}
+
+// WatcherEnrich is config of enrichment, data collected is only attached to notifications,
+// container statuses and logs are only collected for Pods
+type WatcherEnrich struct {
+	// WarningEvents attaches recent Warning Events of the object
+	WarningEvents bool `json:"warningEvents,omitempty" yaml:"warningEvents,omitempty"`
+
+	// MaxWarningEvents is the max number of Warning Events attached, 5 by default
+	MaxWarningEvents int `json:"maxWarningEvents,omitempty" yaml:"maxWarningEvents,omitempty"`
+
+	// ContainerStatuses attaches container statuses, including last termination reasons and restart counts
+	ContainerStatuses bool `json:"containerStatuses,omitempty" yaml:"containerStatuses,omitempty"`
+
+	// Logs attaches tail of logs of crashed containers
+	Logs bool `json:"logs,omitempty" yaml:"logs,omitempty"`
+
+	// LogLines is the max number of lines of each log tail, 20 by default
+	LogLines int64 `json:"logLines,omitempty" yaml:"logLines,omitempty"`
+
+	// LogBytes is the max number of bytes of each log tail, 4096 by default
+	LogBytes int64 `json:"logBytes,omitempty" yaml:"logBytes,omitempty"`
+}
+
+// WatcherRetention is retention policy of events recorded, fields not set fall back to the global policy
+type WatcherRetention struct {
+	// MaxAge is the max age of events, e.g. 720h
+	MaxAge string `json:"maxAge,omitempty" yaml:"maxAge,omitempty"`
+
+	// MaxEvents is the max number of events of the watcher
+	MaxEvents int `json:"maxEvents,omitempty" yaml:"maxEvents,omitempty"`
+
+	// MaxVersions is the max number of events of an object
+	MaxVersions int `json:"maxVersions,omitempty" yaml:"maxVersions,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// WatcherList no client needed for list as it's been created in above
+type WatcherList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `son:"metadata,omitempty"`
+
+	Items []Watcher `json:"items"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterWatcher is a top-level type
+type ClusterWatcher struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec WatcherSpec `json:"spec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterWatcherList no client needed for list as it's been created in above
+type ClusterWatcherList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `son:"metadata,omitempty"`
+
+	Items []ClusterWatcher `json:"items"`
+}
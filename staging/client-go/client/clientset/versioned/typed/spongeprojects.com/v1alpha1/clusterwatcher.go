@@ -0,0 +1,168 @@
+/*
+Copyright SpongeProjects.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	"time"
+
+	v1alpha1 "github.com/spongeprojects/client-go/api/spongeprojects.com/v1alpha1"
+	scheme "github.com/spongeprojects/client-go/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// ClusterWatchersGetter has a method to return a ClusterWatcherInterface.
+// A group's client should implement this interface.
+type ClusterWatchersGetter interface {
+	ClusterWatchers() ClusterWatcherInterface
+}
+
+// ClusterWatcherInterface has methods to work with ClusterWatcher resources.
+type ClusterWatcherInterface interface {
+	Create(ctx context.Context, clusterWatcher *v1alpha1.ClusterWatcher, opts v1.CreateOptions) (*v1alpha1.ClusterWatcher, error)
+	Update(ctx context.Context, clusterWatcher *v1alpha1.ClusterWatcher, opts v1.UpdateOptions) (*v1alpha1.ClusterWatcher, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.ClusterWatcher, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.ClusterWatcherList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.ClusterWatcher, err error)
+	ClusterWatcherExpansion
+}
+
+// clusterWatchers implements ClusterWatcherInterface
+type clusterWatchers struct {
+	client rest.Interface
+}
+
+// newClusterWatchers returns a ClusterWatchers
+func newClusterWatchers(c *SpongeprojectsV1alpha1Client) *clusterWatchers {
+	return &clusterWatchers{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the clusterWatcher, and returns the corresponding clusterWatcher object, and an error if there is any.
+func (c *clusterWatchers) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.ClusterWatcher, err error) {
+	result = &v1alpha1.ClusterWatcher{}
+	err = c.client.Get().
+		Resource("clusterwatchers").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of ClusterWatchers that match those selectors.
+func (c *clusterWatchers) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.ClusterWatcherList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.ClusterWatcherList{}
+	err = c.client.Get().
+		Resource("clusterwatchers").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested clusterWatchers.
+func (c *clusterWatchers) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Resource("clusterwatchers").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a clusterWatcher and creates it.  Returns the server's representation of the clusterWatcher, and an error, if there is any.
+func (c *clusterWatchers) Create(ctx context.Context, clusterWatcher *v1alpha1.ClusterWatcher, opts v1.CreateOptions) (result *v1alpha1.ClusterWatcher, err error) {
+	result = &v1alpha1.ClusterWatcher{}
+	err = c.client.Post().
+		Resource("clusterwatchers").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(clusterWatcher).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a clusterWatcher and updates it. Returns the server's representation of the clusterWatcher, and an error, if there is any.
+func (c *clusterWatchers) Update(ctx context.Context, clusterWatcher *v1alpha1.ClusterWatcher, opts v1.UpdateOptions) (result *v1alpha1.ClusterWatcher, err error) {
+	result = &v1alpha1.ClusterWatcher{}
+	err = c.client.Put().
+		Resource("clusterwatchers").
+		Name(clusterWatcher.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(clusterWatcher).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the clusterWatcher and deletes it. Returns an error if one occurs.
+func (c *clusterWatchers) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Resource("clusterwatchers").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *clusterWatchers) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Resource("clusterwatchers").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched clusterWatcher.
+func (c *clusterWatchers) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.ClusterWatcher, err error) {
+	result = &v1alpha1.ClusterWatcher{}
+	err = c.client.Patch(pt).
+		Resource("clusterwatchers").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
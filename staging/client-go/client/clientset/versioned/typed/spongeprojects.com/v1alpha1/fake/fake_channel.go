@@ -0,0 +1,122 @@
+/*
+Copyright SpongeProjects.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1alpha1 "github.com/spongeprojects/client-go/api/spongeprojects.com/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeChannels implements ChannelInterface
+type FakeChannels struct {
+	Fake *FakeSpongeprojectsV1alpha1
+}
+
+var channelsResource = schema.GroupVersionResource{Group: "spongeprojects.com", Version: "v1alpha1", Resource: "channels"}
+
+var channelsKind = schema.GroupVersionKind{Group: "spongeprojects.com", Version: "v1alpha1", Kind: "Channel"}
+
+// Get takes name of the channel, and returns the corresponding channel object, and an error if there is any.
+func (c *FakeChannels) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.Channel, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(channelsResource, name), &v1alpha1.Channel{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.Channel), err
+}
+
+// List takes label and field selectors, and returns the list of Channels that match those selectors.
+func (c *FakeChannels) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.ChannelList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(channelsResource, channelsKind, opts), &v1alpha1.ChannelList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.ChannelList{ListMeta: obj.(*v1alpha1.ChannelList).ListMeta}
+	for _, item := range obj.(*v1alpha1.ChannelList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested channels.
+func (c *FakeChannels) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(channelsResource, opts))
+}
+
+// Create takes the representation of a channel and creates it.  Returns the server's representation of the channel, and an error, if there is any.
+func (c *FakeChannels) Create(ctx context.Context, channel *v1alpha1.Channel, opts v1.CreateOptions) (result *v1alpha1.Channel, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(channelsResource, channel), &v1alpha1.Channel{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.Channel), err
+}
+
+// Update takes the representation of a channel and updates it. Returns the server's representation of the channel, and an error, if there is any.
+func (c *FakeChannels) Update(ctx context.Context, channel *v1alpha1.Channel, opts v1.UpdateOptions) (result *v1alpha1.Channel, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(channelsResource, channel), &v1alpha1.Channel{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.Channel), err
+}
+
+// Delete takes name of the channel and deletes it. Returns an error if one occurs.
+func (c *FakeChannels) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteAction(channelsResource, name), &v1alpha1.Channel{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeChannels) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(channelsResource, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.ChannelList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched channel.
+func (c *FakeChannels) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.Channel, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(channelsResource, name, pt, data, subresources...), &v1alpha1.Channel{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.Channel), err
+}
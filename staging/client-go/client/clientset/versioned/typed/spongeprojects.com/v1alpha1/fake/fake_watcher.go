@@ -0,0 +1,130 @@
+/*
+Copyright SpongeProjects.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1alpha1 "github.com/spongeprojects/client-go/api/spongeprojects.com/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeWatchers implements WatcherInterface
+type FakeWatchers struct {
+	Fake *FakeSpongeprojectsV1alpha1
+	ns   string
+}
+
+var watchersResource = schema.GroupVersionResource{Group: "spongeprojects.com", Version: "v1alpha1", Resource: "watchers"}
+
+var watchersKind = schema.GroupVersionKind{Group: "spongeprojects.com", Version: "v1alpha1", Kind: "Watcher"}
+
+// Get takes name of the watcher, and returns the corresponding watcher object, and an error if there is any.
+func (c *FakeWatchers) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.Watcher, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(watchersResource, c.ns, name), &v1alpha1.Watcher{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.Watcher), err
+}
+
+// List takes label and field selectors, and returns the list of Watchers that match those selectors.
+func (c *FakeWatchers) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.WatcherList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(watchersResource, watchersKind, c.ns, opts), &v1alpha1.WatcherList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.WatcherList{ListMeta: obj.(*v1alpha1.WatcherList).ListMeta}
+	for _, item := range obj.(*v1alpha1.WatcherList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested watchers.
+func (c *FakeWatchers) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(watchersResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a watcher and creates it.  Returns the server's representation of the watcher, and an error, if there is any.
+func (c *FakeWatchers) Create(ctx context.Context, watcher *v1alpha1.Watcher, opts v1.CreateOptions) (result *v1alpha1.Watcher, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(watchersResource, c.ns, watcher), &v1alpha1.Watcher{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.Watcher), err
+}
+
+// Update takes the representation of a watcher and updates it. Returns the server's representation of the watcher, and an error, if there is any.
+func (c *FakeWatchers) Update(ctx context.Context, watcher *v1alpha1.Watcher, opts v1.UpdateOptions) (result *v1alpha1.Watcher, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(watchersResource, c.ns, watcher), &v1alpha1.Watcher{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.Watcher), err
+}
+
+// Delete takes name of the watcher and deletes it. Returns an error if one occurs.
+func (c *FakeWatchers) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(watchersResource, c.ns, name), &v1alpha1.Watcher{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeWatchers) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(watchersResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.WatcherList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched watcher.
+func (c *FakeWatchers) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.Watcher, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(watchersResource, c.ns, name, pt, data, subresources...), &v1alpha1.Watcher{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.Watcher), err
+}
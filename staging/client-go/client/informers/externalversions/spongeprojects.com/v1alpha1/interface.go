@@ -0,0 +1,59 @@
+/*
+Copyright SpongeProjects.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	internalinterfaces "github.com/spongeprojects/client-go/client/informers/externalversions/internalinterfaces"
+)
+
+// Interface provides access to all the informers in this group version.
+type Interface interface {
+	// Channels returns a ChannelInformer.
+	Channels() ChannelInformer
+	// ClusterWatchers returns a ClusterWatcherInformer.
+	ClusterWatchers() ClusterWatcherInformer
+	// Watchers returns a WatcherInformer.
+	Watchers() WatcherInformer
+}
+
+type version struct {
+	factory          internalinterfaces.SharedInformerFactory
+	namespace        string
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// New returns a new Interface.
+func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakListOptions internalinterfaces.TweakListOptionsFunc) Interface {
+	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
+}
+
+// Channels returns a ChannelInformer.
+func (v *version) Channels() ChannelInformer {
+	return &channelInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// ClusterWatchers returns a ClusterWatcherInformer.
+func (v *version) ClusterWatchers() ClusterWatcherInformer {
+	return &clusterWatcherInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// Watchers returns a WatcherInformer.
+func (v *version) Watchers() WatcherInformer {
+	return &watcherInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
@@ -0,0 +1,10 @@
+module github.com/spongeprojects/client-go
+
+go 1.16
+
+require (
+	github.com/spongeprojects/magicconch v0.0.6
+	k8s.io/apimachinery v0.21.1
+	k8s.io/client-go v0.21.1
+	k8s.io/klog/v2 v2.8.0
+)